@@ -3,9 +3,13 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -21,11 +25,23 @@ type NoNoodleWorkflowClient struct {
 	fiberApp             *fiber.App
 	clientHealthCheckUrl string
 	clientBaseUrl        string
+
+	// subscriptions holds what the core issued for each subscribed process
+	// task, keyed like the task handlers
+	subscriptionsMu sync.RWMutex
+	subscriptions   map[string]subscription
+}
+
+type subscription struct {
+	sessionKey    string
+	signingSecret string
 }
 
 type NoodleJobClient struct {
 	CompleteTask func(workflowID string, task string) error
 	FailedTask   func(workflowID string, task string) error
+	// HeartbeatTask extends the lease of a job that takes longer than its task lease
+	HeartbeatTask func(workflowID string, task string) error
 }
 
 type NoNoodleClientInterface interface {
@@ -33,9 +49,11 @@ type NoNoodleClientInterface interface {
 	CompleteTask(workflowID string, task string) error
 	CreateWorkflow(processID string) (string, error)
 	FailedTask(workflowID string, task string) error
+	HeartbeatTask(workflowID string, task string) error
 	AddNoNoodleWorkflowHandler(fiberApp *fiber.App)
 	RegisterTask(processID string, task string, handler func(noodleJobClient NoodleJobClient, job Job) error)
 	Run() error
+	Unsubscribe() error
 }
 
 func NewNoNoodleWorkflowClient(hosturl string, httpClient *http.Client, clientHealthCheckUrl string, clientBaseUrl string) NoNoodleClientInterface {
@@ -48,6 +66,7 @@ func NewNoNoodleWorkflowClient(hosturl string, httpClient *http.Client, clientHe
 		},
 		clientHealthCheckUrl: clientHealthCheckUrl,
 		clientBaseUrl:        clientBaseUrl,
+		subscriptions:        make(map[string]subscription),
 	}
 }
 
@@ -55,8 +74,9 @@ func (nn *NoNoodleWorkflowClient) RegisterTask(processID string, task string, ha
 
 	nn.ProcessRegistry.listTaskRegistry[processID+"_"+task] = func(job Job) error {
 		return handler(NoodleJobClient{
-			CompleteTask: nn.CompleteTask,
-			FailedTask:   nn.FailedTask,
+			CompleteTask:  nn.CompleteTask,
+			FailedTask:    nn.FailedTask,
+			HeartbeatTask: nn.HeartbeatTask,
 		}, job)
 	}
 }
@@ -99,10 +119,17 @@ func (nn *NoNoodleWorkflowClient) reSubscribeAllTasks() {
 
 		callbackUrl := fmt.Sprintf("%s/no_noodle_workflow_client/subscribe", nn.clientBaseUrl)
 
-		_, err := nn.subscribeTask(processID, task, nn.clientHealthCheckUrl, callbackUrl)
+		// A subscription left over from a previous run of the worker is taken
+		// over with a new secret
+		sessionKey, signingSecret, err := nn.subscribeTask(processID, task, nn.clientHealthCheckUrl, callbackUrl)
 		if err != nil {
 			fmt.Printf("Error re-subscribing to task: %s of process: %s, error: %v\n", task, processID, err)
+			continue
 		}
+
+		nn.subscriptionsMu.Lock()
+		nn.subscriptions[key] = subscription{sessionKey: sessionKey, signingSecret: signingSecret}
+		nn.subscriptionsMu.Unlock()
 	}
 }
 
@@ -134,6 +161,20 @@ func (nn *NoNoodleWorkflowClient) Run() error {
 			})
 		}
 
+		// Only the core holding the secret of this subscription can deliver jobs
+		nn.subscriptionsMu.RLock()
+		signingSecret := nn.subscriptions[jsonPayloads.ProcessID+"_"+jsonPayloads.TaskID].signingSecret
+		nn.subscriptionsMu.RUnlock()
+
+		err := verifyDelivery(signingSecret, c.Get(timestampHeader), c.Get(signatureHeader), c.Body(), time.Now())
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"status":  "error",
+				"error":   "Invalid delivery signature",
+				"details": err.Error(),
+			})
+		}
+
 		go nn.taskHandler(jsonPayloads)
 
 		return c.JSON(fiber.Map{
@@ -264,7 +305,88 @@ func (nn *NoNoodleWorkflowClient) FailedTask(workflowID string, task string) err
 	return nil
 }
 
-func (nn *NoNoodleWorkflowClient) subscribeTask(processID string, task string, healthCheckURL string, callbackURL string) (string, error) {
+// HeartbeatTask tells the core the job of task is still being worked on, so
+// it is not delivered again when the task lease runs out.
+func (nn *NoNoodleWorkflowClient) HeartbeatTask(workflowID string, task string) error {
+
+	url := nn.hosturl + "/heartbeat_task"
+
+	payload := map[string]string{
+		"workflow_id": workflowID,
+		"task":        task,
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonPayload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	res, err := nn.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to heartbeat task, status code: %d, response: %s", res.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Unsubscribe ends every subscription of the client, call it before the worker
+// shuts down so the core stops delivering jobs to it.
+func (nn *NoNoodleWorkflowClient) Unsubscribe() error {
+
+	nn.subscriptionsMu.Lock()
+	subscriptions := nn.subscriptions
+	nn.subscriptions = make(map[string]subscription)
+	nn.subscriptionsMu.Unlock()
+
+	var errs []error
+	for key, subscription := range subscriptions {
+		if err := nn.unsubscribeTask(subscription.sessionKey); err != nil {
+			errs = append(errs, fmt.Errorf("failed to unsubscribe %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (nn *NoNoodleWorkflowClient) unsubscribeTask(sessionKey string) error {
+
+	req, err := http.NewRequest("DELETE", nn.hosturl+"/subscriptions/"+url.PathEscape(sessionKey), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := nn.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Already gone, e.g. evicted by failed health checks
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to unsubscribe session %s, status code: %d", sessionKey, resp.StatusCode)
+	}
+	return nil
+}
+
+// subscribeTask returns the session key of the subscription and the secret
+// its deliveries are signed with.
+func (nn *NoNoodleWorkflowClient) subscribeTask(processID string, task string, healthCheckURL string, callbackURL string) (string, string, error) {
 
 	type SubscribeRequest struct {
 		ProcessID      string `json:"process_id"`
@@ -284,36 +406,37 @@ func (nn *NoNoodleWorkflowClient) subscribeTask(processID string, task string, h
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	req, err := http.NewRequest("POST", nn.hosturl+"/subscribe", bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	req.Header.Add("Content-Type", "application/json")
 	resp, err := nn.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to subscribe process %s task %s, status code: %d", processID, task, resp.StatusCode)
+		return "", "", fmt.Errorf("failed to subscribe process %s task %s, status code: %d", processID, task, resp.StatusCode)
 	}
 
 	type SubscribeResponse struct {
-		SessionKey string `json:"connection_key"`
+		SessionKey    string `json:"connection_key"`
+		SigningSecret string `json:"signing_secret"`
 	}
 
 	var subscribeResp SubscribeResponse
 	err = json.NewDecoder(resp.Body).Decode(&subscribeResp)
 
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return subscribeResp.SessionKey, nil
+	return subscribeResp.SessionKey, subscribeResp.SigningSecret, nil
 }
@@ -3,9 +3,10 @@ package client
 import "time"
 
 type ProcessConfig struct {
-	ProcessID     string              `json:"process_id"`
-	MapStageTask  map[string][]string `json:"map_stage_task"`
-	MapStageReady map[string][]string `json:"map_stage_ready"`
+	ProcessID           string              `json:"process_id"`
+	MapStageTask        map[string][]string `json:"map_stage_task"`
+	MapStageReady       map[string][]string `json:"map_stage_ready"`
+	MapTaskLeaseSeconds map[string]int      `json:"map_task_lease_seconds,omitempty"`
 }
 
 type TaskStatusData struct {
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers the core signs every job delivery with, see verifyDelivery.
+const (
+	timestampHeader = "X-No-Noodle-Timestamp"
+	signatureHeader = "X-No-Noodle-Signature"
+)
+
+// deliveryMaxAge is how far the timestamp of a delivery may be off from now
+// before it is rejected as a replay.
+const deliveryMaxAge = 5 * time.Minute
+
+var (
+	errUnknownSubscription = errors.New("no signing secret for the subscription")
+	errMissingSignature    = errors.New("delivery is not signed")
+	errStaleDelivery       = errors.New("delivery timestamp is too old or in the future")
+	errInvalidSignature    = errors.New("delivery signature does not match")
+)
+
+// verifyDelivery checks the body was signed by the core with the secret of
+// the subscription it was delivered for, within deliveryMaxAge of now. The
+// signature is "v1=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+func verifyDelivery(signingSecret string, timestamp string, signature string, body []byte, now time.Time) error {
+
+	if signingSecret == "" {
+		return errUnknownSubscription
+	}
+	if timestamp == "" || signature == "" {
+		return errMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errMissingSignature
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > deliveryMaxAge || age < -deliveryMaxAge {
+		return errStaleDelivery
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	expected := "v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errInvalidSignature
+	}
+	return nil
+}
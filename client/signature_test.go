@@ -0,0 +1,152 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// signedDelivery is a job delivery as the core signed it, read through the
+// headers the client looks at.
+type signedDelivery struct {
+	signingSecret string
+	timestamp     string
+	signature     string
+	body          []byte
+}
+
+// deliverFromCore subscribes a worker to a core and returns the first job the
+// core delivers to it.
+func deliverFromCore(t *testing.T) signedDelivery {
+	t.Helper()
+
+	db, err := util.NewSQLite(filepath.Join(t.TempDir(), "no_noodle.db"), 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repository.NewSQLiteNoNoodleWorkflow(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker := msgbroker.NewMemoryMessageBroker()
+	core := api.NewNoNoodleWorkflowCorePostgresql(repo, api.NewMessageService(broker, 3, 20*time.Second, api.DeliveryPolicy{}), api.ClusterPolicy{})
+	t.Cleanup(func() {
+		broker.Close()
+		db.Close()
+	})
+
+	deliveries := make(chan signedDelivery, 10)
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- signedDelivery{
+			timestamp: r.Header.Get(timestampHeader),
+			signature: r.Header.Get(signatureHeader),
+			body:      body,
+		}
+	}))
+	t.Cleanup(worker.Close)
+
+	err = core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "sp",
+		MapStageTask:  map[string][]string{"start": {"a"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionKey, signingSecret, err := core.SubscribeTask("sp", "a", worker.URL+"/health", worker.URL+"/callback", entitites.HealthCheckConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { core.UnsubscribeTask(sessionKey) })
+	if _, err := core.CreateWorkflow("sp", "bk"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case delivery := <-deliveries:
+		delivery.signingSecret = signingSecret
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job was never delivered")
+	}
+	return signedDelivery{}
+}
+
+func TestVerifyDelivery(t *testing.T) {
+	delivery := deliverFromCore(t)
+	if delivery.timestamp == "" || delivery.signature == "" {
+		t.Fatalf("the core delivered without the signature headers: %+v", delivery)
+	}
+	seconds, err := strconv.ParseInt(delivery.timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("the core signed with timestamp %q: %v", delivery.timestamp, err)
+	}
+	signedAt := time.Unix(seconds, 0)
+
+	tests := []struct {
+		name          string
+		signingSecret string
+		timestamp     string
+		signature     string
+		body          []byte
+		now           time.Time
+		want          error
+	}{
+		{name: "valid", now: signedAt},
+		{name: "valid within max age", now: signedAt.Add(deliveryMaxAge)},
+		{name: "forged body", body: []byte(`{"process_id":"sp","task_id":"a","workflow_id":"forged"}`), now: signedAt, want: errInvalidSignature},
+		{name: "wrong secret", signingSecret: "not-the-secret", now: signedAt, want: errInvalidSignature},
+		{name: "unknown subscription", signingSecret: "-", now: signedAt, want: errUnknownSubscription},
+		{name: "stale timestamp", now: signedAt.Add(deliveryMaxAge + time.Second), want: errStaleDelivery},
+		{name: "future timestamp", now: signedAt.Add(-deliveryMaxAge - time.Second), want: errStaleDelivery},
+		{name: "missing timestamp", timestamp: "-", now: signedAt, want: errMissingSignature},
+		{name: "missing signature", signature: "-", now: signedAt, want: errMissingSignature},
+		{name: "non-numeric timestamp", timestamp: "yesterday", now: signedAt, want: errMissingSignature},
+	}
+
+	// Fields left empty take the delivery of the core, "-" clears them
+	pick := func(value string, delivered string) string {
+		switch value {
+		case "":
+			return delivered
+		case "-":
+			return ""
+		}
+		return value
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == nil {
+				body = delivery.body
+			}
+
+			err := verifyDelivery(
+				pick(tt.signingSecret, delivery.signingSecret),
+				pick(tt.timestamp, delivery.timestamp),
+				pick(tt.signature, delivery.signature),
+				body,
+				tt.now,
+			)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
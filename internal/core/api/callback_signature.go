@@ -0,0 +1,47 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of a signed callback delivery. The signature is
+// "v1=" + hex(HMAC-SHA256(secret, timestamp + "." + body)), so a worker can
+// reject forged payloads and, by the timestamp, replays of old ones.
+const (
+	CallbackTimestampHeader = "X-No-Noodle-Timestamp"
+	CallbackSignatureHeader = "X-No-Noodle-Signature"
+)
+
+func generateSigningSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func callbackSignature(signingSecret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signCallback sets the signature headers of a delivery. Subscriptions made
+// before secrets were issued have none and are delivered unsigned.
+func signCallback(req *http.Request, signingSecret string, body []byte) {
+	if signingSecret == "" {
+		return
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(CallbackTimestampHeader, timestamp)
+	req.Header.Set(CallbackSignatureHeader, callbackSignature(signingSecret, timestamp, body))
+}
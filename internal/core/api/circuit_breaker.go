@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// DeliveryPolicy bounds and paces the deliveries to a subscriber, see
+// config.DeliveryConfig. The zero value gives up on a delivery after
+// defaultDeliveryTimeout, retries right away, honors a Retry-After up to
+// defaultRetryAfterMax and never opens a circuit.
+type DeliveryPolicy struct {
+	Timeout          time.Duration
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	RetryAfterMax    time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// defaultDeliveryTimeout bounds a delivery when the policy does not, a worker
+// that never answers must not hold up its subscription.
+const defaultDeliveryTimeout = 30 * time.Second
+
+// deliveryTimeout returns how long a delivery may wait for the subscriber.
+func (p DeliveryPolicy) deliveryTimeout() time.Duration {
+	if p.Timeout <= 0 {
+		return defaultDeliveryTimeout
+	}
+	return p.Timeout
+}
+
+// defaultRetryAfterMax bounds a Retry-After when the policy does not, a
+// subscriber must not pause its deliveries for as long as it likes.
+const defaultRetryAfterMax = 5 * time.Minute
+
+// retryAfter returns how long a subscriber that asked for retryAfter is left
+// alone.
+func (p DeliveryPolicy) retryAfter(retryAfter time.Duration) time.Duration {
+	if p.RetryAfterMax <= 0 {
+		return min(retryAfter, defaultRetryAfterMax)
+	}
+	return min(retryAfter, p.RetryAfterMax)
+}
+
+// callbackError is a delivery the subscriber answered with an error status,
+// RetryAfter is set when a 429 or 503 asked to come back later.
+type callbackError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *callbackError) Error() string {
+	return fmt.Sprintf("failed to notify subscriber, status code: %d", e.StatusCode)
+}
+
+func newCallbackError(resp *http.Response) *callbackError {
+	callbackErr := &callbackError{StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		callbackErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return callbackErr
+}
+
+// parseRetryAfter reads Retry-After as either delay seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// CircuitBreaker tracks the deliveries to one subscriber. Its consumer waits
+// on it before every delivery and reports the outcome, the breaker is safe to
+// read from other goroutines for status reporting.
+type CircuitBreaker struct {
+	policy DeliveryPolicy
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	lastError           string
+	openedDate          time.Time
+	// resumeDate is when the next delivery may go out, after a backoff or
+	// the cooldown of an open circuit
+	resumeDate time.Time
+}
+
+func newCircuitBreaker(policy DeliveryPolicy) *CircuitBreaker {
+	return &CircuitBreaker{
+		policy: policy,
+		state:  entitites.CIRCUIT_STATE_CLOSED,
+	}
+}
+
+// Wait blocks until the next delivery may go out, an open circuit turns half
+// open once its cooldown is over. It returns false when ctx is done first.
+func (b *CircuitBreaker) Wait(ctx context.Context) bool {
+
+	for {
+		b.mu.Lock()
+		wait := time.Until(b.resumeDate)
+		if wait <= 0 {
+			if b.state == entitites.CIRCUIT_STATE_OPEN {
+				b.state = entitites.CIRCUIT_STATE_HALF_OPEN
+			}
+			b.mu.Unlock()
+			return ctx.Err() == nil
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Success closes the circuit and resets the backoff.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = entitites.CIRCUIT_STATE_CLOSED
+	b.consecutiveFailures = 0
+	b.lastError = ""
+	b.openedDate = time.Time{}
+	b.resumeDate = time.Time{}
+}
+
+// Failure records a failed delivery and returns how long the subscriber is
+// left alone: the backoff, at least what a Retry-After asked for up to
+// RetryAfterMax, or the cooldown when this failure opens the circuit.
+func (b *CircuitBreaker) Failure(err error) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures++
+	b.lastError = err.Error()
+
+	delay := backoff(b.policy.BackoffBase, b.policy.BackoffMax, b.consecutiveFailures)
+	var callbackErr *callbackError
+	if errors.As(err, &callbackErr) {
+		delay = max(delay, b.policy.retryAfter(callbackErr.RetryAfter))
+	}
+
+	// A failed trial delivery opens the circuit again right away
+	if b.state == entitites.CIRCUIT_STATE_HALF_OPEN ||
+		(b.policy.BreakerThreshold > 0 && b.consecutiveFailures >= b.policy.BreakerThreshold) {
+		if b.state != entitites.CIRCUIT_STATE_OPEN {
+			b.openedDate = time.Now()
+		}
+		b.state = entitites.CIRCUIT_STATE_OPEN
+		delay = max(delay, b.policy.BreakerCooldown)
+	}
+
+	b.resumeDate = time.Now().Add(delay)
+	return delay
+}
+
+func (b *CircuitBreaker) status() entitites.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := entitites.CircuitBreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		LastError:           b.lastError,
+	}
+	if !b.openedDate.IsZero() {
+		openedDate := b.openedDate
+		status.OpenedDate = &openedDate
+	}
+	if time.Now().Before(b.resumeDate) {
+		retryDate := b.resumeDate
+		status.RetryDate = &retryDate
+	}
+	return status
+}
+
+// backoff doubles base with every failure after the first up to maxDelay, 0
+// leaves it uncapped, with up to half of it randomized so failing subscribers
+// do not retry in lockstep.
+func backoff(base time.Duration, maxDelay time.Duration, failures int) time.Duration {
+	if base <= 0 || failures <= 0 {
+		return 0
+	}
+
+	delay := base
+	for i := 1; i < failures; i++ {
+		if (maxDelay > 0 && delay >= maxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if maxDelay > 0 {
+		delay = min(delay, maxDelay)
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
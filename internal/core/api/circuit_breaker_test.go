@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		maxDelay time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", base: 100 * time.Millisecond, maxDelay: time.Second, failures: 0, want: 0},
+		{name: "no base", base: 0, maxDelay: time.Second, failures: 3, want: 0},
+		{name: "first failure", base: 100 * time.Millisecond, maxDelay: time.Second, failures: 1, want: 100 * time.Millisecond},
+		{name: "doubles", base: 100 * time.Millisecond, maxDelay: time.Second, failures: 2, want: 200 * time.Millisecond},
+		{name: "doubles again", base: 100 * time.Millisecond, maxDelay: time.Second, failures: 4, want: 800 * time.Millisecond},
+		{name: "capped", base: 100 * time.Millisecond, maxDelay: time.Second, failures: 5, want: time.Second},
+		{name: "stays capped", base: 100 * time.Millisecond, maxDelay: time.Second, failures: 1000, want: time.Second},
+		{name: "cap below base", base: time.Second, maxDelay: 100 * time.Millisecond, failures: 1, want: 100 * time.Millisecond},
+		{name: "no cap", base: 100 * time.Millisecond, maxDelay: 0, failures: 4, want: 800 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Up to half of the delay is randomized
+			for i := 0; i < 100; i++ {
+				got := backoff(tt.base, tt.maxDelay, tt.failures)
+				if got < tt.want/2 || got > tt.want {
+					t.Fatalf("got %v, want between %v and %v", got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+
+	if got := backoff(time.Second, 0, 1000); got <= 0 {
+		t.Fatalf("got %v after many uncapped failures, want a positive delay", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "zero seconds", value: "0", want: 0},
+		{name: "negative seconds", value: "-5", want: 0},
+		{name: "http date", value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{name: "http date in the past", value: now.Add(-time.Hour).Format(http.TimeFormat), want: 0},
+		{name: "fractional seconds", value: "1.5", want: 0},
+		{name: "garbage", value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCapsRetryAfter(t *testing.T) {
+	dayLong := &callbackError{StatusCode: http.StatusServiceUnavailable, RetryAfter: 24 * time.Hour}
+
+	tests := []struct {
+		name   string
+		policy DeliveryPolicy
+		err    error
+		want   time.Duration
+	}{
+		{name: "within the cap", policy: DeliveryPolicy{RetryAfterMax: time.Hour}, err: &callbackError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}, want: time.Minute},
+		{name: "capped by the policy", policy: DeliveryPolicy{RetryAfterMax: time.Hour}, err: dayLong, want: time.Hour},
+		{name: "capped by default", policy: DeliveryPolicy{}, err: dayLong, want: defaultRetryAfterMax},
+		{name: "cooldown beyond the cap", policy: DeliveryPolicy{RetryAfterMax: time.Minute, BreakerThreshold: 1, BreakerCooldown: time.Hour}, err: dayLong, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newCircuitBreaker(tt.policy).Failure(tt.err); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	cooldown := 20 * time.Millisecond
+	breaker := newCircuitBreaker(DeliveryPolicy{BreakerThreshold: 2, BreakerCooldown: cooldown})
+	ctx := context.Background()
+	failed := errors.New("connection refused")
+
+	wantState := func(state string, failures int) {
+		t.Helper()
+		status := breaker.status()
+		if status.State != state || status.ConsecutiveFailures != failures {
+			t.Fatalf("got %s after %d failures, want %s after %d", status.State, status.ConsecutiveFailures, state, failures)
+		}
+	}
+
+	wantState(entitites.CIRCUIT_STATE_CLOSED, 0)
+
+	if delay := breaker.Failure(failed); delay != 0 {
+		t.Fatalf("got delay %v below the threshold, want none", delay)
+	}
+	wantState(entitites.CIRCUIT_STATE_CLOSED, 1)
+
+	if delay := breaker.Failure(failed); delay != cooldown {
+		t.Fatalf("got delay %v when the circuit opens, want the cooldown %v", delay, cooldown)
+	}
+	wantState(entitites.CIRCUIT_STATE_OPEN, 2)
+	if breaker.status().OpenedDate == nil {
+		t.Fatal("open circuit has no opened date")
+	}
+
+	// The cooldown is over, one trial delivery goes out
+	if !breaker.Wait(ctx) {
+		t.Fatal("wait gave up")
+	}
+	wantState(entitites.CIRCUIT_STATE_HALF_OPEN, 2)
+
+	// A failed trial opens the circuit again
+	if delay := breaker.Failure(failed); delay != cooldown {
+		t.Fatalf("got delay %v after a failed trial, want the cooldown %v", delay, cooldown)
+	}
+	wantState(entitites.CIRCUIT_STATE_OPEN, 3)
+
+	if !breaker.Wait(ctx) {
+		t.Fatal("wait gave up")
+	}
+	wantState(entitites.CIRCUIT_STATE_HALF_OPEN, 3)
+
+	// A successful trial closes it
+	breaker.Success()
+	wantState(entitites.CIRCUIT_STATE_CLOSED, 0)
+	if status := breaker.status(); status.OpenedDate != nil || status.RetryDate != nil {
+		t.Fatalf("got %+v, want a closed circuit to keep no dates", status)
+	}
+}
+
+func TestCircuitBreakerWaitStopsWithContext(t *testing.T) {
+	breaker := newCircuitBreaker(DeliveryPolicy{BreakerThreshold: 1, BreakerCooldown: time.Hour})
+	breaker.Failure(errors.New("connection refused"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if breaker.Wait(ctx) {
+		t.Fatal("wait went through an open circuit before its cooldown")
+	}
+	if state := breaker.status().State; state != entitites.CIRCUIT_STATE_OPEN {
+		t.Fatalf("got %s, want the circuit to stay open", state)
+	}
+}
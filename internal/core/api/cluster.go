@@ -0,0 +1,406 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+const (
+	defaultClusterLease     = 15 * time.Second
+	defaultClusterHeartbeat = 5 * time.Second
+
+	// requeueLease names the duty of requeueing expired jobs of every queue
+	requeueLease = "requeue_expired"
+	// retentionLease names the duty of sweeping expired workflows
+	retentionLease = "retention"
+)
+
+// ClusterPolicy configures how core instances sharing one database split the
+// subscriptions between them. Every instance renews a lease on itself and on
+// the subscriptions it consumes each HeartbeatInterval, an instance or
+// subscription whose lease ran out for LeaseDuration is taken over by the
+// others. Leases are measured with the clock of each instance, so their skew
+// has to stay well below LeaseDuration. An empty InstanceID is generated.
+type ClusterPolicy struct {
+	InstanceID        string
+	LeaseDuration     time.Duration
+	HeartbeatInterval time.Duration
+}
+
+// clusterMember keeps this instance a live member of the cluster. Each
+// subscription belongs to the live instance ranking highest for it by
+// rendezvous hashing, so a joining or leaving instance only moves its own
+// share. The previous owner stops and releases a subscription before the new
+// one claims it, which keeps every subscription on exactly one instance.
+type clusterMember struct {
+	core              *NoNoodleWorkflowCorePostgresql
+	instanceID        string
+	leaseDuration     time.Duration
+	heartbeatInterval time.Duration
+	startDate         time.Time
+
+	// mu serializes rebalancing with subscriptions adopted on subscribe
+	mu        sync.Mutex
+	instances []string
+	// renewDate is when the leases of the running subscriptions were last
+	// renewed together
+	renewDate time.Time
+}
+
+// generateInstanceID names an instance started without an instance ID
+func generateInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "core"
+	}
+	return hostname + "-" + uuid.New().String()[:8]
+}
+
+func newClusterMember(core *NoNoodleWorkflowCorePostgresql, policy ClusterPolicy) *clusterMember {
+
+	member := &clusterMember{
+		core:              core,
+		instanceID:        policy.InstanceID,
+		leaseDuration:     policy.LeaseDuration,
+		heartbeatInterval: policy.HeartbeatInterval,
+		startDate:         util.GetCurrentTime(),
+	}
+
+	if member.instanceID == "" {
+		member.instanceID = generateInstanceID()
+	}
+	if member.leaseDuration <= 0 {
+		member.leaseDuration = defaultClusterLease
+	}
+	if member.heartbeatInterval <= 0 {
+		member.heartbeatInterval = defaultClusterHeartbeat
+	}
+	if member.heartbeatInterval >= member.leaseDuration {
+		fmt.Printf("Cluster heartbeat interval %v does not renew a lease of %v in time, using %v\n", member.heartbeatInterval, member.leaseDuration, member.leaseDuration/3)
+		member.heartbeatInterval = member.leaseDuration / 3
+	}
+
+	// Until the first heartbeat the instance only knows about itself
+	member.instances = []string{member.instanceID}
+
+	return member
+}
+
+// heartbeat renews the lease of this instance and refreshes the live members.
+func (m *clusterMember) heartbeat() error {
+
+	now := util.GetCurrentTime()
+	err := m.core.repo.RenewInstance(entitites.CoreInstance{
+		InstanceID:      m.instanceID,
+		StartDate:       m.startDate,
+		LeaseExpireDate: now.Add(m.leaseDuration),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to renew instance lease: %w", err)
+	}
+
+	instances, err := m.core.repo.ListInstances(now)
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	live := []string{m.instanceID}
+	for _, instance := range instances {
+		if instance.InstanceID != m.instanceID {
+			live = append(live, instance.InstanceID)
+		}
+	}
+
+	m.mu.Lock()
+	m.instances = live
+	m.mu.Unlock()
+
+	return nil
+}
+
+// ownsLocked tells whether this instance ranks highest for sessionKey among
+// the live members.
+func (m *clusterMember) ownsLocked(sessionKey string) bool {
+
+	var owner string
+	var best uint64
+	for _, instanceID := range m.instances {
+		hash := fnv.New64a()
+		hash.Write([]byte(instanceID))
+		hash.Write([]byte{0})
+		hash.Write([]byte(sessionKey))
+		score := hash.Sum64()
+		if owner == "" || score > best || (score == best && instanceID < owner) {
+			owner, best = instanceID, score
+		}
+	}
+	return owner == m.instanceID
+}
+
+// rebalance starts the stored subscriptions this instance owns, renewing
+// their leases, and stops the ones it no longer owns. It returns the stored
+// subscriptions.
+func (m *clusterMember) rebalance() ([]entitites.SubscriberRegistry, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subscribers, err := m.core.repo.GetAllSubscribers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscribers: %w", err)
+	}
+
+	now := util.GetCurrentTime()
+	stored := map[string]bool{}
+	var release []string
+	var errs []error
+	for _, subscriber := range *subscribers {
+		stored[subscriber.SessionKey] = true
+		subscription, running := m.core.subscribers.get(subscriber.SessionKey)
+
+		if !m.ownsLocked(subscriber.SessionKey) {
+			// Handed over once stopped, the new owner claims it on its next heartbeat
+			if running {
+				m.stop(subscriber.SessionKey)
+			}
+			if running || subscriber.OwnerInstanceID == m.instanceID {
+				release = append(release, subscriber.SessionKey)
+			}
+			continue
+		}
+
+		claimed, err := m.core.repo.ClaimSubscriber(subscriber.SessionKey, m.instanceID, now, now.Add(m.leaseDuration))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to claim subscriber %s: %w", subscriber.SessionKey, err))
+		}
+		if !claimed {
+			// Still held by its previous owner, taken while our lease ran out,
+			// or not renewed, in which case the next heartbeat claims it again
+			if running {
+				m.stop(subscriber.SessionKey)
+			}
+			continue
+		}
+
+		switch {
+		case !running:
+			err = m.core.subscribers.start(subscriber)
+		case subscription.subscriber.Revision != subscriber.Revision:
+			// Taken over by a re-subscribe handled by another instance
+			err = m.core.subscribers.restart(subscriber)
+		default:
+			if subscriber.RenewDate != nil {
+				subscription.recordRenewal(*subscriber.RenewDate)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to start subscriber %s: %w", subscriber.SessionKey, err))
+		}
+	}
+
+	// Unsubscribed or evicted through another instance
+	for _, subscription := range m.core.subscribers.running() {
+		if !stored[subscription.subscriber.SessionKey] {
+			m.stop(subscription.subscriber.SessionKey)
+		}
+	}
+
+	m.renewDate = now
+
+	if err := m.core.repo.ReleaseSubscribers(m.instanceID, release); err != nil {
+		errs = append(errs, fmt.Errorf("failed to release subscribers: %w", err))
+	}
+
+	return *subscribers, errors.Join(errs...)
+}
+
+// fence stops every subscription of this instance once their leases could
+// run out before the next heartbeat, as another instance may claim them then.
+func (m *clusterMember) fence() {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if time.Since(m.renewDate)+m.heartbeatInterval < m.leaseDuration {
+		return
+	}
+
+	for _, subscription := range m.core.subscribers.running() {
+		fmt.Printf("Stopping subscriber with session key %s, its lease could not be renewed\n", subscription.subscriber.SessionKey)
+		m.stop(subscription.subscriber.SessionKey)
+	}
+}
+
+func (m *clusterMember) stop(sessionKey string) {
+	if err := m.core.subscribers.stop(sessionKey); err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+		fmt.Printf("Failed to stop subscriber with session key %s: %v\n", sessionKey, err)
+	}
+}
+
+// adopt starts a subscription saved by SubscribeTask right away when this
+// instance owns it, otherwise its owner picks it up on its next heartbeat.
+func (m *clusterMember) adopt(subscriber entitites.SubscriberRegistry) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A re-subscribe took over a subscription this instance consumes
+	if _, running := m.core.subscribers.get(subscriber.SessionKey); running {
+		return m.core.subscribers.restart(subscriber)
+	}
+
+	if !m.ownsLocked(subscriber.SessionKey) {
+		return nil
+	}
+
+	now := util.GetCurrentTime()
+	claimed, err := m.core.repo.ClaimSubscriber(subscriber.SessionKey, m.instanceID, now, now.Add(m.leaseDuration))
+	if err != nil || !claimed {
+		return err
+	}
+	return m.core.subscribers.start(subscriber)
+}
+
+// requeueExpired requeues the expired jobs of every queue when this instance
+// holds the requeue lease. subscribers adds the queues of tasks no longer in
+// the latest version of their process.
+func (m *clusterMember) requeueExpired(ctx context.Context, subscribers []entitites.SubscriberRegistry) error {
+
+	now := util.GetCurrentTime()
+	leader, err := m.core.repo.ClaimClusterLease(requeueLease, m.instanceID, now, now.Add(m.leaseDuration))
+	if err != nil {
+		return fmt.Errorf("failed to claim requeue lease: %w", err)
+	}
+	if !leader {
+		return nil
+	}
+
+	// Instances gone for a whole lease are not coming back under the same ID
+	if _, err := m.core.repo.RemoveExpiredInstances(now.Add(-m.leaseDuration)); err != nil {
+		fmt.Println("Failed to remove expired instances:", err)
+	}
+
+	processConfigs, err := m.core.ListProcessConfigs()
+	if err != nil {
+		return err
+	}
+
+	channals := []string{}
+	for _, processConfig := range processConfigs {
+		for _, task := range processTasks(processConfig) {
+			channals = append(channals, taskChannal(processConfig.ProcessID, task))
+		}
+	}
+	for _, subscriber := range subscribers {
+		channal := taskChannal(subscriber.ProcessID, subscriber.Task)
+		if !slices.Contains(channals, channal) {
+			channals = append(channals, channal)
+		}
+	}
+
+	for _, channal := range channals {
+		if err := m.core.pubsub.RequeueExpired(ctx, channal); err != nil && ctx.Err() == nil {
+			fmt.Printf("Failed to requeue expired jobs of %s: %v\n", channal, err)
+		}
+	}
+	return nil
+}
+
+// leave stops every subscription of this instance and hands its leases to the
+// other instances.
+func (m *clusterMember) leave() {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sessionKeys []string
+	for _, subscription := range m.core.subscribers.running() {
+		m.stop(subscription.subscriber.SessionKey)
+		sessionKeys = append(sessionKeys, subscription.subscriber.SessionKey)
+	}
+
+	if err := m.core.repo.ReleaseSubscribers(m.instanceID, sessionKeys); err != nil {
+		fmt.Println("Failed to release subscribers:", err)
+	}
+	if err := m.core.repo.ReleaseClusterLeases(m.instanceID); err != nil {
+		fmt.Println("Failed to release cluster leases:", err)
+	}
+	if err := m.core.repo.RemoveInstance(m.instanceID); err != nil {
+		fmt.Println("Failed to remove instance:", err)
+	}
+}
+
+// RunCluster keeps this instance a live member of the cluster until ctx is
+// done: it renews its leases, rebalances the subscriptions when instances
+// join or leave and, when elected, requeues expired jobs. Its subscriptions
+// are handed to the other instances on return.
+func (c *NoNoodleWorkflowCorePostgresql) RunCluster(ctx context.Context) error {
+
+	defer c.cluster.leave()
+
+	ticker := time.NewTicker(c.cluster.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Rebalancing goes on with the members last seen, so the leases of
+		// the subscriptions are renewed even if this one was not
+		if err := c.cluster.heartbeat(); err != nil {
+			fmt.Println("Cluster heartbeat failed:", err)
+		}
+
+		subscribers, err := c.cluster.rebalance()
+		if err != nil {
+			fmt.Println("Cluster rebalance failed:", err)
+			c.cluster.fence()
+		}
+
+		if err := c.cluster.requeueExpired(ctx, subscribers); err != nil {
+			fmt.Println("Requeueing expired jobs failed:", err)
+		}
+	}
+}
+
+// ListClusterInstances returns the live instances sharing the database with
+// this one and how many subscriptions each holds.
+func (c *NoNoodleWorkflowCorePostgresql) ListClusterInstances() ([]entitites.CoreInstance, error) {
+
+	now := util.GetCurrentTime()
+	instances, err := c.repo.ListInstances(now)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers, err := c.repo.GetAllSubscribers()
+	if err != nil {
+		return nil, err
+	}
+
+	owned := map[string]int{}
+	for _, subscriber := range *subscribers {
+		if subscriber.OwnerLeaseExpireDate != nil && subscriber.OwnerLeaseExpireDate.After(now) {
+			owned[subscriber.OwnerInstanceID]++
+		}
+	}
+
+	for i := range instances {
+		instances[i].Self = instances[i].InstanceID == c.cluster.instanceID
+		instances[i].Subscriptions = owned[instances[i].InstanceID]
+	}
+	return instances, nil
+}
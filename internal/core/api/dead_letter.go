@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+// jsonPayload returns a job payload as JSON, a payload that is no valid JSON
+// becomes a JSON string.
+func jsonPayload(payload []byte) json.RawMessage {
+	if json.Valid(payload) {
+		return json.RawMessage(payload)
+	}
+	encoded, _ := json.Marshal(string(payload))
+	return encoded
+}
+
+func toDeadLetter(processID string, task string, deadLetter msgbroker.DeadLetter) entitites.DeadLetter {
+
+	return entitites.DeadLetter{
+		ID:          deadLetter.ID,
+		ProcessID:   processID,
+		Task:        task,
+		Payload:     jsonPayload(deadLetter.Payload),
+		Attempts:    deadLetter.Attempts,
+		LastError:   deadLetter.LastError,
+		EnqueueDate: deadLetter.EnqueuedAt,
+		DeadDate:    deadLetter.DeadDate,
+	}
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) ListDeadLetters(processID string, task string) ([]entitites.DeadLetter, error) {
+
+	deadLetters, err := c.pubsub.ListDeadLetters(context.Background(), taskChannal(processID, task))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]entitites.DeadLetter, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		result = append(result, toDeadLetter(processID, task, deadLetter))
+	}
+
+	return result, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) GetDeadLetter(processID string, task string, id string) (*entitites.DeadLetter, error) {
+
+	deadLetters, err := c.pubsub.ListDeadLetters(context.Background(), taskChannal(processID, task))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deadLetter := range deadLetters {
+		if deadLetter.ID == id {
+			result := toDeadLetter(processID, task, deadLetter)
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) ReplayDeadLetter(processID string, task string, id string) error {
+
+	replayed, err := c.pubsub.ReplayDeadLetter(context.Background(), taskChannal(processID, task), id)
+	if err != nil {
+		return err
+	}
+	if !replayed {
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) DeleteDeadLetter(processID string, task string, id string) error {
+
+	deleted, err := c.pubsub.DeleteDeadLetter(context.Background(), taskChannal(processID, task), id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) PurgeDeadLetters(processID string, task string) (int, error) {
+	return c.pubsub.PurgeDeadLetters(context.Background(), taskChannal(processID, task))
+}
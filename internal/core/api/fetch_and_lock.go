@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+const (
+	maxFetchJobs    = 100
+	maxFetchTimeout = time.Minute
+	// fetchLinger is how long a fetch already holding jobs waits for more
+	fetchLinger = 100 * time.Millisecond
+)
+
+// taskJob is the payload of every job published for a task
+type taskJob struct {
+	ProcessID  string `json:"process_id"`
+	TaskID     string `json:"task_id"`
+	WorkflowID string `json:"workflow_id"`
+}
+
+// fetchedMessage is a message reserved for a fetch. The fetch answers on more
+// whether it wants another message of the same queue.
+type fetchedMessage struct {
+	channal         string
+	envelope        *msgbroker.Envelope
+	leaseExpireDate time.Time
+	more            chan bool
+}
+
+// lockedJobRef is what a job ID handed to a pulling worker stands for. Message
+// IDs are only unique within a queue and stay the same when a job is
+// delivered again, so it names the task instance and the worker as well.
+type lockedJobRef struct {
+	workflowID string
+	task       string
+	messageID  string
+	workerID   string
+}
+
+func encodeJobID(ref lockedJobRef) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join([]string{ref.workflowID, ref.task, ref.messageID, ref.workerID}, "\n")))
+}
+
+func decodeJobID(jobID string) (lockedJobRef, error) {
+
+	decoded, err := base64.RawURLEncoding.DecodeString(jobID)
+	parts := strings.Split(string(decoded), "\n")
+	if err != nil || len(parts) != 4 {
+		return lockedJobRef{}, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+
+	return lockedJobRef{workflowID: parts[0], task: parts[1], messageID: parts[2], workerID: parts[3]}, nil
+}
+
+func validateFetchRequest(workerID string, topics []entitites.FetchTopic, maxJobs int, lockDuration time.Duration, timeout time.Duration) error {
+
+	if workerID == "" {
+		return fmt.Errorf("%w: worker_id is required", ErrInvalidFetchRequest)
+	}
+	if len(topics) == 0 {
+		return fmt.Errorf("%w: at least one topic is required", ErrInvalidFetchRequest)
+	}
+	for _, topic := range topics {
+		if topic.ProcessID == "" || topic.Task == "" {
+			return fmt.Errorf("%w: every topic needs process_id and task", ErrInvalidFetchRequest)
+		}
+	}
+	if maxJobs < 1 || maxJobs > maxFetchJobs {
+		return fmt.Errorf("%w: max_jobs must be between 1 and %d", ErrInvalidFetchRequest, maxFetchJobs)
+	}
+	if timeout < 0 || timeout > maxFetchTimeout {
+		return fmt.Errorf("%w: timeout must be between 0 and %v", ErrInvalidFetchRequest, maxFetchTimeout)
+	}
+	if lockDuration < 0 {
+		return fmt.Errorf("%w: lock duration %v", ErrInvalidTaskLease, lockDuration)
+	}
+
+	return nil
+}
+
+// FetchAndLockJobs reserves up to maxJobs jobs of topics for workerID, each
+// locked for lockDuration, 0 uses the task lease. It waits up to timeout for
+// the first job and returns the jobs that are available by then, possibly
+// none. Jobs go through the same queues and task states as pushed ones.
+func (c *NoNoodleWorkflowCorePostgresql) FetchAndLockJobs(workerID string, topics []entitites.FetchTopic, maxJobs int, lockDuration time.Duration, timeout time.Duration) ([]entitites.LockedJob, error) {
+
+	if err := validateFetchRequest(workerID, topics, maxJobs, lockDuration, timeout); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), max(timeout, fetchLinger))
+	defer cancel()
+
+	return c.fetchJobs(ctx, workerID, topics, maxJobs, lockDuration), nil
+}
+
+// fetchJobs locks up to maxJobs jobs of topics for workerID. It returns once it
+// has maxJobs, shortly after the last job when no more are queued, or when ctx
+// is done, whichever comes first.
+func (c *NoNoodleWorkflowCorePostgresql) fetchJobs(ctx context.Context, workerID string, topics []entitites.FetchTopic, maxJobs int, lockDuration time.Duration) []entitites.LockedJob {
+
+	// The worker names the consumer for brokers that track ownership
+	ctx, cancel := context.WithCancel(msgbroker.WithConsumer(ctx, workerID))
+	defer cancel()
+
+	fetched := make(chan fetchedMessage)
+	for _, topic := range topics {
+		channal := taskChannal(topic.ProcessID, topic.Task)
+		lease := c.jobLease
+		if lockDuration > 0 {
+			lease = func(*msgbroker.Envelope) time.Duration { return lockDuration }
+		}
+
+		// Reserves only while the fetch wants more. Every topic reserves at
+		// once, a message left over when the fetch is full is released without
+		// counting the attempt, or losing the race would dead-letter it
+		go func() {
+			for {
+				envelope, leaseExpireDate, err := c.pubsub.ReserveMessage(ctx, channal, lease)
+				if err != nil {
+					if ctx.Err() == nil {
+						fmt.Printf("Failed to fetch from %s for worker %s: %v\n", channal, workerID, err)
+					}
+					return
+				}
+
+				more := make(chan bool, 1)
+				select {
+				case fetched <- fetchedMessage{channal: channal, envelope: envelope, leaseExpireDate: leaseExpireDate, more: more}:
+				case <-ctx.Done():
+					// Reserved as the fetch returned, give it to the next worker
+					if err := c.pubsub.Release(context.Background(), channal, envelope.ID); err != nil {
+						fmt.Printf("Failed to release job %s of %s: %v\n", envelope.ID, channal, err)
+					}
+					return
+				}
+				if !<-more {
+					return
+				}
+			}
+		}()
+	}
+
+	jobs := []entitites.LockedJob{}
+	var linger <-chan time.Time
+	for len(jobs) < maxJobs {
+		select {
+		case <-ctx.Done():
+			return jobs
+		case <-linger:
+			return jobs
+		case message := <-fetched:
+			if job, ok := c.lockJob(workerID, message); ok {
+				jobs = append(jobs, job)
+			}
+			message.more <- len(jobs) < maxJobs
+			linger = time.After(fetchLinger)
+		}
+	}
+
+	return jobs
+}
+
+// lockJob records the lease and worker of a fetched job like deliverTask does
+// for pushed ones. It reports false when the job must not be handed out.
+func (c *NoNoodleWorkflowCorePostgresql) lockJob(workerID string, message fetchedMessage) (entitites.LockedJob, bool) {
+
+	var job taskJob
+	if err := json.Unmarshal(message.envelope.Payload, &job); err != nil {
+		fmt.Printf("Dead-lettering undecodable job %s of %s: %v\n", message.envelope.ID, message.channal, err)
+		if err := c.pubsub.DeadLetter(context.Background(), message.channal, message.envelope.ID, "invalid job payload: "+err.Error()); err != nil {
+			fmt.Printf("Failed to dead-letter job %s of %s: %v\n", message.envelope.ID, message.channal, err)
+		}
+		return entitites.LockedJob{}, false
+	}
+
+	if !c.leaseTask(job.WorkflowID, job.TaskID, message.envelope.ID, message.leaseExpireDate) {
+		// The task finished while its job was queued
+		if err := c.pubsub.Ack(context.Background(), message.channal, message.envelope.ID); err != nil {
+			fmt.Printf("Failed to ack job %s of %s: %v\n", message.envelope.ID, message.channal, err)
+		}
+		return entitites.LockedJob{}, false
+	}
+
+	if err := c.repo.UpdateTaskWorker(job.WorkflowID, job.TaskID, workerID); err != nil {
+		fmt.Printf("Failed to record worker for workflow %s task %s: %v\n", job.WorkflowID, job.TaskID, err)
+	}
+
+	return entitites.LockedJob{
+		JobID:           encodeJobID(lockedJobRef{workflowID: job.WorkflowID, task: job.TaskID, messageID: message.envelope.ID, workerID: workerID}),
+		ProcessID:       job.ProcessID,
+		TaskID:          job.TaskID,
+		WorkflowID:      job.WorkflowID,
+		Attempts:        message.envelope.Attempts,
+		LeaseExpireDate: message.leaseExpireDate,
+	}, true
+}
+
+// lockedTask returns the task instance of a fetched job, as long as the job is
+// the latest delivery of the still active task.
+func (c *NoNoodleWorkflowCorePostgresql) lockedTask(jobID string) (*entitites.TaskInstance, error) {
+
+	ref, err := decodeJobID(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskInstance, err := c.getTaskInstance(ref.workflowID, ref.task)
+	if err != nil {
+		return nil, err
+	}
+	if taskInstance.Status != TASK_STATUS_IN_ACTIVE {
+		return nil, fmt.Errorf("%w: workflow %s task %s is %s", ErrTaskNotActive, ref.workflowID, ref.task, taskInstance.Status)
+	}
+	if taskInstance.JobID != ref.messageID || taskInstance.Worker != ref.workerID {
+		return nil, fmt.Errorf("%w: job of workflow %s task %s was delivered again", ErrLeaseExpired, ref.workflowID, ref.task)
+	}
+
+	return taskInstance, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) CompleteJob(jobID string) error {
+
+	taskInstance, err := c.lockedTask(jobID)
+	if err != nil {
+		return err
+	}
+
+	return c.CompleteTask(taskInstance.WorkflowID, taskInstance.Task)
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) FailJob(jobID string) error {
+
+	taskInstance, err := c.lockedTask(jobID)
+	if err != nil {
+		return err
+	}
+
+	return c.FailedTask(taskInstance.WorkflowID, taskInstance.Task)
+}
+
+// ExtendJobLock extends the lease of a fetched job by lockDuration from now,
+// 0 uses the task lease, and returns when the new lease expires.
+func (c *NoNoodleWorkflowCorePostgresql) ExtendJobLock(jobID string, lockDuration time.Duration) (time.Time, error) {
+
+	if lockDuration < 0 {
+		return time.Time{}, fmt.Errorf("%w: lock duration %v", ErrInvalidTaskLease, lockDuration)
+	}
+
+	taskInstance, err := c.lockedTask(jobID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if lockDuration == 0 {
+		lockDuration = c.taskLease(taskInstance.WorkflowID, taskInstance.Task)
+	}
+	return c.extendTaskLease(taskInstance, lockDuration)
+}
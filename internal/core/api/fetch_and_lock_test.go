@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+// startBusyTopics deploys process bp with start tasks a and b and starts
+// workflows, leaving both queues with that many jobs.
+func startBusyTopics(t *testing.T, core api.NoNoodleCoreInterface, workflows int) []entitites.FetchTopic {
+	t.Helper()
+
+	err := core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "bp",
+		MapStageTask:  map[string][]string{"start": {"a", "b"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range workflows {
+		if _, err := core.CreateWorkflow("bp", "bk"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return []entitites.FetchTopic{{ProcessID: "bp", Task: "a"}, {ProcessID: "bp", Task: "b"}}
+}
+
+// wantNoAttemptsLost checks that no job of topics was dead-lettered and that
+// the jobs still queued were never counted as delivered.
+func wantNoAttemptsLost(t *testing.T, core api.NoNoodleCoreInterface, topics []entitites.FetchTopic) {
+	t.Helper()
+
+	for _, topic := range topics {
+		deadLetters, err := core.ListDeadLetters(topic.ProcessID, topic.Task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deadLetters) != 0 {
+			t.Fatalf("task %s has %d dead letters, want none", topic.Task, len(deadLetters))
+		}
+
+		jobs, err := core.ListQueueJobs(topic.ProcessID, topic.Task, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, job := range jobs {
+			if job.State != msgbroker.MessageStateReserved && job.Attempts != 0 {
+				t.Fatalf("queued job %s of task %s has %d attempts, want 0", job.ID, topic.Task, job.Attempts)
+			}
+		}
+	}
+}
+
+// TestFetchReleasesUnusedReservations fetches one job at a time from two busy
+// topics, the topic losing each fetch must not be charged an attempt.
+func TestFetchReleasesUnusedReservations(t *testing.T) {
+	core, _ := newCore(t)
+	topics := startBusyTopics(t, core, 10)
+
+	for range 8 {
+		jobs, err := core.FetchAndLockJobs("w", topics, 1, 0, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs) != 1 {
+			t.Fatalf("fetched %d jobs, want 1", len(jobs))
+		}
+		if err := core.CompleteJob(jobs[0].JobID); err != nil {
+			t.Fatal(err)
+		}
+		wantNoAttemptsLost(t, core, topics)
+	}
+}
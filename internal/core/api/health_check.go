@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// Bounds of the health check a subscriber may ask for, a zero setting takes
+// the default, which is what every subscriber got before it could choose.
+const (
+	defaultHealthCheckInterval = 5
+	minHealthCheckInterval     = 1
+	maxHealthCheckInterval     = 300
+
+	defaultHealthCheckTimeout = 5
+	minHealthCheckTimeout     = 1
+	maxHealthCheckTimeout     = 60
+
+	defaultHealthCheckFailureThreshold = 10
+	minHealthCheckFailureThreshold     = 1
+	maxHealthCheckFailureThreshold     = 100
+
+	defaultHealthCheckMethod  = http.MethodGet
+	maxHealthCheckStatusCodes = 10
+)
+
+var (
+	ErrInvalidHealthCheck = errors.New("invalid health check")
+
+	healthCheckMethods            = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodOptions}
+	defaultHealthCheckStatusCodes = []int{http.StatusOK}
+)
+
+// normalizeHealthCheck fills in the defaults of healthCheck and rejects
+// settings outside the bounds with ErrInvalidHealthCheck.
+func normalizeHealthCheck(healthCheck entitites.HealthCheckConfig) (entitites.HealthCheckConfig, error) {
+
+	inBounds := func(name string, value *int, defaultValue int, minValue int, maxValue int) error {
+		if *value == 0 {
+			*value = defaultValue
+		}
+		if *value < minValue || *value > maxValue {
+			return fmt.Errorf("%w: %s %d is not within %d and %d", ErrInvalidHealthCheck, name, *value, minValue, maxValue)
+		}
+		return nil
+	}
+
+	if err := inBounds("interval_seconds", &healthCheck.IntervalSeconds, defaultHealthCheckInterval, minHealthCheckInterval, maxHealthCheckInterval); err != nil {
+		return healthCheck, err
+	}
+	if err := inBounds("timeout_seconds", &healthCheck.TimeoutSeconds, defaultHealthCheckTimeout, minHealthCheckTimeout, maxHealthCheckTimeout); err != nil {
+		return healthCheck, err
+	}
+	if err := inBounds("failure_threshold", &healthCheck.FailureThreshold, defaultHealthCheckFailureThreshold, minHealthCheckFailureThreshold, maxHealthCheckFailureThreshold); err != nil {
+		return healthCheck, err
+	}
+
+	healthCheck.Method = strings.ToUpper(healthCheck.Method)
+	if healthCheck.Method == "" {
+		healthCheck.Method = defaultHealthCheckMethod
+	}
+	if !slices.Contains(healthCheckMethods, healthCheck.Method) {
+		return healthCheck, fmt.Errorf("%w: method %s is not one of %s", ErrInvalidHealthCheck, healthCheck.Method, strings.Join(healthCheckMethods, ", "))
+	}
+
+	if len(healthCheck.StatusCodes) == 0 {
+		healthCheck.StatusCodes = slices.Clone(defaultHealthCheckStatusCodes)
+	}
+	if len(healthCheck.StatusCodes) > maxHealthCheckStatusCodes {
+		return healthCheck, fmt.Errorf("%w: at most %d status codes", ErrInvalidHealthCheck, maxHealthCheckStatusCodes)
+	}
+	for _, statusCode := range healthCheck.StatusCodes {
+		if statusCode < 100 || statusCode > 599 {
+			return healthCheck, fmt.Errorf("%w: status code %d", ErrInvalidHealthCheck, statusCode)
+		}
+	}
+
+	return healthCheck, nil
+}
+
+// checkSubscriberHealth sends one health check to healthCheckURL.
+func (c *NoNoodleWorkflowCorePostgresql) checkSubscriberHealth(healthCheckURL string, healthCheck entitites.HealthCheckConfig) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(healthCheck.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, healthCheck.Method, healthCheckURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if !slices.Contains(healthCheck.StatusCodes, resp.StatusCode) {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func TestNormalizeHealthCheck(t *testing.T) {
+	defaults := entitites.HealthCheckConfig{
+		IntervalSeconds:  5,
+		TimeoutSeconds:   5,
+		FailureThreshold: 10,
+		Method:           "GET",
+		StatusCodes:      []int{200},
+	}
+	tooManyStatusCodes := []int{200, 201, 202, 203, 204, 205, 206, 207, 208, 226, 299}
+
+	tests := []struct {
+		name        string
+		healthCheck entitites.HealthCheckConfig
+		want        entitites.HealthCheckConfig
+		wantErr     bool
+	}{
+		{name: "defaults", healthCheck: entitites.HealthCheckConfig{}, want: defaults},
+		{
+			name: "within bounds",
+			healthCheck: entitites.HealthCheckConfig{
+				IntervalSeconds: 300, TimeoutSeconds: 60, FailureThreshold: 100, Method: "HEAD", StatusCodes: []int{100, 204, 599},
+			},
+			want: entitites.HealthCheckConfig{
+				IntervalSeconds: 300, TimeoutSeconds: 60, FailureThreshold: 100, Method: "HEAD", StatusCodes: []int{100, 204, 599},
+			},
+		},
+		{
+			name:        "lower bounds",
+			healthCheck: entitites.HealthCheckConfig{IntervalSeconds: 1, TimeoutSeconds: 1, FailureThreshold: 1},
+			want: entitites.HealthCheckConfig{
+				IntervalSeconds: 1, TimeoutSeconds: 1, FailureThreshold: 1, Method: "GET", StatusCodes: []int{200},
+			},
+		},
+		{name: "interval too long", healthCheck: entitites.HealthCheckConfig{IntervalSeconds: 301}, wantErr: true},
+		{name: "negative interval", healthCheck: entitites.HealthCheckConfig{IntervalSeconds: -1}, wantErr: true},
+		{name: "timeout too long", healthCheck: entitites.HealthCheckConfig{TimeoutSeconds: 61}, wantErr: true},
+		{name: "negative timeout", healthCheck: entitites.HealthCheckConfig{TimeoutSeconds: -1}, wantErr: true},
+		{name: "failure threshold too high", healthCheck: entitites.HealthCheckConfig{FailureThreshold: 101}, wantErr: true},
+		{name: "negative failure threshold", healthCheck: entitites.HealthCheckConfig{FailureThreshold: -1}, wantErr: true},
+		{name: "lower case method", healthCheck: entitites.HealthCheckConfig{Method: "post"}, want: withMethod(defaults, "POST")},
+		{name: "options method", healthCheck: entitites.HealthCheckConfig{Method: "OPTIONS"}, want: withMethod(defaults, "OPTIONS")},
+		{name: "method not allowed", healthCheck: entitites.HealthCheckConfig{Method: "DELETE"}, wantErr: true},
+		{name: "unknown method", healthCheck: entitites.HealthCheckConfig{Method: "BREW"}, wantErr: true},
+		{name: "most status codes", healthCheck: entitites.HealthCheckConfig{StatusCodes: tooManyStatusCodes[:10]}, want: withStatusCodes(defaults, tooManyStatusCodes[:10])},
+		{name: "too many status codes", healthCheck: entitites.HealthCheckConfig{StatusCodes: tooManyStatusCodes}, wantErr: true},
+		{name: "status code too low", healthCheck: entitites.HealthCheckConfig{StatusCodes: []int{200, 99}}, wantErr: true},
+		{name: "status code too high", healthCheck: entitites.HealthCheckConfig{StatusCodes: []int{600}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeHealthCheck(tt.healthCheck)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHealthCheck) {
+					t.Fatalf("got %v, want %v", err, ErrInvalidHealthCheck)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The default status codes are cloned, normalizing must not hand out the
+// package level slice.
+func TestNormalizeHealthCheckClonesDefaults(t *testing.T) {
+	healthCheck, err := normalizeHealthCheck(entitites.HealthCheckConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthCheck.StatusCodes[0] = 500
+
+	if defaultHealthCheckStatusCodes[0] != 200 {
+		t.Fatalf("got default status codes %v, want [200]", defaultHealthCheckStatusCodes)
+	}
+}
+
+func withMethod(healthCheck entitites.HealthCheckConfig, method string) entitites.HealthCheckConfig {
+	healthCheck.Method = method
+	return healthCheck
+}
+
+func withStatusCodes(healthCheck entitites.HealthCheckConfig, statusCodes []int) entitites.HealthCheckConfig {
+	healthCheck.StatusCodes = statusCodes
+	return healthCheck
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// JobStream delivers jobs to a worker holding one persistent connection,
+// instead of pushing them to a callback URL. The worker subscribes to any
+// number of process/task pairs and is handed up to maxJobs jobs at a time,
+// each locked like a fetched job. A job frees its slot when the worker
+// completes or fails it through the stream or when its lock runs out.
+//
+// The connection itself tells whether the worker is alive, jobs still locked
+// when Run returns are released for redelivery right away.
+type JobStream struct {
+	core         *NoNoodleWorkflowCorePostgresql
+	workerID     string
+	maxJobs      int
+	lockDuration time.Duration
+
+	mu     sync.Mutex
+	topics []entitites.FetchTopic
+	locked map[string]streamedJob
+	// topicsChanged interrupts the fetch in progress, slotFreed wakes a
+	// stream waiting for the worker to finish a job
+	topicsChanged chan struct{}
+	slotFreed     chan struct{}
+}
+
+type streamedJob struct {
+	channal         string
+	messageID       string
+	leaseExpireDate time.Time
+	// delivered is false for jobs fetched after a send failed, they never
+	// reached the worker
+	delivered bool
+}
+
+// OpenJobStream prepares a stream of jobs for workerID, locked for
+// lockDuration, 0 uses the task lease. Nothing is delivered before Run.
+func (c *NoNoodleWorkflowCorePostgresql) OpenJobStream(workerID string, maxJobs int, lockDuration time.Duration) (*JobStream, error) {
+
+	if workerID == "" {
+		return nil, fmt.Errorf("%w: worker_id is required", ErrInvalidFetchRequest)
+	}
+	if maxJobs < 1 || maxJobs > maxFetchJobs {
+		return nil, fmt.Errorf("%w: max_jobs must be between 1 and %d", ErrInvalidFetchRequest, maxFetchJobs)
+	}
+	if lockDuration < 0 {
+		return nil, fmt.Errorf("%w: lock duration %v", ErrInvalidTaskLease, lockDuration)
+	}
+
+	return &JobStream{
+		core:          c,
+		workerID:      workerID,
+		maxJobs:       maxJobs,
+		lockDuration:  lockDuration,
+		locked:        map[string]streamedJob{},
+		topicsChanged: make(chan struct{}, 1),
+		slotFreed:     make(chan struct{}, 1),
+	}, nil
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe adds topics to the stream, topics already subscribed are ignored.
+func (s *JobStream) Subscribe(topics []entitites.FetchTopic) error {
+
+	if len(topics) == 0 {
+		return fmt.Errorf("%w: at least one topic is required", ErrInvalidFetchRequest)
+	}
+	for _, topic := range topics {
+		if topic.ProcessID == "" || topic.Task == "" {
+			return fmt.Errorf("%w: every topic needs process_id and task", ErrInvalidFetchRequest)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, topic := range topics {
+		if !slices.Contains(s.topics, topic) {
+			s.topics = append(s.topics, topic)
+		}
+	}
+	notify(s.topicsChanged)
+
+	return nil
+}
+
+// Unsubscribe removes topics from the stream, jobs already delivered stay
+// locked to the worker.
+func (s *JobStream) Unsubscribe(topics []entitites.FetchTopic) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.topics = slices.DeleteFunc(s.topics, func(topic entitites.FetchTopic) bool {
+		return slices.Contains(topics, topic)
+	})
+	notify(s.topicsChanged)
+}
+
+// Topics returns the topics the stream is subscribed to.
+func (s *JobStream) Topics() []entitites.FetchTopic {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.topics)
+}
+
+// freeSlots drops jobs whose lock ran out and returns how many more jobs the
+// worker takes, and when the next lock runs out.
+func (s *JobStream) freeSlots() (int, time.Time) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var nextExpiry time.Time
+	for jobID, job := range s.locked {
+		if !job.leaseExpireDate.After(now) {
+			delete(s.locked, jobID)
+			continue
+		}
+		if nextExpiry.IsZero() || job.leaseExpireDate.Before(nextExpiry) {
+			nextExpiry = job.leaseExpireDate
+		}
+	}
+
+	return s.maxJobs - len(s.locked), nextExpiry
+}
+
+// Run delivers jobs of the subscribed topics to send until ctx is done or send
+// fails. A job send fails on is released for redelivery.
+func (s *JobStream) Run(ctx context.Context, send func(job entitites.LockedJob) error) error {
+
+	defer s.releaseLocked()
+
+	for {
+		// The topics are read below, only a change after that restarts the fetch
+		select {
+		case <-s.topicsChanged:
+		default:
+		}
+
+		free, nextExpiry := s.freeSlots()
+		topics := s.Topics()
+
+		if free == 0 || len(topics) == 0 {
+			var expired <-chan time.Time
+			if !nextExpiry.IsZero() {
+				expired = time.After(time.Until(nextExpiry))
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.topicsChanged:
+			case <-s.slotFreed:
+			case <-expired:
+			}
+			continue
+		}
+
+		// A subscription change restarts the fetch with the new topics
+		fetchCtx, cancel := context.WithCancel(ctx)
+		watching := make(chan struct{})
+		go func() {
+			defer close(watching)
+			select {
+			case <-s.topicsChanged:
+				cancel()
+			case <-fetchCtx.Done():
+			}
+		}()
+		jobs := s.core.fetchJobs(fetchCtx, s.workerID, topics, free, s.lockDuration)
+		cancel()
+		<-watching
+
+		for i, job := range jobs {
+			s.track(job, true)
+			if err := send(job); err != nil {
+				// Nothing after the failed send reached the worker either
+				for _, job := range jobs[i+1:] {
+					s.track(job, false)
+				}
+				return fmt.Errorf("failed to send job %s: %w", job.JobID, err)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (s *JobStream) track(job entitites.LockedJob, delivered bool) {
+
+	ref, err := decodeJobID(job.JobID)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locked[job.JobID] = streamedJob{
+		channal:         taskChannal(job.ProcessID, job.TaskID),
+		messageID:       ref.messageID,
+		leaseExpireDate: job.LeaseExpireDate,
+		delivered:       delivered,
+	}
+}
+
+// done frees the slot of jobID once the job no longer holds its lock.
+func (s *JobStream) done(jobID string, err error) {
+
+	if err != nil && !errors.Is(err, ErrTaskNotActive) && !errors.Is(err, ErrLeaseExpired) && !errors.Is(err, ErrJobNotFound) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.locked[jobID]; ok {
+		delete(s.locked, jobID)
+		notify(s.slotFreed)
+	}
+}
+
+// Complete completes a job delivered by the stream and frees its slot.
+func (s *JobStream) Complete(jobID string) error {
+
+	err := s.core.CompleteJob(jobID)
+	s.done(jobID, err)
+	return err
+}
+
+// Fail fails a job delivered by the stream and frees its slot.
+func (s *JobStream) Fail(jobID string) error {
+
+	err := s.core.FailJob(jobID)
+	s.done(jobID, err)
+	return err
+}
+
+// Extend extends the lock of a job delivered by the stream like
+// ExtendJobLock and returns when the new lock expires.
+func (s *JobStream) Extend(jobID string, lockDuration time.Duration) (time.Time, error) {
+
+	leaseExpireDate, err := s.core.ExtendJobLock(jobID, lockDuration)
+	if err != nil {
+		s.done(jobID, err)
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.locked[jobID]; ok {
+		job.leaseExpireDate = leaseExpireDate
+		s.locked[jobID] = job
+	}
+
+	return leaseExpireDate, nil
+}
+
+// releaseLocked hands the jobs the worker still holds to the next worker
+// instead of waiting for their locks to run out. A job that never reached the
+// worker is released without counting the attempt, a delivered one keeps it
+// so a job that keeps breaking its workers is still dead-lettered.
+func (s *JobStream) releaseLocked() {
+
+	s.mu.Lock()
+	locked := s.locked
+	s.locked = map[string]streamedJob{}
+	s.mu.Unlock()
+
+	for jobID, job := range locked {
+		if _, err := s.core.lockedTask(jobID); err != nil {
+			continue
+		}
+		release := s.core.pubsub.Nack
+		if !job.delivered {
+			release = s.core.pubsub.Release
+		}
+		if err := release(context.Background(), job.channal, job.messageID); err != nil {
+			fmt.Printf("Failed to release job %s of %s for worker %s: %v\n", job.messageID, job.channal, s.workerID, err)
+		}
+	}
+}
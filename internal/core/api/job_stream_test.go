@@ -0,0 +1,53 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// TestJobStreamReleasesUnusedReservations streams two busy topics to a worker
+// taking one job at a time. Every job handed out fetches from both topics, the
+// topic losing must not be charged an attempt for it.
+func TestJobStreamReleasesUnusedReservations(t *testing.T) {
+	core, _ := newCore(t)
+	topics := startBusyTopics(t, core, 10)
+
+	stream, err := core.OpenJobStream("w", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Subscribe(topics); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := make(chan entitites.LockedJob)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- stream.Run(ctx, func(job entitites.LockedJob) error {
+			jobs <- job
+			return nil
+		})
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// More rounds than the 3 delivery attempts the core allows
+	for range 8 {
+		var job entitites.LockedJob
+		select {
+		case job = <-jobs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the stream delivered no job")
+		}
+		wantNoAttemptsLost(t, core, topics)
+		if err := stream.Complete(job.JobID); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+// MessageService delivers workflow tasks through a msgbroker.MessageBroker used
+// as a simple message channal. Messages are persisted in the broker until a
+// consumer reserves and acks them.
+//
+// A message whose handler keeps failing is dead-lettered once it has been
+// delivered maxDeliveryAttempts times, 0 retries forever. defaultLease is the
+// visibility timeout of tasks without a lease of their own. deliveryPolicy
+// paces the deliveries to subscribers that keep failing.
+type MessageService struct {
+	broker              msgbroker.MessageBroker
+	maxDeliveryAttempts int
+	defaultLease        time.Duration
+	deliveryPolicy      DeliveryPolicy
+}
+
+// NewMessageService creates a new channal-based messaging service
+func NewMessageService(broker msgbroker.MessageBroker, maxDeliveryAttempts int, defaultLease time.Duration, deliveryPolicy DeliveryPolicy) *MessageService {
+	return &MessageService{
+		broker:              broker,
+		maxDeliveryAttempts: maxDeliveryAttempts,
+		defaultLease:        defaultLease,
+		deliveryPolicy:      deliveryPolicy,
+	}
+}
+
+// NewCircuitBreaker returns the delivery state of a new subscriber under the
+// delivery policy of the service.
+func (ps *MessageService) NewCircuitBreaker() *CircuitBreaker {
+	return newCircuitBreaker(ps.deliveryPolicy)
+}
+
+// SendToMsgChannal enqueues a message into the broker queue (channal) for the given topic.
+// The channal key is prefix + topic (e.g. "workflow:task0").
+func (ps *MessageService) SendToMsgChannal(ctx context.Context, channal string, payload []byte) error {
+
+	return ps.broker.Enqueue(ctx, channal, payload)
+}
+
+// SendToMsgChannalAt enqueues a message that is delivered no earlier than
+// deliverAt, for retries with backoff and scheduled work.
+func (ps *MessageService) SendToMsgChannalAt(ctx context.Context, channal string, payload []byte, deliverAt time.Time) error {
+
+	return ps.broker.EnqueueAt(ctx, channal, payload, deliverAt)
+}
+
+// outbox holds the messages sent within a transaction that the broker cannot
+// enqueue in it, until the transaction has committed.
+type outbox struct {
+	messages []outboxMessage
+}
+
+type outboxMessage struct {
+	channal string
+	payload []byte
+}
+
+// SendToMsgChannalTx enqueues within tx when the broker shares the workflow
+// database. Any other broker would hand the message to a consumer before the
+// task it stands for is committed, so it is kept in pending and only enqueued
+// by SendPending once tx has committed.
+func (ps *MessageService) SendToMsgChannalTx(ctx context.Context, tx *sql.Tx, channal string, payload []byte, pending *outbox) error {
+
+	if txBroker, ok := ps.broker.(msgbroker.TxMessageBroker); ok {
+		return txBroker.EnqueueTx(ctx, tx, channal, payload)
+	}
+
+	pending.messages = append(pending.messages, outboxMessage{channal: channal, payload: payload})
+	return nil
+}
+
+// SendPending enqueues the messages kept back by SendToMsgChannalTx, call it
+// once their transaction has committed. The workflow changes are already
+// committed, so a message that cannot be enqueued is only logged.
+func (ps *MessageService) SendPending(ctx context.Context, pending *outbox) {
+
+	for _, message := range pending.messages {
+		if err := ps.broker.Enqueue(ctx, message.channal, message.payload); err != nil {
+			log.Println("error enqueueing committed message to channal:", message.channal, "error:", err, "payload:", string(message.payload))
+		}
+	}
+	pending.messages = nil
+}
+
+// SubscribeChannal continuously dequeues messages from the topic channal and processes them.
+// Every message is reserved for the lease returned for it by lease, the handler
+// gets the time that lease runs out unless it is extended with ExtendLease.
+// The handler gets ctx too, cancelling the subscription aborts a delivery in
+// flight, which leaves its message reserved without counting it as failed.
+// A handled message stays reserved until the work it stands for is done and
+// the message is Ack'ed, if the lease runs out first it is delivered again
+// once the cluster requeues the expired messages.
+//
+// breaker paces the deliveries. After a failure nothing is reserved until its
+// backoff is over, and the failed message is held back for as long instead of
+// for its whole lease. While the circuit is open nothing is reserved at all.
+// This blocks until the context is cancelled or an unrecoverable error occurs.
+func (ps *MessageService) SubscribeChannal(ctx context.Context, callbackURL string, channal string, lease func(envelope *msgbroker.Envelope) time.Duration, handler func(ctx context.Context, callbackURL string, envelope *msgbroker.Envelope, leaseExpireDate time.Time) error, breaker *CircuitBreaker) {
+
+	fmt.Println("Consuming messages from channal:", channal)
+
+	for {
+		// Exit if context is cancelled, otherwise wait out a backoff or open circuit
+		if !breaker.Wait(ctx) {
+			fmt.Println("Context cancelled, stopping subscription to channal:", channal, "error:", ctx.Err())
+			return
+		}
+
+		// Reserve a message with the task lease as visibility timeout
+		envelope, leaseExpireDate, err := ps.ReserveMessage(ctx, channal, lease)
+		if err != nil {
+			// If the context was cancelled, just exit
+			if ctx.Err() != nil {
+				fmt.Println("Context cancelled, stopping subscription to channal:", channal, "error:", ctx.Err())
+				return
+			}
+			log.Println("error reserving from channal:", err)
+			continue
+		}
+
+		if err := handler(ctx, callbackURL, envelope, leaseExpireDate); err != nil {
+			if ctx.Err() != nil {
+				fmt.Println("Context cancelled, stopping subscription to channal:", channal, "error:", ctx.Err())
+				return
+			}
+			delay := breaker.Failure(err)
+			log.Println("error handling message", envelope.ID, "from channal:", err, "attempt:", envelope.Attempts, "retry in:", delay)
+			if ps.maxDeliveryAttempts > 0 && envelope.Attempts >= ps.maxDeliveryAttempts {
+				if err := ps.broker.DeadLetter(context.Background(), channal, envelope.ID, err.Error()); err != nil {
+					log.Println("error dead-lettering message from channal:", err)
+				}
+				continue
+			}
+			// Do not Ack; message will be re-delivered once the delay is over,
+			// or after its visibility timeout should that be shorter
+			if delay < time.Until(leaseExpireDate) {
+				if _, err := ps.broker.Extend(context.Background(), channal, envelope.ID, delay); err != nil {
+					log.Println("error holding back message from channal:", err)
+				}
+			}
+			continue
+		}
+		breaker.Success()
+	}
+}
+
+// ReserveMessage reserves the next message of channal worth delivering for
+// the lease returned for it by lease and returns when that runs out. The lease
+// depends on the message, so it is reserved for the default lease first and
+// extended to its own lease once known. Empty messages are dropped and
+// messages whose last allowed delivery expired are dead-lettered on the way.
+func (ps *MessageService) ReserveMessage(ctx context.Context, channal string, lease func(envelope *msgbroker.Envelope) time.Duration) (*msgbroker.Envelope, time.Time, error) {
+
+	for {
+		envelope, err := ps.broker.Reserve(ctx, channal, ps.defaultLease)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		leaseExpireDate := time.Now().Add(ps.defaultLease)
+		if len(envelope.Payload) == 0 {
+			ps.broker.Ack(context.Background(), channal, envelope.ID)
+			continue
+		}
+
+		// The previous delivery was the last one allowed and its lease expired
+		if ps.maxDeliveryAttempts > 0 && envelope.Attempts > ps.maxDeliveryAttempts {
+			log.Println("message", envelope.ID, "from channal:", channal, "exceeded", ps.maxDeliveryAttempts, "deliveries")
+			if err := ps.broker.DeadLetter(context.Background(), channal, envelope.ID, "lease expired"); err != nil {
+				log.Println("error dead-lettering message from channal:", err)
+			}
+			continue
+		}
+
+		if visibilityTimeout := lease(envelope); visibilityTimeout != ps.defaultLease {
+			extended, err := ps.broker.Extend(context.Background(), channal, envelope.ID, visibilityTimeout)
+			if err != nil {
+				log.Println("error leasing message", envelope.ID, "from channal:", err)
+			} else if extended {
+				leaseExpireDate = time.Now().Add(visibilityTimeout)
+			}
+		}
+
+		return envelope, leaseExpireDate, nil
+	}
+}
+
+// RemoveConsumer forgets a consumer of channal that stopped reserving, for
+// brokers that keep track of their consumers.
+func (ps *MessageService) RemoveConsumer(ctx context.Context, channal string, consumer string) error {
+
+	if consumerBroker, ok := ps.broker.(msgbroker.ConsumerMessageBroker); ok {
+		return consumerBroker.RemoveConsumer(ctx, channal, consumer)
+	}
+
+	return nil
+}
+
+// RequeueExpired makes messages of channal whose lease ran out available again
+func (ps *MessageService) RequeueExpired(ctx context.Context, channal string) error {
+	return ps.broker.RequeueExpired(ctx, channal)
+}
+
+// Ack confirms a message handed to a worker once its task is finished
+func (ps *MessageService) Ack(ctx context.Context, channal string, id string) error {
+	return ps.broker.Ack(ctx, channal, id)
+}
+
+// ExtendLease gives the worker holding message id another lease from now, it
+// returns false when the message is no longer reserved.
+func (ps *MessageService) ExtendLease(ctx context.Context, channal string, id string, lease time.Duration) (bool, error) {
+	return ps.broker.Extend(ctx, channal, id, lease)
+}
+
+// Nack makes a reserved message available again right away
+func (ps *MessageService) Nack(ctx context.Context, channal string, id string) error {
+	return ps.broker.Nack(ctx, channal, id)
+}
+
+// Release makes a reserved message that never reached a worker available
+// again right away, without counting the reservation as a delivery attempt.
+func (ps *MessageService) Release(ctx context.Context, channal string, id string) error {
+	return ps.broker.Release(ctx, channal, id)
+}
+
+func (ps *MessageService) DeadLetter(ctx context.Context, channal string, id string, lastError string) error {
+	return ps.broker.DeadLetter(ctx, channal, id, lastError)
+}
+
+func (ps *MessageService) ListDeadLetters(ctx context.Context, channal string) ([]msgbroker.DeadLetter, error) {
+	return ps.broker.ListDeadLetters(ctx, channal)
+}
+
+func (ps *MessageService) DeleteDeadLetter(ctx context.Context, channal string, id string) (bool, error) {
+	return ps.broker.DeleteDeadLetter(ctx, channal, id)
+}
+
+func (ps *MessageService) PurgeDeadLetters(ctx context.Context, channal string) (int, error) {
+	return ps.broker.PurgeDeadLetters(ctx, channal)
+}
+
+// ReplayDeadLetter enqueues the dead letter again before removing it, a crash
+// in between delivers it twice rather than losing it.
+func (ps *MessageService) ReplayDeadLetter(ctx context.Context, channal string, id string) (bool, error) {
+
+	deadLetters, err := ps.broker.ListDeadLetters(ctx, channal)
+	if err != nil {
+		return false, err
+	}
+
+	for _, deadLetter := range deadLetters {
+		if deadLetter.ID != id {
+			continue
+		}
+		if err := ps.broker.Enqueue(ctx, channal, deadLetter.Payload); err != nil {
+			return false, err
+		}
+		if _, err := ps.broker.DeleteDeadLetter(ctx, channal, id); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (ps *MessageService) QueueStats(ctx context.Context, channal string) (msgbroker.QueueStats, error) {
+	return ps.broker.Stats(ctx, channal)
+}
+
+func (ps *MessageService) ListMessages(ctx context.Context, channal string, limit int) ([]msgbroker.QueuedMessage, error) {
+	return ps.broker.ListMessages(ctx, channal, limit)
+}
+
+func (ps *MessageService) DeleteMessage(ctx context.Context, channal string, id string) (bool, error) {
+	return ps.broker.Delete(ctx, channal, id)
+}
+
+func (ps *MessageService) PurgeMessages(ctx context.Context, channal string) (int, error) {
+	return ps.broker.Purge(ctx, channal)
+}
+
+// selectMessages returns the messages of channal in one of states, only those
+// listed in ids unless ids is empty.
+func (ps *MessageService) selectMessages(ctx context.Context, channal string, ids []string, states ...string) ([]msgbroker.QueuedMessage, error) {
+
+	messages, err := ps.broker.ListMessages(ctx, channal, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := []msgbroker.QueuedMessage{}
+	for _, message := range messages {
+		if slices.Contains(states, message.State) && (len(ids) == 0 || slices.Contains(ids, message.ID)) {
+			selected = append(selected, message)
+		}
+	}
+	return selected, nil
+}
+
+// RequeueReserved makes reserved messages of channal available again right
+// away, whether their lease expired or not. It returns how many it released.
+func (ps *MessageService) RequeueReserved(ctx context.Context, channal string, ids []string) (int, error) {
+
+	reserved, err := ps.selectMessages(ctx, channal, ids, msgbroker.MessageStateReserved)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, message := range reserved {
+		if err := ps.broker.Nack(ctx, channal, message.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(reserved), nil
+}
+
+// MoveMessages moves ready and delayed messages of channal to target, delayed
+// ones keep their due time. Each payload is moved as rewritten by rewrite.
+// Like ReplayDeadLetter it enqueues before it deletes, a message reserved or a
+// crash in between delivers it twice rather than losing it.
+func (ps *MessageService) MoveMessages(ctx context.Context, channal string, target string, ids []string, rewrite func(payload []byte) ([]byte, error)) (int, error) {
+
+	messages, err := ps.selectMessages(ctx, channal, ids, msgbroker.MessageStateReady, msgbroker.MessageStateDelayed)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, message := range messages {
+		payload, err := rewrite(message.Payload)
+		if err != nil {
+			return i, err
+		}
+		if message.State == msgbroker.MessageStateDelayed {
+			err = ps.broker.EnqueueAt(ctx, target, payload, *message.VisibleAt)
+		} else {
+			err = ps.broker.Enqueue(ctx, target, payload)
+		}
+		if err != nil {
+			return i, err
+		}
+		if _, err := ps.broker.Delete(ctx, channal, message.ID); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(messages), nil
+}
@@ -5,48 +5,122 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
 )
 
 type NoNoodleWorkflowCorePostgresql struct {
-	httpClient *http.Client
-	repo       *repository.PostgreSQLNoNoodleWorkflow
-	pubsub     *RedisMessageService
+	httpClient     *http.Client
+	repo           repository.NoNoodleWorkflowRepository
+	pubsub         *MessageService
+	workflowEvents *workflowEventHub
+	subscribers    *subscriberSupervisor
+	cluster        *clusterMember
 }
 
 const (
-	TASK_STATUS_WAITING   = "waiting"
-	TASK_STATUS_IN_ACTIVE = "active"
-	TASK_STATUS_COMPLETED = "completed"
-	TASK_STATUS_FAILED    = "failed"
+	TASK_STATUS_WAITING   = entitites.TASK_STATUS_WAITING
+	TASK_STATUS_IN_ACTIVE = entitites.TASK_STATUS_IN_ACTIVE
+	TASK_STATUS_COMPLETED = entitites.TASK_STATUS_COMPLETED
+	TASK_STATUS_FAILED    = entitites.TASK_STATUS_FAILED
+
+	WORKFLOW_STATUS_RUNNING   = entitites.WORKFLOW_STATUS_RUNNING
+	WORKFLOW_STATUS_COMPLETED = entitites.WORKFLOW_STATUS_COMPLETED
+	WORKFLOW_STATUS_FAILED    = entitites.WORKFLOW_STATUS_FAILED
+)
+
+var (
+	ErrWorkflowNotFound       = errors.New("workflow not found")
+	ErrInvalidWorkflowQuery   = repository.ErrInvalidWorkflowQuery
+	ErrProcessNotFound        = errors.New("process not found")
+	ErrProcessConfigNotFound  = errors.New("process config not found")
+	ErrProcessDisabled        = errors.New("process is disabled")
+	ErrProcessConfigIsInUse   = errors.New("process config is still referenced by workflows")
+	ErrInvalidRetentionPolicy = errors.New("invalid retention policy")
+	ErrDeadLetterNotFound     = errors.New("dead letter not found")
+	ErrInvalidTaskLease       = errors.New("invalid task lease")
+	ErrInvalidProcessID       = errors.New("invalid process id")
+	ErrTaskNotFound           = errors.New("task not found")
+	ErrTaskNotActive          = errors.New("task is not active")
+	ErrLeaseExpired           = errors.New("task lease expired")
+	ErrJobNotFound            = errors.New("job not found")
+	ErrInvalidQueueMove       = errors.New("invalid queue move")
+	ErrInvalidFetchRequest    = errors.New("invalid fetch request")
 )
 
 type NoNoodleCoreInterface interface {
 	DeployProcessConfig(processConfig *entitites.ProcessConfig) error
 	CompleteTask(workflowID string, task string) error
-	CreateWorkflow(processID string) (string, error)
+	CreateWorkflow(processID string, businessKey string) (string, error)
 	FailedTask(workflowID string, task string) error
-	SubscribeTask(processID string, task string, healthCheckURL string, callbackURL string) (string, error)
-	SubscriberHealthCheck(callbackURL string) error
+	HeartbeatTask(workflowID string, task string) (time.Time, error)
+	FetchAndLockJobs(workerID string, topics []entitites.FetchTopic, maxJobs int, lockDuration time.Duration, timeout time.Duration) ([]entitites.LockedJob, error)
+	CompleteJob(jobID string) error
+	FailJob(jobID string) error
+	ExtendJobLock(jobID string, lockDuration time.Duration) (time.Time, error)
+	OpenJobStream(workerID string, maxJobs int, lockDuration time.Duration) (*JobStream, error)
+	GetWorkflow(workflowID string) (*entitites.Workflow, error)
+	ListWorkflowEvents(query entitites.WorkflowEventQuery) ([]entitites.WorkflowEvent, error)
+	LatestWorkflowEventID() (int64, error)
+	WatchWorkflowEvents(ctx context.Context, query entitites.WorkflowEventQuery, send func(event entitites.WorkflowEvent) error) error
+	SearchWorkflows(query entitites.WorkflowQuery) (*entitites.WorkflowPage, error)
+	ListProcessConfigs() ([]entitites.ProcessConfig, error)
+	GetProcessConfig(processID string) (*entitites.ProcessConfig, error)
+	GetProcessConfigVersion(processID string, version int) (*entitites.ProcessConfig, error)
+	ListProcessConfigVersions(processID string) ([]entitites.ProcessConfig, error)
+	SetProcessEnabled(processID string, enabled bool) error
+	DeleteProcessConfig(processID string, version int) error
+	SetRetentionPolicy(policy entitites.RetentionPolicy) error
+	ListRetentionPolicies() ([]entitites.RetentionPolicy, error)
+	ListDeadLetters(processID string, task string) ([]entitites.DeadLetter, error)
+	GetDeadLetter(processID string, task string, id string) (*entitites.DeadLetter, error)
+	ReplayDeadLetter(processID string, task string, id string) error
+	DeleteDeadLetter(processID string, task string, id string) error
+	PurgeDeadLetters(processID string, task string) (int, error)
+	ListQueues() ([]entitites.QueueStats, error)
+	GetQueueStats(processID string, task string) (*entitites.QueueStats, error)
+	ListQueueJobs(processID string, task string, limit int) ([]entitites.QueuedJob, error)
+	GetQueueJob(processID string, task string, id string) (*entitites.QueuedJob, error)
+	DeleteQueueJob(processID string, task string, id string) error
+	PurgeQueue(processID string, task string) (int, error)
+	RequeueQueueJobs(processID string, task string, ids []string) (int, error)
+	MoveQueueJobs(processID string, task string, toProcessID string, toTask string, ids []string) (int, error)
+	SubscribeTask(processID string, task string, healthCheckURL string, callbackURL string, healthCheck entitites.HealthCheckConfig) (sessionKey string, signingSecret string, err error)
+	SubscriberHealthCheck(healthCheckURL string, healthCheck entitites.HealthCheckConfig) error
+	ListCircuitBreakers() []entitites.CircuitBreakerStatus
+	ListSubscriptionStatuses() []entitites.SubscriptionStatus
+	ListSubscriptions() ([]entitites.SubscriptionStatus, error)
+	UnsubscribeTask(sessionKey string) error
+	RenewSubscription(sessionKey string) (entitites.SubscriptionStatus, error)
+	RunCluster(ctx context.Context) error
+	ListClusterInstances() ([]entitites.CoreInstance, error)
 }
 
-func NewNoNoodleWorkflowCorePostgresql(repo *repository.PostgreSQLNoNoodleWorkflow, pubsub *RedisMessageService) NoNoodleCoreInterface {
+// NewNoNoodleWorkflowCorePostgresql joins the cluster of instances sharing
+// repo and starts the subscriptions this instance owns. RunCluster keeps the
+// membership alive.
+func NewNoNoodleWorkflowCorePostgresql(repo repository.NoNoodleWorkflowRepository, pubsub *MessageService, cluster ClusterPolicy) NoNoodleCoreInterface {
 
 	noNoodleCore := &NoNoodleWorkflowCorePostgresql{
-		httpClient: &http.Client{},
-		repo:       repo,
-		pubsub:     pubsub,
+		httpClient:     &http.Client{},
+		repo:           repo,
+		pubsub:         pubsub,
+		workflowEvents: newWorkflowEventHub(),
 	}
+	noNoodleCore.subscribers = newSubscriberSupervisor(noNoodleCore)
+	noNoodleCore.cluster = newClusterMember(noNoodleCore, cluster)
 
-	err := noNoodleCore.ReSubscribeTask()
-	if err != nil {
-		// Handle the error appropriately, e.g., log it or return it
+	if err := noNoodleCore.cluster.heartbeat(); err != nil {
+		fmt.Println("Error joining the cluster:", err)
+	}
+	if _, err := noNoodleCore.cluster.rebalance(); err != nil {
 		fmt.Println("Error re-subscribing tasks:", err)
 	}
 
@@ -55,6 +129,13 @@ func NewNoNoodleWorkflowCorePostgresql(repo *repository.PostgreSQLNoNoodleWorkfl
 
 func (c *NoNoodleWorkflowCorePostgresql) DeployProcessConfig(processConfig *entitites.ProcessConfig) error {
 
+	if err := validateProcessID(processConfig.ProcessID); err != nil {
+		return err
+	}
+	if err := validateTaskLease(processConfig.MapTaskLeaseSeconds); err != nil {
+		return err
+	}
+
 	// Implement the logic to complete a task in the workflow using the repository
 	tx, err := c.repo.GetDB().Begin()
 	if err != nil {
@@ -68,7 +149,7 @@ func (c *NoNoodleWorkflowCorePostgresql) DeployProcessConfig(processConfig *enti
 		}
 	}()
 
-	// Implement the logic to deploy a process configuration using the repository
+	// Deploying always adds a new version, the assigned one is written back into processConfig
 	err = c.repo.InsertProcessConfig(tx, processConfig)
 	if err != nil {
 		return err
@@ -77,16 +158,31 @@ func (c *NoNoodleWorkflowCorePostgresql) DeployProcessConfig(processConfig *enti
 }
 
 func (c *NoNoodleWorkflowCorePostgresql) CompleteTask(workflowID string, task string) error {
+
+	taskInstance, _ := c.getTaskInstance(workflowID, task)
+
+	err := c.completeTask(workflowID, task)
+	if err != nil {
+		return err
+	}
+
+	c.ackTaskJob(taskInstance)
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) completeTask(workflowID string, task string) error {
 	// Implement the logic to complete a task in the workflow using the repository
 	tx, err := c.repo.GetDB().Begin()
 	if err != nil {
 		return err
 	}
+	var pending outbox
 	defer func() {
 		if err != nil {
 			tx.Rollback()
-		} else {
-			tx.Commit()
+		} else if tx.Commit() == nil {
+			c.pubsub.SendPending(context.Background(), &pending)
+			c.workflowEvents.broadcast()
 		}
 	}()
 
@@ -100,7 +196,12 @@ func (c *NoNoodleWorkflowCorePostgresql) CompleteTask(workflowID string, task st
 		return err
 	}
 
-	processConfig, err := c.repo.GetProcessConfigByProcessID(tx, workflow.ProcessID)
+	err = c.recordWorkflowEvent(tx, workflowID, workflow.ProcessID, entitites.WORKFLOW_EVENT_TASK_COMPLETED, "", task)
+	if err != nil {
+		return err
+	}
+
+	processConfig, err := c.repo.GetProcessConfigByVersion(tx, workflow.ProcessID, workflow.ProcessVersion)
 	if err != nil {
 		return err
 	}
@@ -113,7 +214,7 @@ func (c *NoNoodleWorkflowCorePostgresql) CompleteTask(workflowID string, task st
 		}
 		needPublish := false
 		for _, validTask := range taskToValidate {
-			if workflow.TaskStatus[validTask].Status == "completed" {
+			if workflow.TaskStatus[validTask].Status == TASK_STATUS_COMPLETED {
 				needPublish = true
 			} else {
 				needPublish = false
@@ -126,8 +227,12 @@ func (c *NoNoodleWorkflowCorePostgresql) CompleteTask(workflowID string, task st
 	}
 
 	for _, stage := range stageToPublish {
+		err = c.recordWorkflowEvent(tx, workflowID, workflow.ProcessID, entitites.WORKFLOW_EVENT_STAGE_PUBLISHED, stage, "")
+		if err != nil {
+			return err
+		}
 		for _, stageTask := range processConfig.MapStageTask[stage] {
-			err = c.publishTaskToBroker(tx, workflow.ProcessID, workflowID, stageTask)
+			err = c.publishTaskToBroker(tx, &pending, workflow.ProcessID, workflowID, stageTask)
 			if err != nil {
 				return err
 			}
@@ -139,10 +244,33 @@ func (c *NoNoodleWorkflowCorePostgresql) CompleteTask(workflowID string, task st
 		}
 	}
 
+	if len(stageToPublish) == 0 && isAllTaskCompleted(workflow) {
+		err = c.repo.UpdateWorkflowStatus(tx, workflowID, WORKFLOW_STATUS_COMPLETED, util.GetCurrentTime())
+		if err != nil {
+			return err
+		}
+		// Completing the last task again finds the workflow already completed
+		if workflow.Status != WORKFLOW_STATUS_COMPLETED {
+			err = c.recordWorkflowEvent(tx, workflowID, workflow.ProcessID, entitites.WORKFLOW_EVENT_WORKFLOW_COMPLETED, "", "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
-func (c *NoNoodleWorkflowCorePostgresql) CreateWorkflow(processID string) (string, error) {
+func isAllTaskCompleted(workflow *entitites.Workflow) bool {
+	for _, taskStatus := range workflow.TaskStatus {
+		if taskStatus.Status != TASK_STATUS_COMPLETED {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) CreateWorkflow(processID string, businessKey string) (string, error) {
 	// Implement the logic to create a new workflow using the repository
 
 	workflowID := generateWorkflowID()
@@ -151,16 +279,26 @@ func (c *NoNoodleWorkflowCorePostgresql) CreateWorkflow(processID string) (strin
 	if err != nil {
 		return "", err
 	}
+	var pending outbox
 	defer func() {
 		if err != nil {
 			tx.Rollback()
-		} else {
-			tx.Commit()
+		} else if tx.Commit() == nil {
+			c.pubsub.SendPending(context.Background(), &pending)
+			c.workflowEvents.broadcast()
 		}
 	}()
 
 	processConfig, err := c.repo.GetProcessConfigByProcessID(tx, processID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("%w: %s", ErrProcessNotFound, processID)
+		}
+		return "", err
+	}
+
+	if !processConfig.Enabled {
+		err = fmt.Errorf("%w: %s", ErrProcessDisabled, processID)
 		return "", err
 	}
 
@@ -174,32 +312,50 @@ func (c *NoNoodleWorkflowCorePostgresql) CreateWorkflow(processID string) (strin
 		}
 	}
 
-	for _, task := range processConfig.MapStageTask["start"] {
-		taskData[task] = entitites.TaskStatusData{
-			Status:     TASK_STATUS_IN_ACTIVE,
-			UpdateDate: util.GetCurrentTime(),
-		}
-
-		publishTaskToBrokerErr := c.publishTaskToBroker(tx, processID, workflowID, task)
-		if publishTaskToBrokerErr != nil {
-			return "", publishTaskToBrokerErr
-		}
-	}
-
 	publishedStage := make(map[string]bool)
 	for stage := range processConfig.MapStageReady {
 		publishedStage[stage] = false
 	}
 
-	err = c.repo.InitializeWorkflow(tx, workflowID, processID, taskData, publishedStage)
+	err = c.repo.InitializeWorkflow(tx, workflowID, processID, processConfig.Version, businessKey, taskData, publishedStage)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.recordWorkflowEvent(tx, workflowID, processID, entitites.WORKFLOW_EVENT_CREATED, "", "")
+	if err != nil {
+		return "", err
+	}
+	err = c.recordWorkflowEvent(tx, workflowID, processID, entitites.WORKFLOW_EVENT_STAGE_PUBLISHED, "start", "")
 	if err != nil {
 		return "", err
 	}
 
+	// Start tasks are activated by publishing them, which also counts their first attempt
+	for _, task := range processConfig.MapStageTask["start"] {
+		err = c.publishTaskToBroker(tx, &pending, processID, workflowID, task)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	return workflowID, nil
 }
 
 func (c *NoNoodleWorkflowCorePostgresql) FailedTask(workflowID string, task string) error {
+
+	taskInstance, _ := c.getTaskInstance(workflowID, task)
+
+	err := c.failedTask(workflowID, task)
+	if err != nil {
+		return err
+	}
+
+	c.ackTaskJob(taskInstance)
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) failedTask(workflowID string, task string) error {
 	// Implement the logic to complete a task in the workflow using the repository
 	tx, err := c.repo.GetDB().Begin()
 	if err != nil {
@@ -210,6 +366,7 @@ func (c *NoNoodleWorkflowCorePostgresql) FailedTask(workflowID string, task stri
 			tx.Rollback()
 		} else {
 			tx.Commit()
+			c.workflowEvents.broadcast()
 		}
 	}()
 
@@ -218,18 +375,61 @@ func (c *NoNoodleWorkflowCorePostgresql) FailedTask(workflowID string, task stri
 		return err
 	}
 
+	workflow, err := c.repo.GetWorkflowByWorkflowID(tx, workflowID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
+		}
+		return err
+	}
+
+	err = c.repo.UpdateWorkflowStatus(tx, workflowID, WORKFLOW_STATUS_FAILED, util.GetCurrentTime())
+	if err != nil {
+		return err
+	}
+
+	err = c.recordWorkflowEvent(tx, workflowID, workflow.ProcessID, entitites.WORKFLOW_EVENT_TASK_FAILED, "", task)
+	if err != nil {
+		return err
+	}
+	if workflow.Status != WORKFLOW_STATUS_FAILED {
+		err = c.recordWorkflowEvent(tx, workflowID, workflow.ProcessID, entitites.WORKFLOW_EVENT_WORKFLOW_FAILED, "", "")
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (c *NoNoodleWorkflowCorePostgresql) publishTaskToBroker(tx *sql.Tx, processID string, workflowID string, stageTask string) error {
+func (c *NoNoodleWorkflowCorePostgresql) GetWorkflow(workflowID string) (*entitites.Workflow, error) {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	type PublishedPayload struct {
-		ProcessID  string `json:"process_id"`
-		TaskID     string `json:"task_id"`
-		WorkflowID string `json:"workflow_id"`
+	workflow, err := c.repo.GetWorkflowByWorkflowID(tx, workflowID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkflowNotFound
+		}
+		return nil, err
 	}
 
-	payload := PublishedPayload{
+	return workflow, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) SearchWorkflows(query entitites.WorkflowQuery) (*entitites.WorkflowPage, error) {
+	return c.repo.SearchWorkflows(query)
+}
+
+// publishTaskToBroker activates stageTask and sends its job, within tx or
+// kept in pending until tx has committed, see SendToMsgChannalTx.
+func (c *NoNoodleWorkflowCorePostgresql) publishTaskToBroker(tx *sql.Tx, pending *outbox, processID string, workflowID string, stageTask string) error {
+
+	payload := taskJob{
 		ProcessID:  processID,
 		TaskID:     stageTask,
 		WorkflowID: workflowID,
@@ -240,7 +440,7 @@ func (c *NoNoodleWorkflowCorePostgresql) publishTaskToBroker(tx *sql.Tx, process
 		return err
 	}
 
-	channal := "no_noodle_workflow:" + processID + ":" + stageTask
+	channal := taskChannal(processID, stageTask)
 
 	fmt.Println("Publishing to channal:", channal, " payload:", string(jsonPayload))
 
@@ -249,12 +449,24 @@ func (c *NoNoodleWorkflowCorePostgresql) publishTaskToBroker(tx *sql.Tx, process
 		return err
 	}
 
-	return c.pubsub.SendToMsgChannal(context.Background(), channal, jsonPayload)
+	err = c.recordWorkflowEvent(tx, workflowID, processID, entitites.WORKFLOW_EVENT_TASK_ACTIVE, "", stageTask)
+	if err != nil {
+		return err
+	}
+
+	return c.pubsub.SendToMsgChannalTx(context.Background(), tx, channal, jsonPayload, pending)
 }
 
-func (c *NoNoodleWorkflowCorePostgresql) SubscriberHealthCheck(callbackURL string) error {
+// SubscriberHealthCheck checks a subscriber once the way it asked to be
+// checked, before it is subscribed.
+func (c *NoNoodleWorkflowCorePostgresql) SubscriberHealthCheck(healthCheckURL string, healthCheck entitites.HealthCheckConfig) error {
 
-	err := c.websocketHealthCheck(callbackURL)
+	healthCheck, err := normalizeHealthCheck(healthCheck)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkSubscriberHealth(healthCheckURL, healthCheck)
 	if err != nil {
 		return fmt.Errorf("subscriber health check failed: %v", err)
 	}
@@ -262,111 +474,110 @@ func (c *NoNoodleWorkflowCorePostgresql) SubscriberHealthCheck(callbackURL strin
 	return nil
 }
 
-func (c *NoNoodleWorkflowCorePostgresql) subscribeChannel(sessionKey string, task string, processID string, healthCheckURL string, callbackURL string) (string, error) {
-
-	ctx, cancel := context.WithCancel(context.Background())
-	// Periodic health check, stops on context cancellation or failure
-	go func() {
-		healthCheckMaxFailures := 10
-		healthCheckFailures := 0
-		healthCheckInterval := 5 * time.Second
-		ticker := time.NewTicker(healthCheckInterval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				fmt.Printf("Stopping health checks for subscriber with session key %s: %v\n", sessionKey, ctx.Err())
-				return
-			case <-ticker.C:
-				go func() {
-					err := c.websocketHealthCheck(healthCheckURL)
-					if err != nil {
-						healthCheckFailures++
-						fmt.Println("Healtch check failed for callback URL:", callbackURL, " error:", err, " failure count:", healthCheckFailures)
-						if healthCheckFailures >= healthCheckMaxFailures {
-							fmt.Printf("Health check failed at max retries %d for callback URL %s: %v\n", healthCheckMaxFailures, callbackURL, err)
-							if removeErr := c.repo.RemoveSubscriber(sessionKey); removeErr != nil {
-								fmt.Printf("Failed to remove subscriber with session key %s: %v\n", sessionKey, removeErr)
-							}
-							cancel()
-							return
-						}
-					} else {
-						healthCheckFailures = 0 // Reset failure count on successful health check
-					}
-				}()
-				go func() {
-					subscriber, err := c.repo.GetSubscriberBySessionKey(sessionKey)
-					if subscriber == nil {
-						fmt.Printf("Subscriber with session key %s not found: %v\n", sessionKey, "Do Eject Subscriber")
-						cancel()
-						return
-					}
-					if err != nil {
-						fmt.Printf("Error retrieving subscriber with session key %s: %v\n", sessionKey, err)
-						cancel()
-						return
-					}
-				}()
-			}
-		}
-	}()
+// SubscribeTask registers the worker at callbackURL for the jobs of a process
+// task, checked as healthCheck asks. Every delivery is signed with the
+// returned signing secret.
+func (c *NoNoodleWorkflowCorePostgresql) SubscribeTask(processID string, task string, healthCheckURL string, callbackURL string, healthCheck entitites.HealthCheckConfig) (string, string, error) {
 
-	channel := "no_noodle_workflow:" + processID + ":" + task
+	// channal := "no_noodle_workflow:" + processID + ":" + task
 
-	go c.pubsub.SubscribeChannal(ctx, callbackURL, channel, c.websocketNotify)
+	healthCheck, err := normalizeHealthCheck(healthCheck)
+	if err != nil {
+		return "", "", err
+	}
 
-	return sessionKey, nil
-}
+	sessionKey := generateSessionKey()
+	if sessionKey == "" {
+		return "", "", fmt.Errorf("failed to generate session key")
+	}
 
-func (c *NoNoodleWorkflowCorePostgresql) SubscribeTask(processID string, task string, healthCheckURL string, callbackURL string) (string, error) {
+	signingSecret, err := generateSigningSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate signing secret: %w", err)
+	}
 
-	// channal := "no_noodle_workflow:" + processID + ":" + task
+	subscriber := entitites.SubscriberRegistry{
+		SessionKey:     sessionKey,
+		ProcessID:      processID,
+		Task:           task,
+		HealthCheckURL: healthCheckURL,
+		CallbackURL:    callbackURL,
+		SigningSecret:  signingSecret,
+		HealthCheck:    healthCheck,
+	}
 
-	sessionKey := generateSessionKey()
-	if sessionKey == "" {
-		return "", fmt.Errorf("failed to generate session key")
+	// Register subscriber before starting background goroutines. A worker
+	// subscribing again, e.g. after a restart, takes over its old session
+	// with the new secret and health check
+	takenOver, err := c.repo.SaveSubscriber(&subscriber)
+	if err != nil {
+		return "", "", err
 	}
 
-	// Register subscriber before starting background goroutines
-	if err := c.repo.SaveSubscriber(sessionKey, healthCheckURL, task, processID, callbackURL); err != nil {
-		return "", err
+	stored, err := c.repo.GetSubscriberBySessionKey(subscriber.SessionKey)
+	if err != nil {
+		return "", "", err
+	}
+	if stored == nil {
+		return "", "", fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscriber.SessionKey)
 	}
 
-	return c.subscribeChannel(sessionKey, task, processID, healthCheckURL, callbackURL)
+	// The instance owning a subscription taken over restarts it with the new
+	// settings, with its next heartbeat unless that is this one
+	if takenOver {
+		fmt.Printf("Subscriber with session key %s taken over by a re-subscribe\n", stored.SessionKey)
+	}
+	if err := c.cluster.adopt(*stored); err != nil {
+		return "", "", err
+	}
+	return stored.SessionKey, signingSecret, nil
 }
 
-func (c *NoNoodleWorkflowCorePostgresql) websocketNotify(callbackURL string, payload []byte) error {
+// deliverTask records the job reserved from channal and its lease on the task,
+// pushes the job to the subscriber and records which worker took it. The push
+// is given up when ctx is cancelled.
+func (c *NoNoodleWorkflowCorePostgresql) deliverTask(ctx context.Context, channal string, callbackURL string, signingSecret string, envelope *msgbroker.Envelope, leaseExpireDate time.Time) error {
 
-	req, err := http.NewRequest("POST", callbackURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return err
+	var job taskJob
+	if err := json.Unmarshal(envelope.Payload, &job); err != nil {
+		fmt.Println("Error decoding delivered payload:", err)
+		return c.websocketNotify(ctx, callbackURL, signingSecret, envelope.Payload)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	// Recorded before the worker can heartbeat or complete the task
+	if !c.leaseTask(job.WorkflowID, job.TaskID, envelope.ID, leaseExpireDate) {
+		// The task finished while its job was queued, e.g. it was redelivered
+		// after the lease expired, there is nothing left to deliver
+		return c.pubsub.Ack(context.Background(), channal, envelope.ID)
+	}
 
-	resp, err := c.httpClient.Do(req)
+	err := c.websocketNotify(ctx, callbackURL, signingSecret, envelope.Payload)
 	if err != nil {
 		return err
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to notify subscriber, status code: %d", resp.StatusCode)
+	if err := c.repo.UpdateTaskWorker(job.WorkflowID, job.TaskID, callbackURL); err != nil {
+		fmt.Printf("Failed to record worker for workflow %s task %s: %v\n", job.WorkflowID, job.TaskID, err)
 	}
 
 	return nil
 }
 
-func (c *NoNoodleWorkflowCorePostgresql) websocketHealthCheck(callbackURL string) error {
+// websocketNotify posts payload to the subscriber, waiting for its answer no
+// longer than the delivery timeout.
+func (c *NoNoodleWorkflowCorePostgresql) websocketNotify(ctx context.Context, callbackURL string, signingSecret string, payload []byte) error {
 
-	req, err := http.NewRequest("GET", callbackURL, nil)
+	ctx, cancel := context.WithTimeout(ctx, c.pubsub.deliveryPolicy.deliveryTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", callbackURL, bytes.NewBuffer(payload))
 	if err != nil {
 		return err
 	}
 
+	req.Header.Set("Content-Type", "application/json")
+	signCallback(req, signingSecret, payload)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
@@ -375,33 +586,7 @@ func (c *NoNoodleWorkflowCorePostgresql) websocketHealthCheck(callbackURL string
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("subscriber health check failed, status code: %d", resp.StatusCode)
-	}
-
-	return nil
-}
-
-func (c *NoNoodleWorkflowCorePostgresql) ReSubscribeTask() error {
-
-	allSubscribers, err := c.repo.GetAllSubscribers()
-	if err != nil {
-		return fmt.Errorf("failed to get all channel infos: %v", err)
-	}
-
-	// Process all live channel infos
-	for _, subscriber := range *allSubscribers {
-		// Implement your logic to re-subscribe tasks based on channelInfo
-		_, err := c.subscribeChannel(
-			subscriber.SessionKey,
-			subscriber.Task,
-			subscriber.ProcessID,
-			subscriber.HealthCheckURL,
-			subscriber.CallbackURL,
-		)
-		if err != nil {
-			fmt.Printf("Failed to re-subscribe to task %s with session key %s: %v\n", subscriber.Task, subscriber.SessionKey, err)
-			panic(err)
-		}
+		return newCallbackError(resp)
 	}
 
 	return nil
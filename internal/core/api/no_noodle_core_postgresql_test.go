@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// slowEnqueueBroker takes a while to confirm an enqueue, the way a remote
+// broker does, while the enqueued message can already be reserved.
+type slowEnqueueBroker struct {
+	msgbroker.MessageBroker
+}
+
+func (b slowEnqueueBroker) Enqueue(ctx context.Context, queue string, message []byte) error {
+	err := b.MessageBroker.Enqueue(ctx, queue, message)
+	time.Sleep(200 * time.Millisecond)
+	return err
+}
+
+// TestCreateWorkflowEnqueuesAfterCommit has a consumer waiting for the start
+// task, the job it reserves must stand for a task it can already see active.
+func TestCreateWorkflowEnqueuesAfterCommit(t *testing.T) {
+	broker := msgbroker.NewMemoryMessageBroker()
+	core, _, path := newCoreOn(t, slowEnqueueBroker{broker}, api.DeliveryPolicy{})
+
+	err := core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "cp",
+		MapStageTask:  map[string][]string{"start": {"a"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second connection only sees what is committed
+	reader, err := util.NewSQLite(path, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status := make(chan string, 1)
+	go func() {
+		envelope, err := broker.Reserve(ctx, "no_noodle_workflow:cp:a", time.Minute)
+		if err != nil {
+			status <- err.Error()
+			return
+		}
+		var job struct {
+			WorkflowID string `json:"workflow_id"`
+		}
+		if err := json.Unmarshal(envelope.Payload, &job); err != nil {
+			status <- err.Error()
+			return
+		}
+		var taskStatus string
+		err = reader.QueryRow("SELECT status FROM task_instance WHERE workflow_id = ? AND task = ?", job.WorkflowID, "a").Scan(&taskStatus)
+		if err != nil {
+			status <- err.Error()
+			return
+		}
+		status <- taskStatus
+	}()
+	// Let the consumer block in Reserve
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := core.CreateWorkflow("cp", "bk"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := <-status; got != entitites.TASK_STATUS_IN_ACTIVE {
+		t.Fatalf("consumer saw task a as %q, want %q", got, entitites.TASK_STATUS_IN_ACTIVE)
+	}
+}
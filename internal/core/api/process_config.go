@@ -0,0 +1,190 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// processIDPattern keeps process IDs usable as a file name, the archive of a
+// process is written to a directory named after it.
+var processIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]{0,254}$`)
+
+func validateProcessID(processID string) error {
+
+	if !processIDPattern.MatchString(processID) {
+		return fmt.Errorf("%w: %q must be at most 255 letters, digits, '_', '-' or '.' and not start with '.'", ErrInvalidProcessID, processID)
+	}
+
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) ListProcessConfigs() ([]entitites.ProcessConfig, error) {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return c.repo.ListProcessConfigs(tx)
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) GetProcessConfig(processID string) (*entitites.ProcessConfig, error) {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	processConfig, err := c.repo.GetProcessConfigByProcessID(tx, processID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrProcessConfigNotFound, processID)
+		}
+		return nil, err
+	}
+
+	return &processConfig, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) GetProcessConfigVersion(processID string, version int) (*entitites.ProcessConfig, error) {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	processConfig, err := c.repo.GetProcessConfigByVersion(tx, processID, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s version %d", ErrProcessConfigNotFound, processID, version)
+		}
+		return nil, err
+	}
+
+	return &processConfig, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) ListProcessConfigVersions(processID string) ([]entitites.ProcessConfig, error) {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	processConfigs, err := c.repo.ListProcessConfigVersions(tx, processID)
+	if err != nil {
+		return nil, err
+	}
+	if len(processConfigs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrProcessConfigNotFound, processID)
+	}
+
+	return processConfigs, nil
+}
+
+// SetProcessEnabled enables or disables every version of a process. Disabled
+// processes keep their workflows and history but reject CreateWorkflow.
+func (c *NoNoodleWorkflowCorePostgresql) SetProcessEnabled(processID string, enabled bool) error {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	found, err := c.repo.SetProcessEnabled(tx, processID, enabled)
+	if err != nil {
+		return err
+	}
+	if !found {
+		err = fmt.Errorf("%w: %s", ErrProcessNotFound, processID)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteProcessConfig removes one version of a process config, refusing while
+// any workflow still runs on or was created from that version.
+func (c *NoNoodleWorkflowCorePostgresql) DeleteProcessConfig(processID string, version int) error {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	workflowCount, err := c.repo.CountWorkflowsByProcessVersion(tx, processID, version)
+	if err != nil {
+		return err
+	}
+	if workflowCount > 0 {
+		err = fmt.Errorf("%w: %s version %d has %d workflows", ErrProcessConfigIsInUse, processID, version, workflowCount)
+		return err
+	}
+
+	found, err := c.repo.DeleteProcessConfig(tx, processID, version)
+	if err != nil {
+		return err
+	}
+	if !found {
+		err = fmt.Errorf("%w: %s version %d", ErrProcessConfigNotFound, processID, version)
+		return err
+	}
+
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) SetRetentionPolicy(policy entitites.RetentionPolicy) error {
+
+	if policy.CompletedRetentionDays < 0 || policy.FailedRetentionDays < 0 {
+		return fmt.Errorf("%w: retention days must not be negative", ErrInvalidRetentionPolicy)
+	}
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	found, err := c.repo.SetRetentionPolicy(tx, policy)
+	if err != nil {
+		return err
+	}
+	if !found {
+		err = fmt.Errorf("%w: %s", ErrProcessNotFound, policy.ProcessID)
+		return err
+	}
+
+	return nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) ListRetentionPolicies() ([]entitites.RetentionPolicy, error) {
+	return c.repo.ListRetentionPolicies()
+}
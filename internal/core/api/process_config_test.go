@@ -0,0 +1,48 @@
+package api_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func TestDeployProcessConfigValidatesProcessID(t *testing.T) {
+	core, _ := newCore(t)
+
+	tests := []struct {
+		processID string
+		valid     bool
+	}{
+		{"order", true},
+		{"order-process_v1.2", true},
+		{"_internal", true},
+		{strings.Repeat("p", 255), true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../../etc", false},
+		{".hidden", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"a:b", false},
+		{"a b", false},
+		{strings.Repeat("p", 256), false},
+	}
+
+	for _, tt := range tests {
+		err := core.DeployProcessConfig(&entitites.ProcessConfig{
+			ProcessID:     tt.processID,
+			MapStageTask:  map[string][]string{"start": {"a"}},
+			MapStageReady: map[string][]string{},
+		})
+		if tt.valid && err != nil {
+			t.Errorf("deploying %q: %v", tt.processID, err)
+		}
+		if !tt.valid && !errors.Is(err, api.ErrInvalidProcessID) {
+			t.Errorf("deploying %q: got error %v, want %v", tt.processID, err, api.ErrInvalidProcessID)
+		}
+	}
+}
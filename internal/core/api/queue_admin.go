@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+func toQueueStats(processID string, task string, stats msgbroker.QueueStats) entitites.QueueStats {
+
+	queueStats := entitites.QueueStats{
+		ProcessID:         processID,
+		Task:              task,
+		Queue:             taskChannal(processID, task),
+		Ready:             stats.Ready,
+		Delayed:           stats.Delayed,
+		Reserved:          stats.Reserved,
+		InFlight:          stats.InFlight,
+		Dead:              stats.Dead,
+		OldestEnqueueDate: stats.OldestEnqueuedAt,
+	}
+	if stats.OldestEnqueuedAt != nil {
+		queueStats.OldestAgeSeconds = max(time.Since(*stats.OldestEnqueuedAt).Seconds(), 0)
+	}
+
+	return queueStats
+}
+
+func toQueuedJob(processID string, task string, message msgbroker.QueuedMessage) entitites.QueuedJob {
+
+	return entitites.QueuedJob{
+		ID:          message.ID,
+		ProcessID:   processID,
+		Task:        task,
+		State:       message.State,
+		Payload:     jsonPayload(message.Payload),
+		Attempts:    message.Attempts,
+		EnqueueDate: message.EnqueuedAt,
+		VisibleDate: message.VisibleAt,
+	}
+}
+
+// processTasks returns every task of processConfig once, sorted.
+func processTasks(processConfig entitites.ProcessConfig) []string {
+
+	tasks := []string{}
+	seen := map[string]bool{}
+	for _, stageTasks := range processConfig.MapStageTask {
+		for _, task := range stageTasks {
+			if !seen[task] {
+				seen[task] = true
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	sort.Strings(tasks)
+
+	return tasks
+}
+
+// ListQueues returns the queue of every task of the latest version of every
+// process.
+func (c *NoNoodleWorkflowCorePostgresql) ListQueues() ([]entitites.QueueStats, error) {
+
+	processConfigs, err := c.ListProcessConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []entitites.QueueStats{}
+	for _, processConfig := range processConfigs {
+		for _, task := range processTasks(processConfig) {
+			stats, err := c.pubsub.QueueStats(context.Background(), taskChannal(processConfig.ProcessID, task))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, toQueueStats(processConfig.ProcessID, task, stats))
+		}
+	}
+
+	return result, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) GetQueueStats(processID string, task string) (*entitites.QueueStats, error) {
+
+	stats, err := c.pubsub.QueueStats(context.Background(), taskChannal(processID, task))
+	if err != nil {
+		return nil, err
+	}
+
+	result := toQueueStats(processID, task, stats)
+	return &result, nil
+}
+
+// ListQueueJobs returns up to limit jobs of the queue, limit <= 0 returns all.
+func (c *NoNoodleWorkflowCorePostgresql) ListQueueJobs(processID string, task string, limit int) ([]entitites.QueuedJob, error) {
+
+	messages, err := c.pubsub.ListMessages(context.Background(), taskChannal(processID, task), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]entitites.QueuedJob, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, toQueuedJob(processID, task, message))
+	}
+
+	return result, nil
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) GetQueueJob(processID string, task string, id string) (*entitites.QueuedJob, error) {
+
+	messages, err := c.pubsub.ListMessages(context.Background(), taskChannal(processID, task), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, message := range messages {
+		if message.ID == id {
+			result := toQueuedJob(processID, task, message)
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) DeleteQueueJob(processID string, task string, id string) error {
+
+	deleted, err := c.pubsub.DeleteMessage(context.Background(), taskChannal(processID, task), id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	return nil
+}
+
+// PurgeQueue removes every job of the queue except dead letters.
+func (c *NoNoodleWorkflowCorePostgresql) PurgeQueue(processID string, task string) (int, error) {
+	return c.pubsub.PurgeMessages(context.Background(), taskChannal(processID, task))
+}
+
+// RequeueQueueJobs makes reserved jobs available again without waiting for
+// their lease, all of them unless ids is set.
+func (c *NoNoodleWorkflowCorePostgresql) RequeueQueueJobs(processID string, task string, ids []string) (int, error) {
+	return c.pubsub.RequeueReserved(context.Background(), taskChannal(processID, task), ids)
+}
+
+// MoveQueueJobs moves ready and delayed jobs to the queue of another task of
+// the same process, all of them unless ids is set. A job belongs to a workflow
+// of its process, so moving it to another process is refused. The task of
+// each moved job is rewritten to the target task, so the job is leased, acked
+// and completed through the queue it now sits in.
+func (c *NoNoodleWorkflowCorePostgresql) MoveQueueJobs(processID string, task string, toProcessID string, toTask string, ids []string) (int, error) {
+
+	if toProcessID == "" || toTask == "" {
+		return 0, fmt.Errorf("%w: target process and task are required", ErrInvalidQueueMove)
+	}
+	if toProcessID != processID {
+		return 0, fmt.Errorf("%w: jobs of process %s cannot move to process %s", ErrInvalidQueueMove, processID, toProcessID)
+	}
+	if toTask == task {
+		return 0, fmt.Errorf("%w: source and target are the same queue", ErrInvalidQueueMove)
+	}
+
+	retarget := func(payload []byte) ([]byte, error) {
+		return retargetTaskJob(payload, toTask)
+	}
+	return c.pubsub.MoveMessages(context.Background(), taskChannal(processID, task), taskChannal(toProcessID, toTask), ids, retarget)
+}
+
+// retargetTaskJob rewrites the task of a job payload, leaving any other field
+// as it is.
+func retargetTaskJob(payload []byte, task string) ([]byte, error) {
+
+	var job map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, fmt.Errorf("%w: job payload is not a task job: %v", ErrInvalidQueueMove, err)
+	}
+
+	taskJSON, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	job["task_id"] = taskJSON
+
+	return json.Marshal(job)
+}
@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// newCore returns a core backed by SQLite and the memory broker, with the
+// database it runs on.
+func newCore(t *testing.T) (api.NoNoodleCoreInterface, *sql.DB) {
+	t.Helper()
+
+	core, db, _ := newCoreOn(t, msgbroker.NewMemoryMessageBroker(), api.DeliveryPolicy{})
+	return core, db
+}
+
+// newCoreOn returns a core backed by SQLite and broker delivering under
+// policy, with the database it runs on and the path of its file.
+func newCoreOn(t *testing.T, broker msgbroker.MessageBroker, policy api.DeliveryPolicy) (api.NoNoodleCoreInterface, *sql.DB, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "no_noodle.db")
+	db, err := util.NewSQLite(path, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repository.NewSQLiteNoNoodleWorkflow(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	core := api.NewNoNoodleWorkflowCorePostgresql(repo, api.NewMessageService(broker, 3, 20*time.Second, policy), api.ClusterPolicy{})
+
+	t.Cleanup(func() {
+		broker.Close()
+		db.Close()
+	})
+
+	return core, db, path
+}
+
+func wantQueueEmpty(t *testing.T, core api.NoNoodleCoreInterface, processID string, task string) {
+	t.Helper()
+
+	stats, err := core.GetQueueStats(processID, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Ready != 0 || stats.Delayed != 0 || stats.Reserved != 0 || stats.InFlight != 0 {
+		t.Fatalf("queue %s: got %+v, want it empty", stats.Queue, *stats)
+	}
+}
+
+func TestMoveQueueJobsThenComplete(t *testing.T) {
+	core, _ := newCore(t)
+
+	err := core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "mp",
+		MapStageTask:  map[string][]string{"start": {"a", "b"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflowID, err := core.CreateWorkflow("mp", "bk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Task b lost its job, the job of task a is moved over to stand in for it
+	if _, err := core.PurgeQueue("mp", "b"); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := core.MoveQueueJobs("mp", "a", "mp", "b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 1 {
+		t.Fatalf("moved %d jobs, want 1", moved)
+	}
+	wantQueueEmpty(t, core, "mp", "a")
+
+	jobs, err := core.FetchAndLockJobs("w", []entitites.FetchTopic{{ProcessID: "mp", Task: "b"}}, 1, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("fetched %d jobs, want 1", len(jobs))
+	}
+	if jobs[0].WorkflowID != workflowID || jobs[0].TaskID != "b" {
+		t.Fatalf("fetched job of workflow %s task %s, want workflow %s task b", jobs[0].WorkflowID, jobs[0].TaskID, workflowID)
+	}
+
+	if err := core.CompleteJob(jobs[0].JobID); err != nil {
+		t.Fatal(err)
+	}
+
+	workflow, err := core.GetWorkflow(workflowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := workflow.TaskStatus["b"].Status; status != api.TASK_STATUS_COMPLETED {
+		t.Fatalf("task b is %s, want %s", status, api.TASK_STATUS_COMPLETED)
+	}
+	if status := workflow.TaskStatus["a"].Status; status != api.TASK_STATUS_IN_ACTIVE {
+		t.Fatalf("task a is %s, want %s", status, api.TASK_STATUS_IN_ACTIVE)
+	}
+	// Acked from the queue it was reserved from
+	wantQueueEmpty(t, core, "mp", "b")
+}
+
+func TestMoveQueueJobsRefusesOtherProcess(t *testing.T) {
+	core, _ := newCore(t)
+
+	_, err := core.MoveQueueJobs("mp", "a", "other", "a", nil)
+	if !errors.Is(err, api.ErrInvalidQueueMove) {
+		t.Fatalf("got error %v, want %v", err, api.ErrInvalidQueueMove)
+	}
+}
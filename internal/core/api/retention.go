@@ -0,0 +1,260 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// RetentionJob periodically removes finished workflows that outlived the
+// retention policy of their process. Work is done in batches, each batch in
+// its own short transaction, so the sweep never holds long locks. Of the
+// instances sharing the database only the one holding the retention lease
+// sweeps, two sweeps would archive the same workflows twice.
+type RetentionJob struct {
+	repo       repository.NoNoodleWorkflowRepository
+	instanceID string
+	archiveDir string
+	interval   time.Duration
+	batchSize  int
+}
+
+// NewRetentionJob creates the retention job of the cluster instance
+// instanceID, see ClusterPolicy. An empty instanceID is generated.
+func NewRetentionJob(repo repository.NoNoodleWorkflowRepository, instanceID string, archiveDir string, interval time.Duration, batchSize int) *RetentionJob {
+	if instanceID == "" {
+		instanceID = generateInstanceID()
+	}
+	return &RetentionJob{
+		repo:       repo,
+		instanceID: instanceID,
+		archiveDir: archiveDir,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+func (j *RetentionJob) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				log.Println("error running retention job:", err)
+			}
+		}
+	}
+}
+
+// claimLease claims or renews the retention lease. It lasts two intervals, the
+// holder renews it on every run and before every batch, so another instance
+// only takes over once the holder missed a whole run.
+func (j *RetentionJob) claimLease() (bool, error) {
+	now := util.GetCurrentTime()
+	leader, err := j.repo.ClaimClusterLease(retentionLease, j.instanceID, now, now.Add(2*j.interval))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim retention lease: %w", err)
+	}
+	return leader, nil
+}
+
+// RunOnce sweeps every process once until no expired workflow is left or ctx
+// is cancelled, unless another instance holds the retention lease.
+func (j *RetentionJob) RunOnce(ctx context.Context) error {
+	leader, err := j.claimLease()
+	if err != nil || !leader {
+		return err
+	}
+
+	policies, err := j.repo.ListRetentionPolicies()
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		retentions := map[string]int{
+			WORKFLOW_STATUS_COMPLETED: policy.CompletedRetentionDays,
+			WORKFLOW_STATUS_FAILED:    policy.FailedRetentionDays,
+		}
+		for status, days := range retentions {
+			if days <= 0 {
+				continue
+			}
+			before := util.GetCurrentTime().AddDate(0, 0, -days)
+			removed, err := j.sweep(ctx, policy, status, before)
+			if removed > 0 {
+				fmt.Printf("Retention removed %d %s workflows of process %s\n", removed, status, policy.ProcessID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (j *RetentionJob) sweep(ctx context.Context, policy entitites.RetentionPolicy, status string, before time.Time) (int, error) {
+	removed := 0
+	for ctx.Err() == nil {
+		// A sweep outlasting the lease must not overlap with the next holder
+		leader, err := j.claimLease()
+		if err != nil || !leader {
+			return removed, err
+		}
+
+		workflowIDs, err := j.repo.GetExpiredWorkflowIDs(policy.ProcessID, status, before, j.batchSize)
+		if err != nil {
+			return removed, err
+		}
+		if len(workflowIDs) == 0 {
+			return removed, nil
+		}
+
+		if policy.Archive {
+			if err := j.archiveBatch(policy.ProcessID, workflowIDs); err != nil {
+				return removed, err
+			}
+		}
+
+		deleted, err := j.deleteBatch(workflowIDs)
+		if err != nil {
+			return removed, err
+		}
+		removed += deleted
+
+		if len(workflowIDs) < j.batchSize {
+			return removed, nil
+		}
+	}
+	return removed, nil
+}
+
+func (j *RetentionJob) deleteBatch(workflowIDs []string) (int, error) {
+	tx, err := j.repo.GetDB().Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	deleted, err := j.repo.DeleteWorkflows(tx, workflowIDs)
+	return deleted, err
+}
+
+// archiveDirName names the archive directory of a process. Process IDs are
+// validated on deploy, those deployed before are escaped so no process
+// writes outside archiveDir.
+func archiveDirName(processID string) string {
+	name := url.PathEscape(processID)
+	if name == "." || name == ".." {
+		name = strings.ReplaceAll(name, ".", "%2E")
+	}
+	return name
+}
+
+// archivedWorkflow is one line of an archive file, the workflow with its
+// event history in event ID order.
+type archivedWorkflow struct {
+	*entitites.Workflow
+	Events []entitites.WorkflowEvent `json:"events"`
+}
+
+// workflowEvents reads the whole event history of a workflow, the events go
+// with it when it is deleted.
+func (j *RetentionJob) workflowEvents(workflowID string) ([]entitites.WorkflowEvent, error) {
+	events := []entitites.WorkflowEvent{}
+	query := entitites.WorkflowEventQuery{WorkflowID: workflowID, Limit: maxWorkflowEventLimit}
+	for {
+		page, err := j.repo.ListWorkflowEvents(query)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page...)
+		if len(page) < query.Limit {
+			return events, nil
+		}
+		query.AfterEventID = page[len(page)-1].EventID
+	}
+}
+
+// archiveBatch exports the workflows, with their task and event history, as
+// one gzip compressed JSONL file under archiveDir/<process_id>/<date>/. The file is
+// synced and renamed into place before anything is deleted.
+func (j *RetentionJob) archiveBatch(processID string, workflowIDs []string) error {
+	// Read before the transaction starts, SQLite has a single connection
+	events := make(map[string][]entitites.WorkflowEvent, len(workflowIDs))
+	for _, workflowID := range workflowIDs {
+		workflowEvents, err := j.workflowEvents(workflowID)
+		if err != nil {
+			return err
+		}
+		events[workflowID] = workflowEvents
+	}
+
+	tx, err := j.repo.GetDB().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := util.GetCurrentTime()
+	dir := filepath.Join(j.archiveDir, archiveDirName(processID), now.Format("2006-01-02"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("workflows-%s-%s.jsonl.gz", now.Format("150405.000000000"), workflowIDs[0]))
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gzipWriter)
+	for _, workflowID := range workflowIDs {
+		workflow, err := j.repo.GetWorkflowByWorkflowID(tx, workflowID)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(archivedWorkflow{Workflow: workflow, Events: events[workflowID]}); err != nil {
+			return err
+		}
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
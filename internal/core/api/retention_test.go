@@ -0,0 +1,150 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// expiredWorkflow completes a workflow of process rp, which archives
+// workflows completed a day ago, and backdates it by two days.
+func expiredWorkflow(t *testing.T) (api.NoNoodleCoreInterface, repository.NoNoodleWorkflowRepository, string) {
+	t.Helper()
+
+	core, db := newCore(t)
+
+	err := core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "rp",
+		MapStageTask:  map[string][]string{"start": {"a"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflowID, err := core.CreateWorkflow("rp", "bk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := core.FetchAndLockJobs("w", []entitites.FetchTopic{{ProcessID: "rp", Task: "a"}}, 1, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("fetched %d jobs, want 1", len(jobs))
+	}
+	if err := core.CompleteJob(jobs[0].JobID); err != nil {
+		t.Fatal(err)
+	}
+
+	err = core.SetRetentionPolicy(entitites.RetentionPolicy{ProcessID: "rp", CompletedRetentionDays: 1, Archive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE workflow SET update_date = ? WHERE workflow_id = ?", util.GetCurrentTime().AddDate(0, 0, -2), workflowID); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := repository.NewSQLiteNoNoodleWorkflow(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return core, repo, workflowID
+}
+
+func TestRetentionArchivesWorkflowEvents(t *testing.T) {
+	core, repo, workflowID := expiredWorkflow(t)
+
+	events, err := repo.ListWorkflowEvents(entitites.WorkflowEventQuery{WorkflowID: workflowID, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 {
+		t.Fatal("workflow recorded no events")
+	}
+
+	archiveDir := t.TempDir()
+	if err := api.NewRetentionJob(repo, "core-1", archiveDir, time.Hour, 10).RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(archiveDir, "rp", "*", "*.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("got archive files %v, want one", paths)
+	}
+	file, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archived struct {
+		WorkflowID string                    `json:"workflow_id"`
+		Events     []entitites.WorkflowEvent `json:"events"`
+	}
+	if err := json.NewDecoder(gzipReader).Decode(&archived); err != nil {
+		t.Fatal(err)
+	}
+	if archived.WorkflowID != workflowID {
+		t.Fatalf("archived workflow %s, want %s", archived.WorkflowID, workflowID)
+	}
+	if len(archived.Events) != len(events) {
+		t.Fatalf("archived events %v, want %v", eventIDs(archived.Events), eventIDs(events))
+	}
+	for i, event := range archived.Events {
+		if event.EventID != events[i].EventID || event.Type != events[i].Type {
+			t.Fatalf("archived event %d is %+v, want %+v", i, event, events[i])
+		}
+	}
+
+	if _, err := core.GetWorkflow(workflowID); err == nil {
+		t.Fatal("workflow still exists after retention")
+	}
+}
+
+// TestRetentionSweepsOnOneInstance runs the retention jobs of two instances,
+// only the one holding the retention lease sweeps.
+func TestRetentionSweepsOnOneInstance(t *testing.T) {
+	core, repo, workflowID := expiredWorkflow(t)
+	ctx := context.Background()
+
+	first := api.NewRetentionJob(repo, "core-1", t.TempDir(), time.Hour, 10)
+	secondArchiveDir := t.TempDir()
+	second := api.NewRetentionJob(repo, "core-2", secondArchiveDir, time.Hour, 10)
+
+	// core-1 holds the retention lease, e.g. it swept first
+	if _, err := repo.ClaimClusterLease("retention", "core-1", util.GetCurrentTime(), util.GetCurrentTime().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.GetWorkflow(workflowID); err != nil {
+		t.Fatalf("the instance without the lease swept: %v", err)
+	}
+	if paths, _ := filepath.Glob(filepath.Join(secondArchiveDir, "*", "*", "*")); len(paths) != 0 {
+		t.Fatalf("the instance without the lease archived %v", paths)
+	}
+
+	if err := first.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.GetWorkflow(workflowID); err == nil {
+		t.Fatal("workflow still exists after retention")
+	}
+}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// subscriberSupervisor owns every subscription this instance consumes: its
+// consumer, its health checks, its circuit breaker and its cancellation. All
+// state shared with the status API sits behind the supervisor or subscription
+// mutex.
+type subscriberSupervisor struct {
+	core *NoNoodleWorkflowCorePostgresql
+
+	// lifecycle orders starting and stopping subscriptions, so a takeover
+	// never runs next to the subscription it replaces
+	lifecycle sync.Mutex
+
+	mu            sync.Mutex
+	subscriptions map[string]*supervisedSubscription
+}
+
+type supervisedSubscription struct {
+	subscriber entitites.SubscriberRegistry
+	instanceID string
+	breaker    *CircuitBreaker
+	startDate  time.Time
+	cancel     context.CancelFunc
+	// done is closed once the consumer and the health loop both returned
+	done chan struct{}
+
+	mu                  sync.Mutex
+	healthCheckFailures int
+	lastHealthCheckDate time.Time
+	lastHealthCheckErr  string
+	lastRenewDate       time.Time
+}
+
+func newSubscriberSupervisor(core *NoNoodleWorkflowCorePostgresql) *subscriberSupervisor {
+	return &subscriberSupervisor{
+		core:          core,
+		subscriptions: make(map[string]*supervisedSubscription),
+	}
+}
+
+// start consumes the jobs of subscriber and checks its health until it is
+// stopped or fails too many health checks in a row.
+func (s *subscriberSupervisor) start(subscriber entitites.SubscriberRegistry) error {
+
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+
+	return s.startLocked(subscriber)
+}
+
+func (s *subscriberSupervisor) startLocked(subscriber entitites.SubscriberRegistry) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[subscriber.SessionKey]; ok {
+		return fmt.Errorf("subscription %s is already running", subscriber.SessionKey)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription := &supervisedSubscription{
+		subscriber: subscriber,
+		instanceID: s.core.cluster.instanceID,
+		breaker:    s.core.pubsub.NewCircuitBreaker(),
+		startDate:  time.Now(),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	// Renewals before the start are not counted again
+	if subscriber.RenewDate != nil {
+		subscription.lastRenewDate = *subscriber.RenewDate
+	}
+	s.subscriptions[subscriber.SessionKey] = subscription
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// Stopping the consumer for any reason ends the health checks too
+		defer cancel()
+		s.consume(ctx, subscription)
+	}()
+	go func() {
+		defer wg.Done()
+		s.checkHealth(ctx, subscription)
+	}()
+	go func() {
+		wg.Wait()
+
+		s.mu.Lock()
+		if s.subscriptions[subscriber.SessionKey] == subscription {
+			delete(s.subscriptions, subscriber.SessionKey)
+		}
+		s.mu.Unlock()
+		close(subscription.done)
+	}()
+
+	return nil
+}
+
+// stop cancels a subscription and waits for it to wind down. Its stored
+// registration is left alone.
+func (s *subscriberSupervisor) stop(sessionKey string) error {
+
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+
+	return s.stopLocked(sessionKey)
+}
+
+func (s *subscriberSupervisor) stopLocked(sessionKey string) error {
+
+	s.mu.Lock()
+	subscription, ok := s.subscriptions[sessionKey]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, sessionKey)
+	}
+
+	subscription.cancel()
+	<-subscription.done
+	return nil
+}
+
+// restart replaces the running subscription of the session of subscriber, if
+// any, with subscriber.
+func (s *subscriberSupervisor) restart(subscriber entitites.SubscriberRegistry) error {
+
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+
+	if err := s.stopLocked(subscriber.SessionKey); err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+		return err
+	}
+	return s.startLocked(subscriber)
+}
+
+func (s *subscriberSupervisor) get(sessionKey string) (*supervisedSubscription, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.subscriptions[sessionKey]
+	return subscription, ok
+}
+
+func (s *subscriberSupervisor) consume(ctx context.Context, subscription *supervisedSubscription) {
+
+	subscriber := subscription.subscriber
+
+	channal := taskChannal(subscriber.ProcessID, subscriber.Task)
+	deliver := func(ctx context.Context, callbackURL string, envelope *msgbroker.Envelope, leaseExpireDate time.Time) error {
+		return s.core.deliverTask(ctx, channal, callbackURL, subscriber.SigningSecret, envelope, leaseExpireDate)
+	}
+
+	// The session key names the consumer for brokers that track ownership
+	s.core.pubsub.SubscribeChannal(
+		msgbroker.WithConsumer(ctx, subscriber.SessionKey),
+		subscriber.CallbackURL,
+		channal,
+		s.core.jobLease,
+		deliver,
+		subscription.breaker,
+	)
+
+	// Unsubscribed, taken over or moved to another instance, the consumer is
+	// not read under this name again until the subscription restarts. Jobs it
+	// still holds stay reserved until they are acked or expire
+	if err := s.core.pubsub.RemoveConsumer(context.Background(), channal, subscriber.SessionKey); err != nil {
+		fmt.Printf("Failed to remove consumer %s of %s: %v\n", subscriber.SessionKey, channal, err)
+	}
+}
+
+// checkHealth checks the subscriber on every interval, one check at a time.
+// Reaching the failure threshold removes the subscription for good.
+func (s *subscriberSupervisor) checkHealth(ctx context.Context, subscription *supervisedSubscription) {
+
+	subscriber := subscription.subscriber
+	ticker := time.NewTicker(time.Duration(subscriber.HealthCheck.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("Stopping health checks for subscriber with session key %s: %v\n", subscriber.SessionKey, ctx.Err())
+			return
+		case <-ticker.C:
+		}
+
+		err := s.core.checkSubscriberHealth(subscriber.HealthCheckURL, subscriber.HealthCheck)
+		failures := subscription.recordHealthCheck(err)
+		if err == nil {
+			continue
+		}
+
+		fmt.Println("Health check failed for callback URL:", subscriber.CallbackURL, " error:", err, " failure count:", failures)
+		if failures >= subscriber.HealthCheck.FailureThreshold {
+			fmt.Printf("Health check failed at max retries %d for callback URL %s: %v\n", subscriber.HealthCheck.FailureThreshold, subscriber.CallbackURL, err)
+			if err := s.core.repo.RemoveSubscriber(subscriber.SessionKey); err != nil {
+				fmt.Printf("Failed to remove subscriber with session key %s: %v\n", subscriber.SessionKey, err)
+			}
+			subscription.cancel()
+			return
+		}
+	}
+}
+
+// recordHealthCheck returns the failed health checks in a row after err.
+func (s *supervisedSubscription) recordHealthCheck(err error) int {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastHealthCheckDate = time.Now()
+	if err != nil {
+		s.healthCheckFailures++
+		s.lastHealthCheckErr = err.Error()
+	} else {
+		s.healthCheckFailures = 0
+		s.lastHealthCheckErr = ""
+	}
+	return s.healthCheckFailures
+}
+
+// recordRenewal counts a renewal by the worker at renewDate as a passed
+// health check, once.
+func (s *supervisedSubscription) recordRenewal(renewDate time.Time) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !renewDate.After(s.lastRenewDate) {
+		return
+	}
+	s.lastRenewDate = renewDate
+	s.healthCheckFailures = 0
+	s.lastHealthCheckErr = ""
+}
+
+func (s *supervisedSubscription) status() entitites.SubscriptionStatus {
+
+	status := storedSubscriptionStatus(s.subscriber)
+	status.Health = entitites.SUBSCRIPTION_HEALTH_HEALTHY
+	status.OwnerInstanceID = s.instanceID
+	circuitBreaker := s.circuitBreakerStatus()
+	status.CircuitBreaker = &circuitBreaker
+	startDate := s.startDate
+	status.StartDate = &startDate
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status.ConsecutiveHealthCheckFailures = s.healthCheckFailures
+	status.LastHealthCheckError = s.lastHealthCheckErr
+	if s.healthCheckFailures > 0 {
+		status.Health = entitites.SUBSCRIPTION_HEALTH_UNHEALTHY
+	}
+	if !s.lastHealthCheckDate.IsZero() {
+		lastHealthCheckDate := s.lastHealthCheckDate
+		status.LastHealthCheckDate = &lastHealthCheckDate
+	}
+	if !s.lastRenewDate.IsZero() {
+		lastRenewDate := s.lastRenewDate
+		status.RenewDate = &lastRenewDate
+	}
+	return status
+}
+
+// storedSubscriptionStatus is the status of a subscription nothing is known
+// about beyond its registration.
+func storedSubscriptionStatus(subscriber entitites.SubscriberRegistry) entitites.SubscriptionStatus {
+	return entitites.SubscriptionStatus{
+		SessionKey:     subscriber.SessionKey,
+		ProcessID:      subscriber.ProcessID,
+		Task:           subscriber.Task,
+		HealthCheckURL: subscriber.HealthCheckURL,
+		CallbackURL:    subscriber.CallbackURL,
+		HealthCheck:    subscriber.HealthCheck,
+		Health:         entitites.SUBSCRIPTION_HEALTH_UNKNOWN,
+		// The lease of the owner may have run out, the next heartbeat settles it
+		OwnerInstanceID: subscriber.OwnerInstanceID,
+		RenewDate:       subscriber.RenewDate,
+		CreateDate:      subscriber.CreateDate,
+	}
+}
+
+func (s *supervisedSubscription) circuitBreakerStatus() entitites.CircuitBreakerStatus {
+
+	status := s.breaker.status()
+	status.SessionKey = s.subscriber.SessionKey
+	status.ProcessID = s.subscriber.ProcessID
+	status.Task = s.subscriber.Task
+	status.CallbackURL = s.subscriber.CallbackURL
+	return status
+}
+
+// running returns the supervised subscriptions ordered by process, task and
+// session key.
+func (s *subscriberSupervisor) running() []*supervisedSubscription {
+
+	s.mu.Lock()
+	subscriptions := make([]*supervisedSubscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(subscriptions, func(a, b *supervisedSubscription) int {
+		return cmp.Or(
+			cmp.Compare(a.subscriber.ProcessID, b.subscriber.ProcessID),
+			cmp.Compare(a.subscriber.Task, b.subscriber.Task),
+			cmp.Compare(a.subscriber.SessionKey, b.subscriber.SessionKey),
+		)
+	})
+	return subscriptions
+}
+
+// ListSubscriptionStatuses returns the live state of every subscription this
+// instance supervises.
+func (c *NoNoodleWorkflowCorePostgresql) ListSubscriptionStatuses() []entitites.SubscriptionStatus {
+
+	subscriptions := c.subscribers.running()
+	statuses := make([]entitites.SubscriptionStatus, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		statuses = append(statuses, subscription.status())
+	}
+	return statuses
+}
+
+// ListCircuitBreakers returns the delivery state of every subscriber consumed
+// by this instance, ordered by process, task and session key.
+func (c *NoNoodleWorkflowCorePostgresql) ListCircuitBreakers() []entitites.CircuitBreakerStatus {
+
+	subscriptions := c.subscribers.running()
+	statuses := make([]entitites.CircuitBreakerStatus, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		statuses = append(statuses, subscription.circuitBreakerStatus())
+	}
+	return statuses
+}
+
+// ListSubscriptions returns every stored subscription ordered by process, task
+// and session key, with the instance owning it and the live state of those
+// this instance supervises.
+func (c *NoNoodleWorkflowCorePostgresql) ListSubscriptions() ([]entitites.SubscriptionStatus, error) {
+
+	subscribers, err := c.repo.GetAllSubscribers()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]entitites.SubscriptionStatus, 0, len(*subscribers))
+	for _, subscriber := range *subscribers {
+		if subscription, ok := c.subscribers.get(subscriber.SessionKey); ok {
+			statuses = append(statuses, subscription.status())
+			continue
+		}
+		statuses = append(statuses, storedSubscriptionStatus(subscriber))
+	}
+
+	slices.SortFunc(statuses, func(a, b entitites.SubscriptionStatus) int {
+		return cmp.Or(
+			cmp.Compare(a.ProcessID, b.ProcessID),
+			cmp.Compare(a.Task, b.Task),
+			cmp.Compare(a.SessionKey, b.SessionKey),
+		)
+	})
+	return statuses, nil
+}
+
+// UnsubscribeTask removes the subscription of sessionKey and stops delivering
+// its jobs, on another instance with its next heartbeat. Jobs already
+// delivered are redelivered once their lease expires.
+func (c *NoNoodleWorkflowCorePostgresql) UnsubscribeTask(sessionKey string) error {
+
+	subscriber, err := c.repo.GetSubscriberBySessionKey(sessionKey)
+	if err != nil {
+		return err
+	}
+	if subscriber == nil {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, sessionKey)
+	}
+
+	if err := c.repo.RemoveSubscriber(sessionKey); err != nil {
+		return err
+	}
+
+	if err := c.subscribers.stop(sessionKey); err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// RenewSubscription counts as a passed health check of the subscription of
+// sessionKey, so a worker can keep its subscription alive on its own. Another
+// instance owning it applies the renewal with its next heartbeat.
+func (c *NoNoodleWorkflowCorePostgresql) RenewSubscription(sessionKey string) (entitites.SubscriptionStatus, error) {
+
+	renewDate := util.GetCurrentTime()
+	renewed, err := c.repo.RenewSubscriber(sessionKey, renewDate)
+	if err != nil {
+		return entitites.SubscriptionStatus{}, err
+	}
+	if !renewed {
+		return entitites.SubscriptionStatus{}, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, sessionKey)
+	}
+
+	if subscription, ok := c.subscribers.get(sessionKey); ok {
+		subscription.recordRenewal(renewDate)
+		return subscription.status(), nil
+	}
+
+	subscriber, err := c.repo.GetSubscriberBySessionKey(sessionKey)
+	if err != nil {
+		return entitites.SubscriptionStatus{}, err
+	}
+	if subscriber == nil {
+		return entitites.SubscriptionStatus{}, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, sessionKey)
+	}
+	return storedSubscriptionStatus(*subscriber), nil
+}
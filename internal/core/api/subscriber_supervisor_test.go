@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+// hungWorker answers health checks but never answers a delivery until the
+// request is given up. Every delivery that reaches it is sent to delivered.
+func hungWorker(t *testing.T) (*httptest.Server, chan struct{}) {
+	t.Helper()
+
+	delivered := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			return
+		}
+		// The server notices the client going away only once the body is read
+		io.ReadAll(r.Body)
+		delivered <- struct{}{}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, delivered
+}
+
+// subscribeHungWorker deploys process sp with start task a, subscribes a hung
+// worker to it and starts a workflow, it returns once the job reached the worker.
+func subscribeHungWorker(t *testing.T, policy api.DeliveryPolicy) (api.NoNoodleCoreInterface, string) {
+	t.Helper()
+
+	core, _, _ := newCoreOn(t, msgbroker.NewMemoryMessageBroker(), policy)
+	server, delivered := hungWorker(t)
+
+	err := core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "sp",
+		MapStageTask:  map[string][]string{"start": {"a"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionKey, _, err := core.SubscribeTask("sp", "a", server.URL+"/health", server.URL+"/callback", entitites.HealthCheckConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.CreateWorkflow("sp", "bk"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job was never delivered")
+	}
+	return core, sessionKey
+}
+
+func TestDeliveryTimesOut(t *testing.T) {
+	core, sessionKey := subscribeHungWorker(t, api.DeliveryPolicy{Timeout: 100 * time.Millisecond, BackoffBase: time.Minute, BackoffMax: time.Minute})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var status entitites.CircuitBreakerStatus
+		for _, breaker := range core.ListCircuitBreakers() {
+			if breaker.SessionKey == sessionKey {
+				status = breaker
+			}
+		}
+		if status.ConsecutiveFailures == 1 {
+			if !strings.Contains(status.LastError, "deadline exceeded") {
+				t.Fatalf("got last error %q, want the delivery to time out", status.LastError)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want the delivery to have failed once", status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := core.UnsubscribeTask(sessionKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestUnsubscribeAbortsDelivery stops a subscription while its worker holds a
+// delivery, with a delivery timeout far longer than the test.
+func TestUnsubscribeAbortsDelivery(t *testing.T) {
+	core, sessionKey := subscribeHungWorker(t, api.DeliveryPolicy{Timeout: time.Hour})
+
+	unsubscribed := make(chan error, 1)
+	go func() { unsubscribed <- core.UnsubscribeTask(sessionKey) }()
+
+	select {
+	case err := <-unsubscribed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("unsubscribing waited for the delivery in flight")
+	}
+
+	stats, err := core.GetQueueStats("sp", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Reserved != 1 {
+		t.Fatalf("got %+v, want the aborted job to stay reserved", *stats)
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+func validateTaskLease(mapTaskLeaseSeconds map[string]int) error {
+
+	for task, seconds := range mapTaskLeaseSeconds {
+		if seconds <= 0 {
+			return fmt.Errorf("%w: task %s has lease %d seconds", ErrInvalidTaskLease, task, seconds)
+		}
+	}
+
+	return nil
+}
+
+// taskLease returns the lease of task in the process version workflowID runs
+// on, or the default lease when that version does not set one.
+func (c *NoNoodleWorkflowCorePostgresql) taskLease(workflowID string, task string) time.Duration {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		fmt.Printf("Failed to read lease of workflow %s task %s: %v\n", workflowID, task, err)
+		return c.pubsub.defaultLease
+	}
+	defer tx.Rollback()
+
+	workflow, err := c.repo.GetWorkflowByWorkflowID(tx, workflowID)
+	if err != nil {
+		fmt.Printf("Failed to read lease of workflow %s task %s: %v\n", workflowID, task, err)
+		return c.pubsub.defaultLease
+	}
+
+	processConfig, err := c.repo.GetProcessConfigByVersion(tx, workflow.ProcessID, workflow.ProcessVersion)
+	if err != nil {
+		fmt.Printf("Failed to read lease of workflow %s task %s: %v\n", workflowID, task, err)
+		return c.pubsub.defaultLease
+	}
+
+	if seconds, ok := processConfig.MapTaskLeaseSeconds[task]; ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return c.pubsub.defaultLease
+}
+
+// jobLease returns the lease of the task a reserved job stands for, or the
+// default lease when the payload is not a task job.
+func (c *NoNoodleWorkflowCorePostgresql) jobLease(envelope *msgbroker.Envelope) time.Duration {
+
+	var job taskJob
+	if err := json.Unmarshal(envelope.Payload, &job); err != nil || job.WorkflowID == "" {
+		return c.pubsub.defaultLease
+	}
+	return c.taskLease(job.WorkflowID, job.TaskID)
+}
+
+// leaseTask records the delivered job and its lease on the task instance, so
+// the active task shows which job it waits for and until when. It returns
+// false when the task is already finished and the job must not be delivered.
+func (c *NoNoodleWorkflowCorePostgresql) leaseTask(workflowID string, task string, jobID string, leaseExpireDate time.Time) bool {
+
+	unfinished, err := c.repo.UpdateTaskLease(workflowID, task, jobID, leaseExpireDate)
+	if err != nil {
+		// Deliver anyway, the worker can still complete the task
+		fmt.Printf("Failed to record lease for workflow %s task %s: %v\n", workflowID, task, err)
+		return true
+	}
+	if !unfinished {
+		fmt.Printf("Dropping job %s, workflow %s task %s is already finished\n", jobID, workflowID, task)
+	}
+	return unfinished
+}
+
+// ackTaskJob acks the job delivered for a task that has just finished. Until
+// then the job stays reserved, so it is delivered again if its lease expires.
+// A job is only ever queued for its own task, see MoveQueueJobs, so the task
+// names the queue it was reserved from.
+func (c *NoNoodleWorkflowCorePostgresql) ackTaskJob(taskInstance *entitites.TaskInstance) {
+
+	if taskInstance == nil || taskInstance.JobID == "" {
+		return
+	}
+
+	err := c.pubsub.Ack(context.Background(), taskChannal(taskInstance.ProcessID, taskInstance.Task), taskInstance.JobID)
+	if err != nil {
+		fmt.Printf("Failed to ack job %s of workflow %s task %s: %v\n", taskInstance.JobID, taskInstance.WorkflowID, taskInstance.Task, err)
+	}
+}
+
+func (c *NoNoodleWorkflowCorePostgresql) getTaskInstance(workflowID string, task string) (*entitites.TaskInstance, error) {
+
+	tx, err := c.repo.GetDB().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	taskInstances, err := c.repo.GetTaskInstancesByWorkflowID(tx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, taskInstance := range taskInstances {
+		if taskInstance.Task == task {
+			return &taskInstance, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: workflow %s task %s", ErrTaskNotFound, workflowID, task)
+}
+
+// HeartbeatTask extends the lease of the job delivered for an active task by
+// the task lease, counted from now. It returns when the new lease expires.
+func (c *NoNoodleWorkflowCorePostgresql) HeartbeatTask(workflowID string, task string) (time.Time, error) {
+
+	taskInstance, err := c.getTaskInstance(workflowID, task)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if taskInstance.Status != TASK_STATUS_IN_ACTIVE || taskInstance.JobID == "" {
+		return time.Time{}, fmt.Errorf("%w: workflow %s task %s is %s", ErrTaskNotActive, workflowID, task, taskInstance.Status)
+	}
+
+	return c.extendTaskLease(taskInstance, c.taskLease(workflowID, task))
+}
+
+// extendTaskLease extends the lease of the job delivered for taskInstance by
+// lease, counted from now.
+func (c *NoNoodleWorkflowCorePostgresql) extendTaskLease(taskInstance *entitites.TaskInstance, lease time.Duration) (time.Time, error) {
+
+	workflowID, task := taskInstance.WorkflowID, taskInstance.Task
+	extended, err := c.pubsub.ExtendLease(context.Background(), taskChannal(taskInstance.ProcessID, task), taskInstance.JobID, lease)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !extended {
+		return time.Time{}, fmt.Errorf("%w: workflow %s task %s job %s", ErrLeaseExpired, workflowID, task, taskInstance.JobID)
+	}
+
+	leaseExpireDate := time.Now().Add(lease)
+	unfinished, err := c.repo.UpdateTaskLease(workflowID, task, taskInstance.JobID, leaseExpireDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !unfinished {
+		return time.Time{}, fmt.Errorf("%w: workflow %s task %s", ErrTaskNotActive, workflowID, task)
+	}
+
+	return leaseExpireDate, nil
+}
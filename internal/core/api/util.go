@@ -11,3 +11,8 @@ func generateWorkflowID() string {
 func generateSessionKey() string {
 	return uuid.New().String()
 }
+
+// taskChannal is the broker queue holding the jobs of one process task
+func taskChannal(processID string, task string) string {
+	return "no_noodle_workflow:" + processID + ":" + task
+}
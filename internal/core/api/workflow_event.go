@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+const (
+	defaultWorkflowEventLimit = 100
+	maxWorkflowEventLimit     = 1000
+
+	// workflowEventPollInterval bounds how late a watcher sees events
+	// committed by another instance of the core
+	workflowEventPollInterval = time.Second
+
+	// workflowEventSettleTime is how long after it was recorded an event may
+	// still be joined by a late commit of an earlier event ID. Pages leave out
+	// events younger than that, watchers read that far back again.
+	workflowEventSettleTime = time.Second
+	// workflowEventRereadWindow is how long watchers keep reading back, well
+	// above any workflow transaction
+	workflowEventRereadWindow = 10 * time.Second
+)
+
+// workflowEventHub wakes the watchers of this instance once new events are
+// committed. Waiters take the current channel, which is closed on broadcast.
+type workflowEventHub struct {
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+func newWorkflowEventHub() *workflowEventHub {
+	return &workflowEventHub{changed: make(chan struct{})}
+}
+
+func (h *workflowEventHub) wait() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.changed
+}
+
+func (h *workflowEventHub) broadcast() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+// recordWorkflowEvent adds an event to the history of a workflow within the
+// transaction changing it, watchers see it once the transaction commits.
+func (c *NoNoodleWorkflowCorePostgresql) recordWorkflowEvent(tx *sql.Tx, workflowID string, processID string, eventType string, stage string, task string) error {
+	return c.repo.InsertWorkflowEvents(tx, []entitites.WorkflowEvent{{
+		WorkflowID: workflowID,
+		ProcessID:  processID,
+		Type:       eventType,
+		Stage:      stage,
+		Task:       task,
+		CreateDate: util.GetCurrentTime(),
+	}})
+}
+
+func normalizeWorkflowEventQuery(query entitites.WorkflowEventQuery) entitites.WorkflowEventQuery {
+	if query.Limit <= 0 {
+		query.Limit = defaultWorkflowEventLimit
+	}
+	if query.Limit > maxWorkflowEventLimit {
+		query.Limit = maxWorkflowEventLimit
+	}
+	if query.AfterEventID < 0 {
+		query.AfterEventID = 0
+	}
+	return query
+}
+
+// ListWorkflowEvents returns a page of the event history in event ID order.
+// Events recorded in the last workflowEventSettleTime are held back, so the
+// next page, starting after the last event, never skips an event committed
+// late with a lower ID.
+func (c *NoNoodleWorkflowCorePostgresql) ListWorkflowEvents(query entitites.WorkflowEventQuery) ([]entitites.WorkflowEvent, error) {
+	query = normalizeWorkflowEventQuery(query)
+	query.RecordedBefore = util.GetCurrentTime().Add(-workflowEventSettleTime)
+	return c.repo.ListWorkflowEvents(query)
+}
+
+// LatestWorkflowEventID returns the ID of the newest recorded event, a watcher
+// starting after it only sees events from now on.
+func (c *NoNoodleWorkflowCorePostgresql) LatestWorkflowEventID() (int64, error) {
+	return c.repo.GetLatestWorkflowEventID()
+}
+
+// WatchWorkflowEvents sends every event matching query after
+// query.AfterEventID, first from the history and then as they are recorded,
+// until ctx is done or send fails. Every round reads back the events of the
+// last workflowEventRereadWindow, so an event committed after others with a
+// higher ID is still sent, once.
+func (c *NoNoodleWorkflowCorePostgresql) WatchWorkflowEvents(ctx context.Context, query entitites.WorkflowEventQuery, send func(event entitites.WorkflowEvent) error) error {
+
+	query = normalizeWorkflowEventQuery(query)
+	// Events up to settled are final, sent holds the record time of the
+	// events sent after it
+	settled := query.AfterEventID
+	sent := make(map[int64]time.Time)
+	for {
+		// Taken before listing, so an event committed meanwhile still wakes us
+		changed := c.workflowEvents.wait()
+
+		query.AfterEventID = settled
+		for {
+			events, err := c.repo.ListWorkflowEvents(query)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				query.AfterEventID = event.EventID
+				if _, ok := sent[event.EventID]; ok {
+					continue
+				}
+				if err := send(event); err != nil {
+					return err
+				}
+				sent[event.EventID] = event.CreateDate
+			}
+
+			// Pruned on every page, a long history is not held in memory
+			rereadFrom := util.GetCurrentTime().Add(-workflowEventRereadWindow)
+			for eventID, createDate := range sent {
+				if createDate.Before(rereadFrom) {
+					settled = max(settled, eventID)
+					delete(sent, eventID)
+				}
+			}
+			if len(events) < query.Limit {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-time.After(workflowEventPollInterval):
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package api_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// newWorkflow creates a workflow and returns it with the ID of its latest
+// event.
+func newWorkflow(t *testing.T, core api.NoNoodleCoreInterface) (string, int64) {
+	t.Helper()
+
+	err := core.DeployProcessConfig(&entitites.ProcessConfig{
+		ProcessID:     "ep",
+		MapStageTask:  map[string][]string{"start": {"a"}},
+		MapStageReady: map[string][]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflowID, err := core.CreateWorkflow("ep", "bk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latestEventID, err := core.LatestWorkflowEventID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return workflowID, latestEventID
+}
+
+// commitEvent commits an event with a chosen ID, standing in for a workflow
+// transaction that commits after others took higher IDs.
+func commitEvent(t *testing.T, db *sql.DB, workflowID string, eventID int64) {
+	t.Helper()
+
+	_, err := db.Exec("INSERT INTO workflow_event (event_id, workflow_id, process_id, type, task, create_date) VALUES (?, ?, ?, ?, ?, ?)",
+		eventID, workflowID, "ep", entitites.WORKFLOW_EVENT_TASK_ACTIVE, "a", util.GetCurrentTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func eventIDs(events []entitites.WorkflowEvent) []int64 {
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.EventID
+	}
+	return ids
+}
+
+func TestListWorkflowEventsOutOfOrderCommit(t *testing.T) {
+	core, db := newCore(t)
+	workflowID, latestEventID := newWorkflow(t, core)
+	query := entitites.WorkflowEventQuery{WorkflowID: workflowID, AfterEventID: latestEventID}
+
+	commitEvent(t, db, workflowID, latestEventID+2)
+	events, err := core.ListWorkflowEvents(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("got events %v before they settled, want none", eventIDs(events))
+	}
+
+	commitEvent(t, db, workflowID, latestEventID+1)
+	time.Sleep(1500 * time.Millisecond)
+	events, err = core.ListWorkflowEvents(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := eventIDs(events); len(ids) != 2 || ids[0] != latestEventID+1 || ids[1] != latestEventID+2 {
+		t.Fatalf("got events %v, want [%d %d]", ids, latestEventID+1, latestEventID+2)
+	}
+}
+
+func TestWatchWorkflowEventsOutOfOrderCommit(t *testing.T) {
+	core, db := newCore(t)
+	workflowID, latestEventID := newWorkflow(t, core)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan int64, 10)
+	query := entitites.WorkflowEventQuery{WorkflowID: workflowID, AfterEventID: latestEventID}
+	go core.WatchWorkflowEvents(ctx, query, func(event entitites.WorkflowEvent) error {
+		received <- event.EventID
+		return nil
+	})
+
+	expect := func(want int64) {
+		t.Helper()
+
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("got event %d, want %d", got, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("event %d was not sent", want)
+		}
+	}
+
+	commitEvent(t, db, workflowID, latestEventID+2)
+	expect(latestEventID + 2)
+	commitEvent(t, db, workflowID, latestEventID+1)
+	expect(latestEventID + 1)
+
+	select {
+	case got := <-received:
+		t.Fatalf("got event %d again", got)
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
@@ -19,18 +19,34 @@ const (
 	DEFAULT_HTTP_CLIENT_TIMEOUT     = 10 * time.Second
 	DEFAULT_WATERMILL_MAX_IDLE_TIME = 30 * time.Minute
 	DEFAULT_NUM_WORKER              = 10
+
+	REPOSITORY_BACKEND_POSTGRESQL = "postgresql"
+	REPOSITORY_BACKEND_SQLITE     = "sqlite"
+
+	MESSAGE_BROKER_REDIS         = "redis"
+	MESSAGE_BROKER_REDIS_STREAMS = "redis_streams"
+	MESSAGE_BROKER_MEMORY        = "memory"
+	MESSAGE_BROKER_POSTGRESQL    = "postgresql"
 )
 
 type Config struct {
 	ServerConfig             ServerConfig
 	ServiceConfig            ServiceConfig
+	MessageBrokerConfig      MessageBrokerConfig
+	DeliveryConfig           DeliveryConfig
 	RedisMessageBrokerConfig RedisMessageBrokerConfig
+	PostgresqlBrokerConfig   PostgresqlBrokerConfig
+	RepositoryConfig         RepositoryConfig
 	PostgresqlRepoConfig     PostgresqlRepoConfig
+	SQLiteRepoConfig         SQLiteRepoConfig
+	RetentionConfig          RetentionConfig
+	ClusterConfig            ClusterConfig
 }
 
 type ServerConfig struct {
 	// Debug string
 	HTTP HTTPConfig
+	GRPC GRPCConfig
 	// Middleware MiddlewareConfig
 }
 
@@ -42,6 +58,11 @@ type HTTPConfig struct {
 	// ProxyURL string
 }
 
+// GRPCConfig configures the gRPC server, an empty Port disables it.
+type GRPCConfig struct {
+	Port string
+}
+
 type MiddlewareConfig struct {
 	AllowCors string
 }
@@ -53,10 +74,72 @@ type ServiceConfig struct {
 	NoNoodleConfig NoNoodleConfig
 }
 
+// MessageBrokerConfig selects the task queue transport.
+// Type is MESSAGE_BROKER_REDIS, MESSAGE_BROKER_REDIS_STREAMS, MESSAGE_BROKER_MEMORY
+// or MESSAGE_BROKER_POSTGRESQL. Both Redis brokers use RedisMessageBrokerConfig.
+// A job failing MaxDeliveryAttempts deliveries is dead-lettered, 0 retries forever.
+// DefaultTaskLease applies to tasks without a lease in their process config.
+type MessageBrokerConfig struct {
+	Type                string
+	MaxDeliveryAttempts int
+	DefaultTaskLease    time.Duration
+}
+
+// DeliveryConfig paces callback deliveries to subscribers. After a failed
+// delivery the subscriber is left alone for BackoffBase, doubling with every
+// further failure up to BackoffMax, or for as long as a 429 or 503 asks with
+// Retry-After, at most RetryAfterMax. CircuitBreakerThreshold failures in a row open the circuit of
+// the subscriber, pausing its deliveries for CircuitBreakerCooldown. A
+// threshold of 0 disables the circuit breaker. A delivery the subscriber has
+// not answered within Timeout counts as failed.
+type DeliveryConfig struct {
+	Timeout                 time.Duration
+	BackoffBase             time.Duration
+	BackoffMax              time.Duration
+	RetryAfterMax           time.Duration
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+}
+
+// PostgresqlBrokerConfig tunes the job_queue broker. It shares the repository
+// connection, so it requires REPOSITORY_BACKEND_POSTGRESQL.
+type PostgresqlBrokerConfig struct {
+	PollInterval time.Duration
+}
+
+// RedisMessageBrokerConfig is the connection of both Redis brokers. Setting
+// SentinelMasterName connects through the Sentinels at SentinelAddrs, setting
+// ClusterMode or listing several Addrs connects to a Redis Cluster, otherwise
+// Addrs is a single node. Username and Password authenticate with Redis ACL,
+// the Sentinel ones with the Sentinels. DB is ignored on a cluster.
+//
+// TLSEnabled encrypts every connection. TLSCAFile replaces the system roots,
+// TLSCertFile and TLSKeyFile add a client certificate.
 type RedisMessageBrokerConfig struct {
-	Addr     string
+	Addrs    []string
+	Username string
 	Password string
 	DB       int
+
+	SentinelMasterName string
+	SentinelAddrs      []string
+	SentinelUsername   string
+	SentinelPassword   string
+
+	ClusterMode bool
+
+	TLSEnabled            bool
+	TLSCAFile             string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+}
+
+// RepositoryConfig selects which storage backend the core runs on.
+// Backend is either REPOSITORY_BACKEND_POSTGRESQL or REPOSITORY_BACKEND_SQLITE.
+type RepositoryConfig struct {
+	Backend string
 }
 
 type PostgresqlRepoConfig struct {
@@ -68,6 +151,31 @@ type PostgresqlRepoConfig struct {
 	SSLMode  string
 }
 
+// RetentionConfig drives the background job applying per process retention
+// policies. Archived workflows are written under ArchiveDir, a relative
+// ArchiveDir is resolved against the working directory of the core.
+type RetentionConfig struct {
+	Enabled    bool
+	Interval   time.Duration
+	BatchSize  int
+	ArchiveDir string
+}
+
+// ClusterConfig lets core instances sharing the repository database split the
+// subscriptions between them. Each instance renews its leases every
+// HeartbeatInterval and is considered gone once they are LeaseDuration old.
+// An empty InstanceID is generated from the hostname.
+type ClusterConfig struct {
+	InstanceID        string
+	LeaseDuration     time.Duration
+	HeartbeatInterval time.Duration
+}
+
+type SQLiteRepoConfig struct {
+	Path          string
+	BusyTimeoutMs int
+}
+
 func GetConfig() *Config {
 	if config != nil {
 		return config
@@ -115,14 +223,51 @@ func doInit() {
 				Port:              getEnvString("HTTP_PORT", "8888"),
 				ConnectionTimeout: getEnvDurationFromSeconds("HTTP_TIMEOUT_SEC", DEFAULT_HTTP_CLIENT_TIMEOUT),
 			},
+			GRPC: GRPCConfig{
+				Port: getEnvString("GRPC_PORT", "9999"),
+			},
 		},
 		ServiceConfig: ServiceConfig{
 			NoNoodleConfig: NoNoodleConfig{},
 		},
+		MessageBrokerConfig: MessageBrokerConfig{
+			Type:                getEnvString("MESSAGE_BROKER", MESSAGE_BROKER_REDIS),
+			MaxDeliveryAttempts: getEnvInt("MESSAGE_BROKER_MAX_DELIVERY_ATTEMPTS", 10),
+			DefaultTaskLease:    getEnvDurationFromSeconds("TASK_LEASE_DEFAULT_SEC", 20*time.Second),
+		},
+		DeliveryConfig: DeliveryConfig{
+			Timeout:                 getEnvDurationFromSeconds("DELIVERY_TIMEOUT_SEC", 30*time.Second),
+			BackoffBase:             getEnvDurationFromMillisecond("DELIVERY_BACKOFF_BASE_MS", 500*time.Millisecond),
+			BackoffMax:              getEnvDurationFromMillisecond("DELIVERY_BACKOFF_MAX_MS", 30*time.Second),
+			RetryAfterMax:           getEnvDurationFromSeconds("DELIVERY_RETRY_AFTER_MAX_SEC", 5*time.Minute),
+			CircuitBreakerThreshold: getEnvInt("DELIVERY_CIRCUIT_BREAKER_THRESHOLD", 5),
+			CircuitBreakerCooldown:  getEnvDurationFromSeconds("DELIVERY_CIRCUIT_BREAKER_COOLDOWN_SEC", 30*time.Second),
+		},
 		RedisMessageBrokerConfig: RedisMessageBrokerConfig{
-			Addr:     getEnvString("REDIS_ADDR", "localhost:6379"),
+			Addrs:    getEnvStringArray("REDIS_ADDR", []string{"localhost:6379"}),
+			Username: getEnvString("REDIS_USERNAME", ""),
 			Password: getEnvString("REDIS_PASSWORD", ""),
 			DB:       getEnvInt("REDIS_DB", 0),
+
+			SentinelMasterName: getEnvString("REDIS_SENTINEL_MASTER", ""),
+			SentinelAddrs:      getEnvStringArray("REDIS_SENTINEL_ADDRS", nil),
+			SentinelUsername:   getEnvString("REDIS_SENTINEL_USERNAME", ""),
+			SentinelPassword:   getEnvString("REDIS_SENTINEL_PASSWORD", ""),
+
+			ClusterMode: getEnvBool("REDIS_CLUSTER_MODE", false),
+
+			TLSEnabled:            getEnvBool("REDIS_TLS_ENABLED", false),
+			TLSCAFile:             getEnvString("REDIS_TLS_CA_FILE", ""),
+			TLSCertFile:           getEnvString("REDIS_TLS_CERT_FILE", ""),
+			TLSKeyFile:            getEnvString("REDIS_TLS_KEY_FILE", ""),
+			TLSServerName:         getEnvString("REDIS_TLS_SERVER_NAME", ""),
+			TLSInsecureSkipVerify: getEnvBool("REDIS_TLS_INSECURE_SKIP_VERIFY", false),
+		},
+		PostgresqlBrokerConfig: PostgresqlBrokerConfig{
+			PollInterval: getEnvDurationFromMillisecond("POSTGRES_BROKER_POLL_INTERVAL_MS", 500*time.Millisecond),
+		},
+		RepositoryConfig: RepositoryConfig{
+			Backend: getEnvString("REPOSITORY_BACKEND", REPOSITORY_BACKEND_POSTGRESQL),
 		},
 		PostgresqlRepoConfig: PostgresqlRepoConfig{
 			Host:     getEnvString("POSTGRES_HOST", "localhost"),
@@ -132,6 +277,21 @@ func doInit() {
 			Dbname:   getEnvString("POSTGRES_DBNAME", "postgres"),
 			SSLMode:  getEnvString("POSTGRES_SSLMODE", "disable"),
 		},
+		SQLiteRepoConfig: SQLiteRepoConfig{
+			Path:          getEnvString("SQLITE_PATH", "no_noodle_workflow.db"),
+			BusyTimeoutMs: getEnvInt("SQLITE_BUSY_TIMEOUT_MS", 5000),
+		},
+		RetentionConfig: RetentionConfig{
+			Enabled:    getEnvBool("RETENTION_ENABLED", true),
+			Interval:   getEnvDurationFromSeconds("RETENTION_INTERVAL_SEC", time.Hour),
+			BatchSize:  getEnvInt("RETENTION_BATCH_SIZE", 500),
+			ArchiveDir: getEnvString("RETENTION_ARCHIVE_DIR", "archive"),
+		},
+		ClusterConfig: ClusterConfig{
+			InstanceID:        getEnvString("CLUSTER_INSTANCE_ID", ""),
+			LeaseDuration:     getEnvDurationFromSeconds("CLUSTER_LEASE_SEC", 15*time.Second),
+			HeartbeatInterval: getEnvDurationFromSeconds("CLUSTER_HEARTBEAT_SEC", 5*time.Second),
+		},
 	}
 }
 
@@ -0,0 +1,24 @@
+package entitites
+
+import "time"
+
+const (
+	CIRCUIT_STATE_CLOSED    = "closed"
+	CIRCUIT_STATE_OPEN      = "open"
+	CIRCUIT_STATE_HALF_OPEN = "half_open"
+)
+
+// CircuitBreakerStatus is the delivery state of one subscriber. While the
+// circuit is open nothing is delivered to it until RetryDate, then a single
+// delivery decides whether it closes or opens again.
+type CircuitBreakerStatus struct {
+	SessionKey          string     `json:"session_key"`
+	ProcessID           string     `json:"process_id"`
+	Task                string     `json:"task"`
+	CallbackURL         string     `json:"callback_url"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	OpenedDate          *time.Time `json:"opened_date,omitempty"`
+	RetryDate           *time.Time `json:"retry_date,omitempty"`
+}
@@ -0,0 +1,15 @@
+package entitites
+
+import "time"
+
+// CoreInstance is a core sharing the database with the others. It is alive
+// until LeaseExpireDate unless it renews its lease.
+type CoreInstance struct {
+	InstanceID      string    `json:"instance_id"`
+	StartDate       time.Time `json:"start_date"`
+	LeaseExpireDate time.Time `json:"lease_expire_date"`
+	// Self marks the instance answering the request
+	Self bool `json:"self"`
+	// Subscriptions counts the subscriptions the instance holds a lease on
+	Subscriptions int `json:"subscriptions"`
+}
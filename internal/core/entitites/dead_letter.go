@@ -0,0 +1,19 @@
+package entitites
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is a task job given up on after too many failed deliveries.
+// Payload is the job exactly as it would be delivered to the subscriber.
+type DeadLetter struct {
+	ID          string          `json:"id"`
+	ProcessID   string          `json:"process_id"`
+	Task        string          `json:"task"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error"`
+	EnqueueDate time.Time       `json:"enqueue_date"`
+	DeadDate    time.Time       `json:"dead_date"`
+}
@@ -0,0 +1,21 @@
+package entitites
+
+import "time"
+
+// FetchTopic names a task queue a pulling worker takes jobs from.
+type FetchTopic struct {
+	ProcessID string `json:"process_id"`
+	Task      string `json:"task"`
+}
+
+// LockedJob is a job handed to a pulling worker. The worker completes, fails
+// or extends it by JobID before LeaseExpireDate, after that the job is
+// delivered again.
+type LockedJob struct {
+	JobID           string    `json:"job_id"`
+	ProcessID       string    `json:"process_id"`
+	TaskID          string    `json:"task_id"`
+	WorkflowID      string    `json:"workflow_id"`
+	Attempts        int       `json:"attempts"`
+	LeaseExpireDate time.Time `json:"lease_expire_date"`
+}
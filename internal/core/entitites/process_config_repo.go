@@ -1,7 +1,16 @@
 package entitites
 
+import "time"
+
 type ProcessConfig struct {
 	ProcessID     string              `json:"process_id"`
+	Version       int                 `json:"version"`
+	Enabled       bool                `json:"enabled"`
 	MapStageTask  map[string][]string `json:"map_stage_task"`
 	MapStageReady map[string][]string `json:"map_stage_ready"`
+	// MapTaskLeaseSeconds overrides the default lease of a task, the time a
+	// worker has to complete a delivered job or extend it with a heartbeat
+	// before it is delivered again.
+	MapTaskLeaseSeconds map[string]int `json:"map_task_lease_seconds,omitempty"`
+	CreateDate          time.Time      `json:"create_date"`
 }
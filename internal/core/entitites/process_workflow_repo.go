@@ -2,15 +2,31 @@ package entitites
 
 import "time"
 
+const (
+	WORKFLOW_STATUS_RUNNING   = "running"
+	WORKFLOW_STATUS_COMPLETED = "completed"
+	WORKFLOW_STATUS_FAILED    = "failed"
+)
+
 type TaskStatusData struct {
-	Status     string    `json:"status"`
-	UpdateDate time.Time `json:"update_date"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	Worker          string     `json:"worker,omitempty"`
+	JobID           string     `json:"job_id,omitempty"`
+	LeaseExpireDate *time.Time `json:"lease_expire_date,omitempty"`
+	StartDate       *time.Time `json:"start_date,omitempty"`
+	EndDate         *time.Time `json:"end_date,omitempty"`
+	UpdateDate      time.Time  `json:"update_date"`
 }
 
 type Workflow struct {
 	WorkflowID     string                    `json:"workflow_id"`
 	ProcessID      string                    `json:"process_id"`
-	TaskStatus     map[string]TaskStatusData `json:"task_status"`
+	ProcessVersion int                       `json:"process_version"`
+	Status         string                    `json:"status"`
+	BusinessKey    string                    `json:"business_key,omitempty"`
+	TaskStatus     map[string]TaskStatusData `json:"task_status,omitempty"`
 	PublishedStage map[string]bool           `json:"published_stage"`
 	CreateDate     time.Time                 `json:"create_date"`
+	UpdateDate     time.Time                 `json:"update_date"`
 }
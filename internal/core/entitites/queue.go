@@ -0,0 +1,36 @@
+package entitites
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QueueStats describes the job queue of one process task. Reserved jobs are
+// held by a worker, InFlight counts those whose lease has not expired yet.
+// OldestAgeSeconds is how long the oldest ready job has been waiting.
+type QueueStats struct {
+	ProcessID         string     `json:"process_id"`
+	Task              string     `json:"task"`
+	Queue             string     `json:"queue"`
+	Ready             int        `json:"ready"`
+	Delayed           int        `json:"delayed"`
+	Reserved          int        `json:"reserved"`
+	InFlight          int        `json:"in_flight"`
+	Dead              int        `json:"dead"`
+	OldestEnqueueDate *time.Time `json:"oldest_enqueue_date,omitempty"`
+	OldestAgeSeconds  float64    `json:"oldest_age_seconds"`
+}
+
+// QueuedJob is a job waiting in or reserved from a task queue, State is ready,
+// delayed or reserved. VisibleDate is when the lease of a reserved job
+// expires or when a delayed job is due.
+type QueuedJob struct {
+	ID          string          `json:"id"`
+	ProcessID   string          `json:"process_id"`
+	Task        string          `json:"task"`
+	State       string          `json:"state"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	EnqueueDate time.Time       `json:"enqueue_date"`
+	VisibleDate *time.Time      `json:"visible_date,omitempty"`
+}
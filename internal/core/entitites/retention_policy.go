@@ -0,0 +1,12 @@
+package entitites
+
+// RetentionPolicy decides how long finished workflows of a process are kept.
+// A zero retention keeps workflows of that status forever. When Archive is
+// set, workflows are exported with their event history to the archive
+// directory before being deleted.
+type RetentionPolicy struct {
+	ProcessID              string `json:"process_id"`
+	CompletedRetentionDays int    `json:"completed_retention_days"`
+	FailedRetentionDays    int    `json:"failed_retention_days"`
+	Archive                bool   `json:"archive"`
+}
@@ -17,10 +17,34 @@ import "time"
 // FOREIGN KEY (process_id) REFERENCES process_config (process_id)
 
 type SubscriberRegistry struct {
-	SessionKey     string    `json:"session_key"`
-	ProcessID      string    `json:"process_id"`
-	Task           string    `json:"task"`
-	HealthCheckURL string    `json:"health_check_url"`
-	CallbackURL    string    `json:"callback_url"`
-	CreateDate     time.Time `json:"create_date"`
+	SessionKey     string `json:"session_key"`
+	ProcessID      string `json:"process_id"`
+	Task           string `json:"task"`
+	HealthCheckURL string `json:"health_check_url"`
+	CallbackURL    string `json:"callback_url"`
+	// SigningSecret signs the deliveries to CallbackURL, it is only handed
+	// out once in the subscribe response
+	SigningSecret string            `json:"-"`
+	HealthCheck   HealthCheckConfig `json:"health_check"`
+	// OwnerInstanceID is the core instance consuming the subscription, it
+	// holds it until OwnerLeaseExpireDate unless it renews the lease
+	OwnerInstanceID      string     `json:"owner_instance_id,omitempty"`
+	OwnerLeaseExpireDate *time.Time `json:"owner_lease_expire_date,omitempty"`
+	// Revision is bumped whenever a re-subscribe takes the subscription over
+	Revision int `json:"revision"`
+	// RenewDate is the last time the worker vouched for its own health
+	RenewDate  *time.Time `json:"renew_date,omitempty"`
+	CreateDate time.Time  `json:"create_date"`
+}
+
+// HealthCheckConfig is how the core checks a subscriber is alive: every
+// IntervalSeconds it sends Method to the health check URL and expects one of
+// StatusCodes within TimeoutSeconds. The subscription is dropped after
+// FailureThreshold failed checks in a row.
+type HealthCheckConfig struct {
+	IntervalSeconds  int    `json:"interval_seconds"`
+	TimeoutSeconds   int    `json:"timeout_seconds"`
+	FailureThreshold int    `json:"failure_threshold"`
+	Method           string `json:"method"`
+	StatusCodes      []int  `json:"status_codes"`
 }
@@ -0,0 +1,32 @@
+package entitites
+
+import "time"
+
+const (
+	SUBSCRIPTION_HEALTH_HEALTHY   = "healthy"
+	SUBSCRIPTION_HEALTH_UNHEALTHY = "unhealthy"
+	// The subscription is stored but not supervised by this instance
+	SUBSCRIPTION_HEALTH_UNKNOWN = "unknown"
+)
+
+// SubscriptionStatus is the state of a subscription. Health turns unhealthy
+// on a failed health check and the subscription is dropped once
+// ConsecutiveHealthCheckFailures reaches the failure threshold of its health
+// check. The live fields are only set on the instance supervising it.
+type SubscriptionStatus struct {
+	SessionKey                     string                `json:"session_key"`
+	ProcessID                      string                `json:"process_id"`
+	Task                           string                `json:"task"`
+	HealthCheckURL                 string                `json:"health_check_url"`
+	CallbackURL                    string                `json:"callback_url"`
+	HealthCheck                    HealthCheckConfig     `json:"health_check"`
+	Health                         string                `json:"health"`
+	ConsecutiveHealthCheckFailures int                   `json:"consecutive_health_check_failures"`
+	LastHealthCheckDate            *time.Time            `json:"last_health_check_date,omitempty"`
+	LastHealthCheckError           string                `json:"last_health_check_error,omitempty"`
+	CircuitBreaker                 *CircuitBreakerStatus `json:"circuit_breaker,omitempty"`
+	OwnerInstanceID                string                `json:"owner_instance_id,omitempty"`
+	RenewDate                      *time.Time            `json:"renew_date,omitempty"`
+	CreateDate                     time.Time             `json:"create_date"`
+	StartDate                      *time.Time            `json:"start_date,omitempty"`
+}
@@ -0,0 +1,43 @@
+package entitites
+
+import "time"
+
+const (
+	TASK_STATUS_WAITING   = "waiting"
+	TASK_STATUS_IN_ACTIVE = "active"
+	TASK_STATUS_COMPLETED = "completed"
+	TASK_STATUS_FAILED    = "failed"
+)
+
+// TaskInstance is one row of the task_instance table, the state of a single
+// task inside a single workflow.
+type TaskInstance struct {
+	WorkflowID string `json:"workflow_id"`
+	ProcessID  string `json:"process_id"`
+	Task       string `json:"task"`
+	Status     string `json:"status"`
+	Attempts   int    `json:"attempts"`
+	Worker     string `json:"worker,omitempty"`
+	// JobID is the broker message of the latest delivery, LeaseExpireDate is
+	// when it is delivered again unless the worker sends a heartbeat. Both are
+	// only set while the task is active.
+	JobID           string     `json:"job_id,omitempty"`
+	LeaseExpireDate *time.Time `json:"lease_expire_date,omitempty"`
+	StartDate       *time.Time `json:"start_date,omitempty"`
+	EndDate         *time.Time `json:"end_date,omitempty"`
+	CreateDate      time.Time  `json:"create_date"`
+	UpdateDate      time.Time  `json:"update_date"`
+}
+
+func (t TaskInstance) ToTaskStatusData() TaskStatusData {
+	return TaskStatusData{
+		Status:          t.Status,
+		Attempts:        t.Attempts,
+		Worker:          t.Worker,
+		JobID:           t.JobID,
+		LeaseExpireDate: t.LeaseExpireDate,
+		StartDate:       t.StartDate,
+		EndDate:         t.EndDate,
+		UpdateDate:      t.UpdateDate,
+	}
+}
@@ -0,0 +1,37 @@
+package entitites
+
+import "time"
+
+const (
+	WORKFLOW_EVENT_CREATED            = "workflow_created"
+	WORKFLOW_EVENT_STAGE_PUBLISHED    = "stage_published"
+	WORKFLOW_EVENT_TASK_ACTIVE        = "task_active"
+	WORKFLOW_EVENT_TASK_COMPLETED     = "task_completed"
+	WORKFLOW_EVENT_TASK_FAILED        = "task_failed"
+	WORKFLOW_EVENT_WORKFLOW_COMPLETED = "workflow_completed"
+	WORKFLOW_EVENT_WORKFLOW_FAILED    = "workflow_failed"
+)
+
+// WorkflowEvent is one entry of the event history of a workflow. EventID
+// grows with every recorded event across all workflows. Stage is set for
+// stage events, Task for task events.
+type WorkflowEvent struct {
+	EventID    int64     `json:"event_id"`
+	WorkflowID string    `json:"workflow_id"`
+	ProcessID  string    `json:"process_id"`
+	Type       string    `json:"type"`
+	Stage      string    `json:"stage,omitempty"`
+	Task       string    `json:"task,omitempty"`
+	CreateDate time.Time `json:"create_date"`
+}
+
+// WorkflowEventQuery selects the events after AfterEventID, narrowed to a
+// workflow or a process when set, and to events recorded before
+// RecordedBefore unless it is zero.
+type WorkflowEventQuery struct {
+	WorkflowID     string
+	ProcessID      string
+	AfterEventID   int64
+	RecordedBefore time.Time
+	Limit          int
+}
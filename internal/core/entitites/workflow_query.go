@@ -0,0 +1,35 @@
+package entitites
+
+import "time"
+
+const (
+	WORKFLOW_SORT_BY_CREATE_DATE = "create_date"
+	WORKFLOW_SORT_BY_UPDATE_DATE = "update_date"
+
+	SORT_ORDER_ASC  = "asc"
+	SORT_ORDER_DESC = "desc"
+)
+
+// WorkflowQuery filters a workflow search. Empty fields are not filtered on.
+// TaskStatus matches workflows having at least one task in that status,
+// narrowed to a single task when Task is set.
+type WorkflowQuery struct {
+	ProcessID   string
+	Status      string
+	Task        string
+	TaskStatus  string
+	BusinessKey string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	SortBy    string
+	SortOrder string
+	Limit     int
+	Cursor    string
+}
+
+// WorkflowPage is one page of a workflow search. NextCursor is empty on the last page.
+type WorkflowPage struct {
+	Workflows  []Workflow `json:"workflows"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,151 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/nonoodlepb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toTimestampPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestampPB(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	converted := t.AsTime()
+	return &converted
+}
+
+func toTaskListsPB(stages map[string][]string) map[string]*nonoodlepb.TaskList {
+	taskLists := make(map[string]*nonoodlepb.TaskList, len(stages))
+	for stage, tasks := range stages {
+		taskLists[stage] = &nonoodlepb.TaskList{Tasks: tasks}
+	}
+	return taskLists
+}
+
+func fromTaskListsPB(taskLists map[string]*nonoodlepb.TaskList) map[string][]string {
+	stages := make(map[string][]string, len(taskLists))
+	for stage, taskList := range taskLists {
+		stages[stage] = taskList.GetTasks()
+	}
+	return stages
+}
+
+func toProcessConfigPB(processConfig *entitites.ProcessConfig) *nonoodlepb.ProcessConfig {
+
+	leaseSeconds := make(map[string]int32, len(processConfig.MapTaskLeaseSeconds))
+	for task, seconds := range processConfig.MapTaskLeaseSeconds {
+		leaseSeconds[task] = int32(seconds)
+	}
+
+	return &nonoodlepb.ProcessConfig{
+		ProcessId:           processConfig.ProcessID,
+		Version:             int32(processConfig.Version),
+		Enabled:             processConfig.Enabled,
+		MapStageTask:        toTaskListsPB(processConfig.MapStageTask),
+		MapStageReady:       toTaskListsPB(processConfig.MapStageReady),
+		MapTaskLeaseSeconds: leaseSeconds,
+		CreateDate:          toTimestampPB(&processConfig.CreateDate),
+	}
+}
+
+// fromProcessConfigPB converts a process config to deploy, the version and
+// dates are assigned by the core.
+func fromProcessConfigPB(processConfig *nonoodlepb.ProcessConfig) *entitites.ProcessConfig {
+
+	var leaseSeconds map[string]int
+	if len(processConfig.GetMapTaskLeaseSeconds()) > 0 {
+		leaseSeconds = make(map[string]int, len(processConfig.GetMapTaskLeaseSeconds()))
+		for task, seconds := range processConfig.GetMapTaskLeaseSeconds() {
+			leaseSeconds[task] = int(seconds)
+		}
+	}
+
+	return &entitites.ProcessConfig{
+		ProcessID:           processConfig.GetProcessId(),
+		MapStageTask:        fromTaskListsPB(processConfig.GetMapStageTask()),
+		MapStageReady:       fromTaskListsPB(processConfig.GetMapStageReady()),
+		MapTaskLeaseSeconds: leaseSeconds,
+	}
+}
+
+func toWorkflowPB(workflow *entitites.Workflow) *nonoodlepb.Workflow {
+
+	taskStatus := make(map[string]*nonoodlepb.TaskStatus, len(workflow.TaskStatus))
+	for task, data := range workflow.TaskStatus {
+		taskStatus[task] = &nonoodlepb.TaskStatus{
+			Status:          data.Status,
+			Attempts:        int32(data.Attempts),
+			Worker:          data.Worker,
+			JobId:           data.JobID,
+			LeaseExpireDate: toTimestampPB(data.LeaseExpireDate),
+			StartDate:       toTimestampPB(data.StartDate),
+			EndDate:         toTimestampPB(data.EndDate),
+			UpdateDate:      toTimestampPB(&data.UpdateDate),
+		}
+	}
+
+	return &nonoodlepb.Workflow{
+		WorkflowId:     workflow.WorkflowID,
+		ProcessId:      workflow.ProcessID,
+		ProcessVersion: int32(workflow.ProcessVersion),
+		Status:         workflow.Status,
+		BusinessKey:    workflow.BusinessKey,
+		TaskStatus:     taskStatus,
+		PublishedStage: workflow.PublishedStage,
+		CreateDate:     toTimestampPB(&workflow.CreateDate),
+		UpdateDate:     toTimestampPB(&workflow.UpdateDate),
+	}
+}
+
+func fromSearchWorkflowsRequestPB(req *nonoodlepb.SearchWorkflowsRequest) entitites.WorkflowQuery {
+	return entitites.WorkflowQuery{
+		ProcessID:   req.GetProcessId(),
+		Status:      req.GetStatus(),
+		Task:        req.GetTask(),
+		TaskStatus:  req.GetTaskStatus(),
+		BusinessKey: req.GetBusinessKey(),
+		CreatedFrom: fromTimestampPB(req.GetCreatedFrom()),
+		CreatedTo:   fromTimestampPB(req.GetCreatedTo()),
+		SortBy:      req.GetSortBy(),
+		SortOrder:   req.GetSortOrder(),
+		Limit:       int(req.GetLimit()),
+		Cursor:      req.GetCursor(),
+	}
+}
+
+func toLockedJobPB(job entitites.LockedJob) *nonoodlepb.LockedJob {
+	return &nonoodlepb.LockedJob{
+		JobId:           job.JobID,
+		ProcessId:       job.ProcessID,
+		TaskId:          job.TaskID,
+		WorkflowId:      job.WorkflowID,
+		Attempts:        int32(job.Attempts),
+		LeaseExpireDate: toTimestampPB(&job.LeaseExpireDate),
+	}
+}
+
+func toTopicsPB(topics []entitites.FetchTopic) []*nonoodlepb.Topic {
+	converted := make([]*nonoodlepb.Topic, 0, len(topics))
+	for _, topic := range topics {
+		converted = append(converted, &nonoodlepb.Topic{ProcessId: topic.ProcessID, Task: topic.Task})
+	}
+	return converted
+}
+
+func fromTopicsPB(topics []*nonoodlepb.Topic) []entitites.FetchTopic {
+	converted := make([]entitites.FetchTopic, 0, len(topics))
+	for _, topic := range topics {
+		converted = append(converted, entitites.FetchTopic{ProcessID: topic.GetProcessId(), Task: topic.GetTask()})
+	}
+	return converted
+}
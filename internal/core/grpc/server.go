@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/nonoodlepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// keepaliveTime and keepaliveTimeout bound how long a job stream of a
+	// worker that stopped answering pings holds its jobs
+	keepaliveTime    = 15 * time.Second
+	keepaliveTimeout = 15 * time.Second
+)
+
+type Server struct {
+	nonoodlepb.UnimplementedNoNoodleWorkflowServer
+	noNoodleCore api.NoNoodleCoreInterface
+}
+
+// NewGRPCServer serves the same core as NewHTTPRouter over gRPC. The server
+// is not listening yet, hand it any net.Listener with Serve.
+func NewGRPCServer(noNoodleCore api.NoNoodleCoreInterface, opts ...grpc.ServerOption) *grpc.Server {
+
+	opts = append([]grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    keepaliveTime,
+			Timeout: keepaliveTimeout,
+		}),
+	}, opts...)
+
+	server := grpc.NewServer(opts...)
+	nonoodlepb.RegisterNoNoodleWorkflowServer(server, &Server{
+		noNoodleCore: noNoodleCore,
+	})
+
+	return server
+}
+
+// toStatus maps core errors to the gRPC status codes of the contract.
+func toStatus(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, api.ErrWorkflowNotFound),
+		errors.Is(err, api.ErrProcessNotFound),
+		errors.Is(err, api.ErrProcessConfigNotFound),
+		errors.Is(err, api.ErrTaskNotFound),
+		errors.Is(err, api.ErrJobNotFound):
+		code = codes.NotFound
+	case errors.Is(err, api.ErrInvalidWorkflowQuery),
+		errors.Is(err, api.ErrInvalidTaskLease),
+		errors.Is(err, api.ErrInvalidProcessID),
+		errors.Is(err, api.ErrInvalidFetchRequest):
+		code = codes.InvalidArgument
+	case errors.Is(err, api.ErrProcessDisabled),
+		errors.Is(err, api.ErrTaskNotActive),
+		errors.Is(err, api.ErrLeaseExpired):
+		code = codes.FailedPrecondition
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func (s *Server) DeployProcessConfig(ctx context.Context, req *nonoodlepb.DeployProcessConfigRequest) (*nonoodlepb.DeployProcessConfigResponse, error) {
+
+	if req.GetProcessConfig() == nil {
+		return nil, status.Error(codes.InvalidArgument, "process_config is required")
+	}
+
+	if err := s.noNoodleCore.DeployProcessConfig(fromProcessConfigPB(req.GetProcessConfig())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.DeployProcessConfigResponse{}, nil
+}
+
+func (s *Server) GetProcessConfig(ctx context.Context, req *nonoodlepb.GetProcessConfigRequest) (*nonoodlepb.GetProcessConfigResponse, error) {
+
+	var processConfig *entitites.ProcessConfig
+	var err error
+	if req.GetVersion() == 0 {
+		processConfig, err = s.noNoodleCore.GetProcessConfig(req.GetProcessId())
+	} else {
+		processConfig, err = s.noNoodleCore.GetProcessConfigVersion(req.GetProcessId(), int(req.GetVersion()))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.GetProcessConfigResponse{ProcessConfig: toProcessConfigPB(processConfig)}, nil
+}
+
+func (s *Server) CreateWorkflow(ctx context.Context, req *nonoodlepb.CreateWorkflowRequest) (*nonoodlepb.CreateWorkflowResponse, error) {
+
+	workflowID, err := s.noNoodleCore.CreateWorkflow(req.GetProcessId(), req.GetBusinessKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.CreateWorkflowResponse{WorkflowId: workflowID}, nil
+}
+
+func (s *Server) GetWorkflow(ctx context.Context, req *nonoodlepb.GetWorkflowRequest) (*nonoodlepb.GetWorkflowResponse, error) {
+
+	workflow, err := s.noNoodleCore.GetWorkflow(req.GetWorkflowId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.GetWorkflowResponse{Workflow: toWorkflowPB(workflow)}, nil
+}
+
+func (s *Server) SearchWorkflows(ctx context.Context, req *nonoodlepb.SearchWorkflowsRequest) (*nonoodlepb.SearchWorkflowsResponse, error) {
+
+	page, err := s.noNoodleCore.SearchWorkflows(fromSearchWorkflowsRequestPB(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &nonoodlepb.SearchWorkflowsResponse{NextCursor: page.NextCursor}
+	for i := range page.Workflows {
+		resp.Workflows = append(resp.Workflows, toWorkflowPB(&page.Workflows[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) CompleteTask(ctx context.Context, req *nonoodlepb.CompleteTaskRequest) (*nonoodlepb.CompleteTaskResponse, error) {
+
+	if err := s.noNoodleCore.CompleteTask(req.GetWorkflowId(), req.GetTask()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.CompleteTaskResponse{}, nil
+}
+
+func (s *Server) FailTask(ctx context.Context, req *nonoodlepb.FailTaskRequest) (*nonoodlepb.FailTaskResponse, error) {
+
+	if err := s.noNoodleCore.FailedTask(req.GetWorkflowId(), req.GetTask()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.FailTaskResponse{}, nil
+}
+
+func (s *Server) HeartbeatTask(ctx context.Context, req *nonoodlepb.HeartbeatTaskRequest) (*nonoodlepb.HeartbeatTaskResponse, error) {
+
+	leaseExpireDate, err := s.noNoodleCore.HeartbeatTask(req.GetWorkflowId(), req.GetTask())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.HeartbeatTaskResponse{LeaseExpireDate: toTimestampPB(&leaseExpireDate)}, nil
+}
+
+func (s *Server) CompleteJob(ctx context.Context, req *nonoodlepb.CompleteJobRequest) (*nonoodlepb.CompleteJobResponse, error) {
+
+	if err := s.noNoodleCore.CompleteJob(req.GetJobId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.CompleteJobResponse{}, nil
+}
+
+func (s *Server) FailJob(ctx context.Context, req *nonoodlepb.FailJobRequest) (*nonoodlepb.FailJobResponse, error) {
+
+	if err := s.noNoodleCore.FailJob(req.GetJobId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.FailJobResponse{}, nil
+}
+
+func (s *Server) ExtendJobLock(ctx context.Context, req *nonoodlepb.ExtendJobLockRequest) (*nonoodlepb.ExtendJobLockResponse, error) {
+
+	leaseExpireDate, err := s.noNoodleCore.ExtendJobLock(req.GetJobId(), time.Duration(req.GetLockDurationSeconds())*time.Second)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &nonoodlepb.ExtendJobLockResponse{LeaseExpireDate: toTimestampPB(&leaseExpireDate)}, nil
+}
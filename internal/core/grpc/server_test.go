@@ -0,0 +1,327 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	coregrpc "github.com/keerapon-som/no_noodle_workflow/internal/core/grpc"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+	"github.com/keerapon-som/no_noodle_workflow/nonoodlepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves a core backed by SQLite and the memory broker on an
+// in-process listener.
+func newClient(t *testing.T) nonoodlepb.NoNoodleWorkflowClient {
+	t.Helper()
+
+	db, err := util.NewSQLite(filepath.Join(t.TempDir(), "no_noodle.db"), 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repository.NewSQLiteNoNoodleWorkflow(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker := msgbroker.NewMemoryMessageBroker()
+	core := api.NewNoNoodleWorkflowCorePostgresql(repo, api.NewMessageService(broker, 3, 20*time.Second, api.DeliveryPolicy{}), api.ClusterPolicy{})
+
+	listener := bufconn.Listen(1 << 20)
+	server := coregrpc.NewGRPCServer(core)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		broker.Close()
+		db.Close()
+	})
+
+	return nonoodlepb.NewNoNoodleWorkflowClient(conn)
+}
+
+func deploy(t *testing.T, client nonoodlepb.NoNoodleWorkflowClient, processID string) {
+	t.Helper()
+
+	_, err := client.DeployProcessConfig(context.Background(), &nonoodlepb.DeployProcessConfigRequest{
+		ProcessConfig: &nonoodlepb.ProcessConfig{
+			ProcessId: processID,
+			MapStageTask: map[string]*nonoodlepb.TaskList{
+				"start": {Tasks: []string{"a"}},
+				"s2":    {Tasks: []string{"b"}},
+			},
+			MapStageReady: map[string]*nonoodlepb.TaskList{
+				"s2": {Tasks: []string{"a"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func wantCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+
+	if status.Code(err) != code {
+		t.Fatalf("got error %v, want code %v", err, code)
+	}
+}
+
+func TestUnary(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	deploy(t, client, "p1")
+	config, err := client.GetProcessConfig(ctx, &nonoodlepb.GetProcessConfigRequest{ProcessId: "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.GetProcessConfig().GetVersion() != 1 || config.GetProcessConfig().GetMapStageTask()["s2"].GetTasks()[0] != "b" {
+		t.Fatalf("unexpected process config %v", config.GetProcessConfig())
+	}
+	_, err = client.GetProcessConfig(ctx, &nonoodlepb.GetProcessConfigRequest{ProcessId: "missing"})
+	wantCode(t, err, codes.NotFound)
+
+	created, err := client.CreateWorkflow(ctx, &nonoodlepb.CreateWorkflowRequest{ProcessId: "p1", BusinessKey: "order-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateWorkflow(ctx, &nonoodlepb.CreateWorkflowRequest{ProcessId: "missing"})
+	wantCode(t, err, codes.NotFound)
+
+	_, err = client.CompleteTask(ctx, &nonoodlepb.CompleteTaskRequest{WorkflowId: created.GetWorkflowId(), Task: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.FailTask(ctx, &nonoodlepb.FailTaskRequest{WorkflowId: created.GetWorkflowId(), Task: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workflow, err := client.GetWorkflow(ctx, &nonoodlepb.GetWorkflowRequest{WorkflowId: created.GetWorkflowId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workflow.GetWorkflow().GetStatus() != "failed" || workflow.GetWorkflow().GetTaskStatus()["a"].GetStatus() != "completed" {
+		t.Fatalf("unexpected workflow %v", workflow.GetWorkflow())
+	}
+	_, err = client.GetWorkflow(ctx, &nonoodlepb.GetWorkflowRequest{WorkflowId: "missing"})
+	wantCode(t, err, codes.NotFound)
+
+	page, err := client.SearchWorkflows(ctx, &nonoodlepb.SearchWorkflowsRequest{ProcessId: "p1", BusinessKey: "order-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.GetWorkflows()) != 1 || page.GetWorkflows()[0].GetWorkflowId() != created.GetWorkflowId() {
+		t.Fatalf("unexpected search result %v", page.GetWorkflows())
+	}
+	_, err = client.SearchWorkflows(ctx, &nonoodlepb.SearchWorkflowsRequest{SortBy: "nonsense"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+// jobStream is the client side of a job stream, its responses are read
+// into responses until the stream ends.
+type jobStream struct {
+	nonoodlepb.NoNoodleWorkflow_StreamJobsClient
+	responses chan *nonoodlepb.StreamJobsResponse
+}
+
+func (s *jobStream) recv(t *testing.T, timeout time.Duration) *nonoodlepb.StreamJobsResponse {
+	t.Helper()
+
+	select {
+	case resp, ok := <-s.responses:
+		if !ok {
+			t.Fatal("the job stream ended")
+		}
+		return resp
+	case <-time.After(timeout):
+		t.Fatal("no response from the job stream")
+	}
+	return nil
+}
+
+func (s *jobStream) send(t *testing.T, req *nonoodlepb.StreamJobsRequest) {
+	t.Helper()
+
+	if err := s.Send(req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openStream(t *testing.T, ctx context.Context, client nonoodlepb.NoNoodleWorkflowClient, workerID string, maxJobs int32, topics ...*nonoodlepb.Topic) *jobStream {
+	t.Helper()
+
+	stream, err := client.StreamJobs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &jobStream{NoNoodleWorkflow_StreamJobsClient: stream, responses: make(chan *nonoodlepb.StreamJobsResponse, 16)}
+	go func() {
+		defer close(s.responses)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			s.responses <- resp
+		}
+	}()
+
+	s.send(t, &nonoodlepb.StreamJobsRequest{
+		RequestId: "open",
+		Request: &nonoodlepb.StreamJobsRequest_Open{Open: &nonoodlepb.OpenJobStream{
+			WorkerId: workerID,
+			MaxJobs:  maxJobs,
+			Topics:   topics,
+		}},
+	})
+
+	result := s.recv(t, time.Second).GetResult()
+	if result.GetRequestId() != "open" || result.GetCode() != int32(codes.OK) || len(result.GetTopics()) != len(topics) {
+		t.Fatalf("unexpected open result %v", result)
+	}
+
+	return s
+}
+
+func TestStreamJobs(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	deploy(t, client, "p1")
+	topicA := &nonoodlepb.Topic{ProcessId: "p1", Task: "a"}
+	stream := openStream(t, ctx, client, "w1", 1, topicA)
+
+	for _, businessKey := range []string{"order-1", "order-2"} {
+		if _, err := client.CreateWorkflow(ctx, &nonoodlepb.CreateWorkflowRequest{ProcessId: "p1", BusinessKey: businessKey}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := stream.recv(t, time.Second).GetJob()
+	if first == nil || first.GetTaskId() != "a" {
+		t.Fatalf("expected a job of task a, got %v", first)
+	}
+
+	_, err := client.ExtendJobLock(ctx, &nonoodlepb.ExtendJobLockRequest{JobId: first.GetJobId(), LockDurationSeconds: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second job waits for the only slot
+	select {
+	case resp := <-stream.responses:
+		t.Fatalf("got %v while the only slot was taken", resp)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	complete := func(requestID string, jobID string) {
+		stream.send(t, &nonoodlepb.StreamJobsRequest{
+			RequestId: requestID,
+			Request:   &nonoodlepb.StreamJobsRequest_Complete{Complete: &nonoodlepb.CompleteJobRequest{JobId: jobID}},
+		})
+	}
+	complete("complete", first.GetJobId())
+
+	// The freed slot may deliver before the result is sent
+	var second *nonoodlepb.LockedJob
+	var result *nonoodlepb.StreamResult
+	for second == nil || result == nil {
+		resp := stream.recv(t, time.Second)
+		if resp.GetJob() != nil {
+			second = resp.GetJob()
+		} else {
+			result = resp.GetResult()
+		}
+	}
+	if result.GetRequestId() != "complete" || result.GetCode() != int32(codes.OK) {
+		t.Fatalf("unexpected complete result %v", result)
+	}
+	if second.GetWorkflowId() == first.GetWorkflowId() {
+		t.Fatal("the completed job was delivered again")
+	}
+
+	complete("complete-again", first.GetJobId())
+	if result := stream.recv(t, time.Second).GetResult(); result.GetCode() != int32(codes.FailedPrecondition) {
+		t.Fatalf("unexpected result completing twice %v", result)
+	}
+}
+
+func TestStreamJobsReleasesJobsOfClosedStream(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	deploy(t, client, "p1")
+	topicA := &nonoodlepb.Topic{ProcessId: "p1", Task: "a"}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream := openStream(t, streamCtx, client, "w1", 1, topicA)
+	if _, err := client.CreateWorkflow(ctx, &nonoodlepb.CreateWorkflowRequest{ProcessId: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	held := stream.recv(t, time.Second).GetJob()
+	cancel()
+
+	// Delivered again long before the task lease runs out
+	other := openStream(t, ctx, client, "w2", 1, topicA)
+	job := other.recv(t, 2*time.Second).GetJob()
+	if job.GetWorkflowId() != held.GetWorkflowId() || job.GetAttempts() != 2 {
+		t.Fatalf("expected the held job again, got %v", job)
+	}
+
+	_, err := client.CompleteJob(ctx, &nonoodlepb.CompleteJobRequest{JobId: held.GetJobId()})
+	wantCode(t, err, codes.FailedPrecondition)
+}
+
+func TestStreamJobsRequiresOpen(t *testing.T) {
+	client := newClient(t)
+
+	stream, err := client.StreamJobs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&nonoodlepb.StreamJobsRequest{
+		Request: &nonoodlepb.StreamJobsRequest_Complete{Complete: &nonoodlepb.CompleteJobRequest{JobId: "x"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	wantCode(t, err, codes.InvalidArgument)
+
+	stream, err = client.StreamJobs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&nonoodlepb.StreamJobsRequest{
+		Request: &nonoodlepb.StreamJobsRequest_Open{Open: &nonoodlepb.OpenJobStream{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	wantCode(t, err, codes.InvalidArgument)
+}
@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/nonoodlepb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamJobs runs an api.JobStream for the worker opening the stream, the
+// gRPC counterpart of the WebSocket job stream.
+func (s *Server) StreamJobs(stream nonoodlepb.NoNoodleWorkflow_StreamJobsServer) error {
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	open := first.GetOpen()
+	if open == nil {
+		return status.Error(codes.InvalidArgument, "the first request must open the stream")
+	}
+
+	maxJobs := int(open.GetMaxJobs())
+	if maxJobs == 0 {
+		maxJobs = 1
+	}
+	jobStream, err := s.noNoodleCore.OpenJobStream(open.GetWorkerId(), maxJobs, time.Duration(open.GetLockDurationSeconds())*time.Second)
+	if err != nil {
+		return toStatus(err)
+	}
+	if len(open.GetTopics()) > 0 {
+		if err := jobStream.Subscribe(fromTopicsPB(open.GetTopics())); err != nil {
+			return toStatus(err)
+		}
+	}
+
+	var sendMu sync.Mutex
+	send := func(resp *nonoodlepb.StreamJobsResponse) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+
+		return stream.Send(resp)
+	}
+
+	if err := send(streamResult(first.GetRequestId(), nil, &nonoodlepb.StreamResult{Topics: toTopicsPB(jobStream.Topics())})); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	running := make(chan error, 1)
+	go func() {
+		defer cancel()
+		running <- jobStream.Run(ctx, func(job entitites.LockedJob) error {
+			return send(&nonoodlepb.StreamJobsResponse{
+				Response: &nonoodlepb.StreamJobsResponse_Job{Job: toLockedJobPB(job)},
+			})
+		})
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				fmt.Println("Job stream stopped receiving:", err)
+			}
+			break
+		}
+
+		if err := send(handleStreamRequest(jobStream, req)); err != nil {
+			break
+		}
+	}
+
+	cancel()
+	return <-running
+}
+
+func handleStreamRequest(jobStream *api.JobStream, req *nonoodlepb.StreamJobsRequest) *nonoodlepb.StreamJobsResponse {
+
+	result := &nonoodlepb.StreamResult{}
+	var err error
+	switch request := req.GetRequest().(type) {
+	case *nonoodlepb.StreamJobsRequest_Subscribe:
+		err = jobStream.Subscribe(fromTopicsPB(request.Subscribe.GetTopics()))
+		result.Topics = toTopicsPB(jobStream.Topics())
+	case *nonoodlepb.StreamJobsRequest_Unsubscribe:
+		jobStream.Unsubscribe(fromTopicsPB(request.Unsubscribe.GetTopics()))
+		result.Topics = toTopicsPB(jobStream.Topics())
+	case *nonoodlepb.StreamJobsRequest_Complete:
+		err = jobStream.Complete(request.Complete.GetJobId())
+	case *nonoodlepb.StreamJobsRequest_Fail:
+		err = jobStream.Fail(request.Fail.GetJobId())
+	case *nonoodlepb.StreamJobsRequest_ExtendLock:
+		var leaseExpireDate time.Time
+		leaseExpireDate, err = jobStream.Extend(request.ExtendLock.GetJobId(), time.Duration(request.ExtendLock.GetLockDurationSeconds())*time.Second)
+		result.LeaseExpireDate = toTimestampPB(&leaseExpireDate)
+	case *nonoodlepb.StreamJobsRequest_Open:
+		err = status.Error(codes.FailedPrecondition, "the stream is already open")
+	default:
+		err = status.Error(codes.InvalidArgument, "the request is empty")
+	}
+
+	return streamResult(req.GetRequestId(), err, result)
+}
+
+func streamResult(requestID string, err error, result *nonoodlepb.StreamResult) *nonoodlepb.StreamJobsResponse {
+
+	result.RequestId = requestID
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			st, _ = status.FromError(toStatus(err))
+		}
+		result.Code = int32(st.Code())
+		result.Message = st.Message()
+	}
+
+	return &nonoodlepb.StreamJobsResponse{
+		Response: &nonoodlepb.StreamJobsResponse_Result{Result: result},
+	}
+}
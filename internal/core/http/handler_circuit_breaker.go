@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListCircuitBreakers reports the delivery state of every subscriber consumed
+// by this instance, an open circuit shows when deliveries resume.
+func (h *Handler) ListCircuitBreakers(c *fiber.Ctx) error {
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   h.noNoodleCore.ListCircuitBreakers(),
+	})
+}
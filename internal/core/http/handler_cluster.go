@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListClusterInstances reports the live core instances sharing the database
+// and how many subscriptions each consumes.
+func (h *Handler) ListClusterInstances(c *fiber.Ctx) error {
+
+	instances, err := h.noNoodleCore.ListClusterInstances()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Failed to list cluster instances",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   instances,
+	})
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ListDeadLetters(c *fiber.Ctx) error {
+
+	deadLetters, err := h.noNoodleCore.ListDeadLetters(c.Params("process_id"), c.Params("task"))
+	if err != nil {
+		return deadLetterError(c, err, "Failed to list dead letters")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   deadLetters,
+	})
+}
+
+func (h *Handler) GetDeadLetter(c *fiber.Ctx) error {
+
+	deadLetter, err := h.noNoodleCore.GetDeadLetter(c.Params("process_id"), c.Params("task"), c.Params("id"))
+	if err != nil {
+		return deadLetterError(c, err, "Failed to get dead letter")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   deadLetter,
+	})
+}
+
+func (h *Handler) ReplayDeadLetter(c *fiber.Ctx) error {
+
+	err := h.noNoodleCore.ReplayDeadLetter(c.Params("process_id"), c.Params("task"), c.Params("id"))
+	if err != nil {
+		return deadLetterError(c, err, "Failed to replay dead letter")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+	})
+}
+
+func (h *Handler) DeleteDeadLetter(c *fiber.Ctx) error {
+
+	err := h.noNoodleCore.DeleteDeadLetter(c.Params("process_id"), c.Params("task"), c.Params("id"))
+	if err != nil {
+		return deadLetterError(c, err, "Failed to delete dead letter")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+	})
+}
+
+func (h *Handler) PurgeDeadLetters(c *fiber.Ctx) error {
+
+	purged, err := h.noNoodleCore.PurgeDeadLetters(c.Params("process_id"), c.Params("task"))
+	if err != nil {
+		return deadLetterError(c, err, "Failed to purge dead letters")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"purged": purged,
+		},
+	})
+}
+
+func deadLetterError(c *fiber.Ctx, err error, message string) error {
+	statusCode := fiber.StatusInternalServerError
+	if errors.Is(err, api.ErrDeadLetterNotFound) {
+		statusCode = fiber.StatusNotFound
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"status":  "error",
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FetchAndLock long-polls for jobs of the given topics, for workers that
+// cannot receive pushed jobs. It answers once max_jobs are locked, some jobs
+// are locked and no more are waiting, or timeout_seconds pass.
+func (h *Handler) FetchAndLock(c *fiber.Ctx) error {
+
+	type FetchAndLockRequest struct {
+		WorkerID            string                 `json:"worker_id"`
+		Topics              []entitites.FetchTopic `json:"topics"`
+		MaxJobs             int                    `json:"max_jobs"`
+		LockDurationSeconds int                    `json:"lock_duration_seconds"`
+		TimeoutSeconds      int                    `json:"timeout_seconds"`
+	}
+
+	req := FetchAndLockRequest{MaxJobs: 1}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid request body",
+		})
+	}
+
+	jobs, err := h.noNoodleCore.FetchAndLockJobs(
+		req.WorkerID,
+		req.Topics,
+		req.MaxJobs,
+		time.Duration(req.LockDurationSeconds)*time.Second,
+		time.Duration(req.TimeoutSeconds)*time.Second,
+	)
+	if err != nil {
+		return jobError(c, err, "Failed to fetch jobs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   jobs,
+	})
+}
+
+type jobRequest struct {
+	JobID               string `json:"job_id"`
+	LockDurationSeconds int    `json:"lock_duration_seconds"`
+}
+
+func (h *Handler) CompleteJob(c *fiber.Ctx) error {
+
+	var req jobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid request body",
+		})
+	}
+
+	if err := h.noNoodleCore.CompleteJob(req.JobID); err != nil {
+		return jobError(c, err, "Failed to complete job")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+	})
+}
+
+func (h *Handler) FailedJob(c *fiber.Ctx) error {
+
+	var req jobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid request body",
+		})
+	}
+
+	if err := h.noNoodleCore.FailJob(req.JobID); err != nil {
+		return jobError(c, err, "Failed to fail job")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+	})
+}
+
+// ExtendJobLock extends the lock of a fetched job by lock_duration_seconds,
+// or by the task lease when it is not set
+func (h *Handler) ExtendJobLock(c *fiber.Ctx) error {
+
+	var req jobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid request body",
+		})
+	}
+
+	leaseExpireDate, err := h.noNoodleCore.ExtendJobLock(req.JobID, time.Duration(req.LockDurationSeconds)*time.Second)
+	if err != nil {
+		return jobError(c, err, "Failed to extend job lock")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   fiber.Map{"lease_expire_date": leaseExpireDate},
+	})
+}
+
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, api.ErrInvalidFetchRequest), errors.Is(err, api.ErrInvalidTaskLease):
+		return fiber.StatusBadRequest
+	case errors.Is(err, api.ErrJobNotFound), errors.Is(err, api.ErrTaskNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, api.ErrTaskNotActive), errors.Is(err, api.ErrLeaseExpired):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+func jobError(c *fiber.Ctx, err error, message string) error {
+	return c.Status(jobErrorStatus(err)).JSON(fiber.Map{
+		"status":  "error",
+		"error":   message,
+		"details": err.Error(),
+	})
+}
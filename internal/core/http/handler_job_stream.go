@@ -0,0 +1,196 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	jobStreamPingInterval = 15 * time.Second
+	// jobStreamPongWait is how long a silent worker is considered alive
+	jobStreamPongWait  = 2 * jobStreamPingInterval
+	jobStreamWriteWait = 10 * time.Second
+)
+
+// jobStreamMessage is a message of the worker on a job stream. Every message
+// is answered with a result carrying the same request_id.
+//
+//	{"type": "subscribe", "topics": [{"process_id": "...", "task": "..."}]}
+//	{"type": "unsubscribe", "topics": [...]}
+//	{"type": "complete", "job_id": "..."}
+//	{"type": "fail", "job_id": "..."}
+//	{"type": "extend_lock", "job_id": "...", "lock_duration_seconds": 30}
+type jobStreamMessage struct {
+	Type                string                 `json:"type"`
+	RequestID           string                 `json:"request_id"`
+	Topics              []entitites.FetchTopic `json:"topics"`
+	JobID               string                 `json:"job_id"`
+	LockDurationSeconds int                    `json:"lock_duration_seconds"`
+}
+
+// OpenJobStream opens the job stream of a worker connecting to
+// /job_stream?worker_id=&max_jobs=&lock_duration_seconds= before the
+// connection is upgraded, so a bad request is still answered over HTTP.
+func (h *Handler) OpenJobStream(c *fiber.Ctx) error {
+
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Job stream requires a WebSocket connection",
+		})
+	}
+
+	stream, err := h.noNoodleCore.OpenJobStream(
+		c.Query("worker_id"),
+		c.QueryInt("max_jobs", 1),
+		time.Duration(c.QueryInt("lock_duration_seconds"))*time.Second,
+	)
+	if err != nil {
+		return jobError(c, err, "Failed to open job stream")
+	}
+
+	c.Locals("job_stream", stream)
+	return c.Next()
+}
+
+// JobStream pushes jobs to the worker as {"type": "job", "data": job} and
+// takes its subscriptions, completions and lock extensions. The worker is
+// pinged regularly and its jobs are released once it stops answering.
+func (h *Handler) JobStream(conn *websocket.Conn) {
+
+	stream := conn.Locals("job_stream").(*api.JobStream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var writeMu sync.Mutex
+	write := func(message fiber.Map) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		conn.SetWriteDeadline(time.Now().Add(jobStreamWriteWait))
+		return conn.WriteJSON(message)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(jobStreamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(jobStreamPongWait))
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(jobStreamPingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(jobStreamWriteWait)); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		// Unblocks the read below when the stream stops on its own
+		defer conn.Close()
+		defer cancel()
+
+		err := stream.Run(ctx, func(job entitites.LockedJob) error {
+			return write(fiber.Map{"type": "job", "data": job})
+		})
+		if err != nil {
+			fmt.Println("Job stream stopped:", err)
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		conn.SetReadDeadline(time.Now().Add(jobStreamPongWait))
+
+		var message jobStreamMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			write(fiber.Map{
+				"type":    "result",
+				"status":  "error",
+				"error":   "Invalid message",
+				"details": err.Error(),
+			})
+			continue
+		}
+
+		if err := write(handleJobStreamMessage(stream, message)); err != nil {
+			break
+		}
+	}
+
+	cancel()
+	wg.Wait()
+}
+
+func handleJobStreamMessage(stream *api.JobStream, message jobStreamMessage) fiber.Map {
+
+	var data any
+	var err error
+	switch message.Type {
+	case "subscribe":
+		err = stream.Subscribe(message.Topics)
+		data = stream.Topics()
+	case "unsubscribe":
+		stream.Unsubscribe(message.Topics)
+		data = stream.Topics()
+	case "complete":
+		err = stream.Complete(message.JobID)
+	case "fail":
+		err = stream.Fail(message.JobID)
+	case "extend_lock":
+		var leaseExpireDate time.Time
+		leaseExpireDate, err = stream.Extend(message.JobID, time.Duration(message.LockDurationSeconds)*time.Second)
+		data = fiber.Map{"lease_expire_date": leaseExpireDate}
+	default:
+		return fiber.Map{
+			"type":       "result",
+			"request_id": message.RequestID,
+			"status":     "error",
+			"code":       fiber.StatusBadRequest,
+			"error":      "Unknown message type: " + message.Type,
+		}
+	}
+
+	if err != nil {
+		return fiber.Map{
+			"type":       "result",
+			"request_id": message.RequestID,
+			"status":     "error",
+			"code":       jobErrorStatus(err),
+			"error":      "Failed to handle " + message.Type,
+			"details":    err.Error(),
+		}
+	}
+
+	return fiber.Map{
+		"type":       "result",
+		"request_id": message.RequestID,
+		"status":     "success",
+		"data":       data,
+	}
+}
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
 
 	"github.com/gofiber/fiber/v2"
@@ -38,7 +40,8 @@ func (h *Handler) CompleteTask(c *fiber.Ctx) error {
 func (h *Handler) CreateWorkflow(c *fiber.Ctx) error {
 
 	type CreateWorkflowRequest struct {
-		ProcessID string `json:"process_id"`
+		ProcessID   string `json:"process_id"`
+		BusinessKey string `json:"business_key"`
 	}
 
 	var req CreateWorkflowRequest
@@ -48,10 +51,18 @@ func (h *Handler) CreateWorkflow(c *fiber.Ctx) error {
 		})
 	}
 
-	workflowID, err := h.noNoodleCore.CreateWorkflow(req.ProcessID)
+	workflowID, err := h.noNoodleCore.CreateWorkflow(req.ProcessID, req.BusinessKey)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create workflow",
+		statusCode := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, api.ErrProcessNotFound):
+			statusCode = fiber.StatusNotFound
+		case errors.Is(err, api.ErrProcessDisabled):
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to create workflow",
+			"details": err.Error(),
 		})
 	}
 
@@ -64,9 +75,10 @@ func (h *Handler) CreateWorkflow(c *fiber.Ctx) error {
 func (h *Handler) DeployProcessConfig(c *fiber.Ctx) error {
 
 	type DeployProcessConfigRequest struct {
-		ProcessID     string              `json:"process_id"`
-		MapStageTask  map[string][]string `json:"map_stage_task"`
-		MapStageReady map[string][]string `json:"map_stage_ready"`
+		ProcessID           string              `json:"process_id"`
+		MapStageTask        map[string][]string `json:"map_stage_task"`
+		MapStageReady       map[string][]string `json:"map_stage_ready"`
+		MapTaskLeaseSeconds map[string]int      `json:"map_task_lease_seconds"`
 	}
 
 	var req DeployProcessConfigRequest
@@ -76,20 +88,29 @@ func (h *Handler) DeployProcessConfig(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.noNoodleCore.DeployProcessConfig(&entitites.ProcessConfig{
-		ProcessID:     req.ProcessID,
-		MapStageTask:  req.MapStageTask,
-		MapStageReady: req.MapStageReady,
-	})
+	processConfig := &entitites.ProcessConfig{
+		ProcessID:           req.ProcessID,
+		MapStageTask:        req.MapStageTask,
+		MapStageReady:       req.MapStageReady,
+		MapTaskLeaseSeconds: req.MapTaskLeaseSeconds,
+	}
+	err := h.noNoodleCore.DeployProcessConfig(processConfig)
 	if err != nil {
+		if errors.Is(err, api.ErrInvalidTaskLease) || errors.Is(err, api.ErrInvalidProcessID) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Failed to deploy process config",
+				"details": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to deploy process config",
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"status": "success",
-		"data":   "Process config deployed successfully",
+		"status":  "success",
+		"data":    "Process config deployed successfully",
+		"version": processConfig.Version,
 	})
 }
 
@@ -120,6 +141,43 @@ func (h *Handler) FailedTask(c *fiber.Ctx) error {
 	})
 }
 
+// HeartbeatTask extends the lease of the job a worker is processing, workers
+// call it well within the task lease while they are still busy.
+func (h *Handler) HeartbeatTask(c *fiber.Ctx) error {
+
+	type HeartbeatTaskRequest struct {
+		WorkflowID string `json:"workflow_id"`
+		Task       string `json:"task"`
+	}
+
+	var req HeartbeatTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	leaseExpireDate, err := h.noNoodleCore.HeartbeatTask(req.WorkflowID, req.Task)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, api.ErrTaskNotFound):
+			statusCode = fiber.StatusNotFound
+		case errors.Is(err, api.ErrTaskNotActive), errors.Is(err, api.ErrLeaseExpired):
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to extend task lease",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   fiber.Map{"lease_expire_date": leaseExpireDate},
+	})
+}
+
 func (h *Handler) SubscribeTask(c *fiber.Ctx) error {
 
 	type SubscribeRequest struct {
@@ -127,6 +185,8 @@ func (h *Handler) SubscribeTask(c *fiber.Ctx) error {
 		Task           string `json:"task"`
 		HealthCheckURL string `json:"health_check_url"`
 		CallbackURL    string `json:"callback_url"`
+		// HealthCheck is optional, settings left out take the server defaults
+		HealthCheck entitites.HealthCheckConfig `json:"health_check"`
 	}
 
 	var req SubscribeRequest
@@ -153,19 +213,27 @@ func (h *Handler) SubscribeTask(c *fiber.Ctx) error {
 
 	fmt.Println("get Req ", req)
 
-	err := h.noNoodleCore.SubscriberHealthCheck(req.HealthCheckURL)
+	err := h.noNoodleCore.SubscriberHealthCheck(req.HealthCheckURL, req.HealthCheck)
 	if err != nil {
 		fmt.Println("GET SUBSCRIBE ERROR ", err)
+		message := "Subscriber health check failed"
+		if errors.Is(err, api.ErrInvalidHealthCheck) {
+			message = "Invalid health check"
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
-			"error":   "Subscriber health check failed",
+			"error":   message,
 			"details": err.Error(),
 		})
 	}
 
-	sessionKey, err := h.noNoodleCore.SubscribeTask(req.ProcessID, req.Task, req.HealthCheckURL, req.CallbackURL)
+	sessionKey, signingSecret, err := h.noNoodleCore.SubscribeTask(req.ProcessID, req.Task, req.HealthCheckURL, req.CallbackURL, req.HealthCheck)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		statusCode := fiber.StatusInternalServerError
+		if errors.Is(err, api.ErrInvalidHealthCheck) {
+			statusCode = fiber.StatusBadRequest
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
 			"status":  "error",
 			"error":   "Failed to subscribe to task",
 			"details": err.Error(),
@@ -174,5 +242,7 @@ func (h *Handler) SubscribeTask(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"connection_key": sessionKey,
+		// Verifies the signed deliveries, it is not handed out again
+		"signing_secret": signingSecret,
 	})
 }
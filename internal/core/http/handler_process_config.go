@@ -0,0 +1,183 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ListProcessConfigs(c *fiber.Ctx) error {
+
+	processConfigs, err := h.noNoodleCore.ListProcessConfigs()
+	if err != nil {
+		return processConfigError(c, err, "Failed to list process configs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   processConfigs,
+	})
+}
+
+func (h *Handler) GetProcessConfig(c *fiber.Ctx) error {
+
+	processConfig, err := h.noNoodleCore.GetProcessConfig(c.Params("process_id"))
+	if err != nil {
+		return processConfigError(c, err, "Failed to get process config")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   processConfig,
+	})
+}
+
+func (h *Handler) ListProcessConfigVersions(c *fiber.Ctx) error {
+
+	processConfigs, err := h.noNoodleCore.ListProcessConfigVersions(c.Params("process_id"))
+	if err != nil {
+		return processConfigError(c, err, "Failed to list process config versions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   processConfigs,
+	})
+}
+
+func (h *Handler) GetProcessConfigVersion(c *fiber.Ctx) error {
+
+	version, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid version",
+		})
+	}
+
+	processConfig, err := h.noNoodleCore.GetProcessConfigVersion(c.Params("process_id"), version)
+	if err != nil {
+		return processConfigError(c, err, "Failed to get process config")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   processConfig,
+	})
+}
+
+func (h *Handler) DeleteProcessConfig(c *fiber.Ctx) error {
+
+	version, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid version",
+		})
+	}
+
+	err = h.noNoodleCore.DeleteProcessConfig(c.Params("process_id"), version)
+	if err != nil {
+		return processConfigError(c, err, "Failed to delete process config")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   "Process config deleted successfully",
+	})
+}
+
+func (h *Handler) DisableProcess(c *fiber.Ctx) error {
+
+	err := h.noNoodleCore.SetProcessEnabled(c.Params("process_id"), false)
+	if err != nil {
+		return processConfigError(c, err, "Failed to disable process")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   "Process disabled successfully",
+	})
+}
+
+func (h *Handler) EnableProcess(c *fiber.Ctx) error {
+
+	err := h.noNoodleCore.SetProcessEnabled(c.Params("process_id"), true)
+	if err != nil {
+		return processConfigError(c, err, "Failed to enable process")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   "Process enabled successfully",
+	})
+}
+
+func (h *Handler) SetRetentionPolicy(c *fiber.Ctx) error {
+
+	type SetRetentionPolicyRequest struct {
+		CompletedRetentionDays int   `json:"completed_retention_days"`
+		FailedRetentionDays    int   `json:"failed_retention_days"`
+		Archive                *bool `json:"archive"`
+	}
+
+	var req SetRetentionPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	policy := entitites.RetentionPolicy{
+		ProcessID:              c.Params("process_id"),
+		CompletedRetentionDays: req.CompletedRetentionDays,
+		FailedRetentionDays:    req.FailedRetentionDays,
+		Archive:                req.Archive == nil || *req.Archive,
+	}
+
+	err := h.noNoodleCore.SetRetentionPolicy(policy)
+	if err != nil {
+		return processConfigError(c, err, "Failed to set retention policy")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   policy,
+	})
+}
+
+func (h *Handler) ListRetentionPolicies(c *fiber.Ctx) error {
+
+	policies, err := h.noNoodleCore.ListRetentionPolicies()
+	if err != nil {
+		return processConfigError(c, err, "Failed to list retention policies")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   policies,
+	})
+}
+
+func processConfigError(c *fiber.Ctx, err error, message string) error {
+	statusCode := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, api.ErrProcessNotFound), errors.Is(err, api.ErrProcessConfigNotFound):
+		statusCode = fiber.StatusNotFound
+	case errors.Is(err, api.ErrProcessConfigIsInUse):
+		statusCode = fiber.StatusConflict
+	case errors.Is(err, api.ErrInvalidRetentionPolicy):
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"status":  "error",
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) ListQueues(c *fiber.Ctx) error {
+
+	queues, err := h.noNoodleCore.ListQueues()
+	if err != nil {
+		return queueError(c, err, "Failed to list queues")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   queues,
+	})
+}
+
+func (h *Handler) GetQueueStats(c *fiber.Ctx) error {
+
+	stats, err := h.noNoodleCore.GetQueueStats(c.Params("process_id"), c.Params("task"))
+	if err != nil {
+		return queueError(c, err, "Failed to get queue")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   stats,
+	})
+}
+
+// ListQueueJobs returns the jobs of a queue, at most ?limit= of them (default 100, 0 for all)
+func (h *Handler) ListQueueJobs(c *fiber.Ctx) error {
+
+	jobs, err := h.noNoodleCore.ListQueueJobs(c.Params("process_id"), c.Params("task"), c.QueryInt("limit", 100))
+	if err != nil {
+		return queueError(c, err, "Failed to list queue jobs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   jobs,
+	})
+}
+
+func (h *Handler) GetQueueJob(c *fiber.Ctx) error {
+
+	job, err := h.noNoodleCore.GetQueueJob(c.Params("process_id"), c.Params("task"), c.Params("id"))
+	if err != nil {
+		return queueError(c, err, "Failed to get queue job")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   job,
+	})
+}
+
+func (h *Handler) DeleteQueueJob(c *fiber.Ctx) error {
+
+	err := h.noNoodleCore.DeleteQueueJob(c.Params("process_id"), c.Params("task"), c.Params("id"))
+	if err != nil {
+		return queueError(c, err, "Failed to delete queue job")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+	})
+}
+
+func (h *Handler) PurgeQueue(c *fiber.Ctx) error {
+
+	purged, err := h.noNoodleCore.PurgeQueue(c.Params("process_id"), c.Params("task"))
+	if err != nil {
+		return queueError(c, err, "Failed to purge queue")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"purged": purged,
+		},
+	})
+}
+
+// RequeueQueueJobs releases reserved jobs, those listed in ids or all of them
+func (h *Handler) RequeueQueueJobs(c *fiber.Ctx) error {
+
+	type RequeueQueueJobsRequest struct {
+		IDs []string `json:"ids"`
+	}
+
+	var req RequeueQueueJobsRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status": "error",
+				"error":  "Invalid request body",
+			})
+		}
+	}
+
+	requeued, err := h.noNoodleCore.RequeueQueueJobs(c.Params("process_id"), c.Params("task"), req.IDs)
+	if err != nil {
+		return queueError(c, err, "Failed to requeue queue jobs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"requeued": requeued,
+		},
+	})
+}
+
+// MoveQueueJobs moves ready and delayed jobs to the queue of another task of
+// the same process, those listed in ids or all of them. to_process_id defaults
+// to the process of the source queue.
+func (h *Handler) MoveQueueJobs(c *fiber.Ctx) error {
+
+	type MoveQueueJobsRequest struct {
+		ToProcessID string   `json:"to_process_id"`
+		ToTask      string   `json:"to_task"`
+		IDs         []string `json:"ids"`
+	}
+
+	var req MoveQueueJobsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status": "error",
+			"error":  "Invalid request body",
+		})
+	}
+
+	if req.ToProcessID == "" {
+		req.ToProcessID = c.Params("process_id")
+	}
+
+	moved, err := h.noNoodleCore.MoveQueueJobs(c.Params("process_id"), c.Params("task"), req.ToProcessID, req.ToTask, req.IDs)
+	if err != nil {
+		return queueError(c, err, "Failed to move queue jobs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"moved": moved,
+		},
+	})
+}
+
+func queueError(c *fiber.Ctx, err error, message string) error {
+	statusCode := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, api.ErrJobNotFound):
+		statusCode = fiber.StatusNotFound
+	case errors.Is(err, api.ErrInvalidQueueMove):
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"status":  "error",
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+)
+
+// ListSubscriptions lists every stored subscription with its health, live
+// state is only reported for subscriptions supervised by this instance.
+func (h *Handler) ListSubscriptions(c *fiber.Ctx) error {
+
+	subscriptions, err := h.noNoodleCore.ListSubscriptions()
+	if err != nil {
+		return subscriptionError(c, err, "Failed to list subscriptions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   subscriptions,
+	})
+}
+
+// ListSubscriptionStatuses reports the live state of every subscription
+// supervised by this instance: its health checks and its delivery circuit.
+func (h *Handler) ListSubscriptionStatuses(c *fiber.Ctx) error {
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   h.noNoodleCore.ListSubscriptionStatuses(),
+	})
+}
+
+// Unsubscribe removes the subscription of the connection key returned by
+// /subscribe.
+func (h *Handler) Unsubscribe(c *fiber.Ctx) error {
+
+	if err := h.noNoodleCore.UnsubscribeTask(c.Params("session_key")); err != nil {
+		return subscriptionError(c, err, "Failed to unsubscribe")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+	})
+}
+
+// RenewSubscription lets a worker vouch for its own health in place of the
+// next health check.
+func (h *Handler) RenewSubscription(c *fiber.Ctx) error {
+
+	subscription, err := h.noNoodleCore.RenewSubscription(c.Params("session_key"))
+	if err != nil {
+		return subscriptionError(c, err, "Failed to renew subscription")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   subscription,
+	})
+}
+
+func subscriptionError(c *fiber.Ctx, err error, message string) error {
+	statusCode := fiber.StatusInternalServerError
+	if errors.Is(err, api.ErrSubscriptionNotFound) {
+		statusCode = fiber.StatusNotFound
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"status":  "error",
+		"error":   message,
+		"details": err.Error(),
+	})
+}
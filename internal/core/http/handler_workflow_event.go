@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// workflowEventKeepalive keeps proxies from closing an idle event stream
+const workflowEventKeepalive = 15 * time.Second
+
+// ListWorkflowEvents returns a page of the event history in event ID order,
+// the next page starts after the event_id of the last event. Events show up
+// about a second after they are recorded, once no earlier event can commit.
+func (h *Handler) ListWorkflowEvents(c *fiber.Ctx) error {
+
+	afterEventID, err := strconv.ParseInt(c.Query("after_event_id", "0"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Invalid after_event_id",
+			"details": err.Error(),
+		})
+	}
+
+	events, err := h.noNoodleCore.ListWorkflowEvents(entitites.WorkflowEventQuery{
+		WorkflowID:   c.Query("workflow_id"),
+		ProcessID:    c.Query("process_id"),
+		AfterEventID: afterEventID,
+		Limit:        c.QueryInt("limit"),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Failed to list workflow events",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   events,
+	})
+}
+
+// StreamWorkflowEvents streams workflow events as Server-Sent Events, narrowed
+// by the workflow_id and process_id query parameters. A reconnecting client
+// resumes after its Last-Event-ID header, or the last_event_id query
+// parameter. Without one, a single workflow is replayed from its start and
+// any other stream starts with the next recorded event.
+func (h *Handler) StreamWorkflowEvents(c *fiber.Ctx) error {
+
+	query := entitites.WorkflowEventQuery{
+		WorkflowID: c.Query("workflow_id"),
+		ProcessID:  c.Query("process_id"),
+	}
+
+	lastEventID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	if lastEventID != "" {
+		var err error
+		query.AfterEventID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"error":   "Invalid Last-Event-ID",
+				"details": err.Error(),
+			})
+		}
+	} else if query.WorkflowID == "" {
+		var err error
+		query.AfterEventID, err = h.noNoodleCore.LatestWorkflowEventID()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  "error",
+				"error":   "Failed to open workflow event stream",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// Closed when the server shuts down, an open stream would otherwise hold it
+	shutdown := c.Context().Done()
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var writeMu sync.Mutex
+		write := func(frame string) error {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+
+			if _, err := w.WriteString(frame); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		// Sends the headers right away, a client gone meanwhile is noticed on
+		// the next write
+		if err := write(": connected\n\n"); err != nil {
+			return
+		}
+
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+
+			ticker := time.NewTicker(workflowEventKeepalive)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-shutdown:
+					cancel()
+					return
+				case <-ticker.C:
+					if err := write(": keepalive\n\n"); err != nil {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		err := h.noNoodleCore.WatchWorkflowEvents(ctx, query, func(event entitites.WorkflowEvent) error {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			return write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.EventID, event.Type, data))
+		})
+		if err != nil && ctx.Err() == nil {
+			fmt.Println("Workflow event stream stopped:", err)
+		}
+
+		cancel()
+		<-stopped
+	})
+
+	return nil
+}
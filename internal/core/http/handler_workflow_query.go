@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetWorkflow(c *fiber.Ctx) error {
+
+	workflow, err := h.noNoodleCore.GetWorkflow(c.Params("workflow_id"))
+	if err != nil {
+		if errors.Is(err, api.ErrWorkflowNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"status": "error",
+				"error":  "Workflow not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Failed to get workflow",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   workflow,
+	})
+}
+
+// SearchWorkflows lists workflows matching the query string filters.
+// Dates are RFC3339, paging continues with the returned next_cursor.
+func (h *Handler) SearchWorkflows(c *fiber.Ctx) error {
+
+	query := entitites.WorkflowQuery{
+		ProcessID:   c.Query("process_id"),
+		Status:      c.Query("status"),
+		Task:        c.Query("task"),
+		TaskStatus:  c.Query("task_status"),
+		BusinessKey: c.Query("business_key"),
+		SortBy:      c.Query("sort_by"),
+		SortOrder:   c.Query("order"),
+		Limit:       c.QueryInt("limit"),
+		Cursor:      c.Query("cursor"),
+	}
+
+	var err error
+	query.CreatedFrom, err = parseQueryTime(c, "created_from")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Invalid created_from",
+			"details": err.Error(),
+		})
+	}
+	query.CreatedTo, err = parseQueryTime(c, "created_to")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Invalid created_to",
+			"details": err.Error(),
+		})
+	}
+
+	page, err := h.noNoodleCore.SearchWorkflows(query)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if errors.Is(err, api.ErrInvalidWorkflowQuery) {
+			statusCode = fiber.StatusBadRequest
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"status":  "error",
+			"error":   "Failed to search workflows",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data":   page,
+	})
+}
+
+func parseQueryTime(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
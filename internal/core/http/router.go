@@ -5,6 +5,7 @@ import (
 
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 )
@@ -40,7 +41,51 @@ func NewHTTPRouter(noNoodleCore api.NoNoodleCoreInterface) *fiber.App {
 	app.Post("/create_workflow", h.CreateWorkflow)
 	app.Post("/deploy_process_config", h.DeployProcessConfig)
 	app.Post("/failed_task", h.FailedTask)
+	app.Post("/heartbeat_task", h.HeartbeatTask)
 	app.Post("/subscribe", h.SubscribeTask)
+	app.Get("/subscriptions", h.ListSubscriptions)
+	app.Get("/subscriptions/live", h.ListSubscriptionStatuses)
+	app.Delete("/subscriptions/:session_key", h.Unsubscribe)
+	app.Post("/subscriptions/:session_key/renew", h.RenewSubscription)
+
+	app.Post("/fetch_and_lock", h.FetchAndLock)
+	app.Post("/complete_job", h.CompleteJob)
+	app.Post("/failed_job", h.FailedJob)
+	app.Post("/extend_job_lock", h.ExtendJobLock)
+	app.Get("/job_stream", h.OpenJobStream, websocket.New(h.JobStream))
+
+	app.Get("/workflow/:workflow_id", h.GetWorkflow)
+	app.Get("/workflows", h.SearchWorkflows)
+	app.Get("/workflow_events", h.ListWorkflowEvents)
+	app.Get("/events", h.StreamWorkflowEvents)
+
+	app.Get("/process_configs", h.ListProcessConfigs)
+	app.Get("/process_config/:process_id", h.GetProcessConfig)
+	app.Get("/process_config/:process_id/versions", h.ListProcessConfigVersions)
+	app.Get("/process_config/:process_id/version/:version", h.GetProcessConfigVersion)
+	app.Delete("/process_config/:process_id/version/:version", h.DeleteProcessConfig)
+	app.Post("/process_config/:process_id/disable", h.DisableProcess)
+	app.Post("/process_config/:process_id/enable", h.EnableProcess)
+	app.Put("/process_config/:process_id/retention", h.SetRetentionPolicy)
+	app.Get("/retention_policies", h.ListRetentionPolicies)
+
+	app.Get("/dead_letters/:process_id/:task", h.ListDeadLetters)
+	app.Delete("/dead_letters/:process_id/:task", h.PurgeDeadLetters)
+	app.Get("/dead_letters/:process_id/:task/:id", h.GetDeadLetter)
+	app.Delete("/dead_letters/:process_id/:task/:id", h.DeleteDeadLetter)
+	app.Post("/dead_letters/:process_id/:task/:id/replay", h.ReplayDeadLetter)
+
+	app.Get("/queues", h.ListQueues)
+	app.Get("/queues/:process_id/:task", h.GetQueueStats)
+	app.Delete("/queues/:process_id/:task", h.PurgeQueue)
+	app.Get("/queues/:process_id/:task/jobs", h.ListQueueJobs)
+	app.Get("/queues/:process_id/:task/jobs/:id", h.GetQueueJob)
+	app.Delete("/queues/:process_id/:task/jobs/:id", h.DeleteQueueJob)
+	app.Post("/queues/:process_id/:task/requeue", h.RequeueQueueJobs)
+	app.Post("/queues/:process_id/:task/move", h.MoveQueueJobs)
+
+	app.Get("/circuit_breakers", h.ListCircuitBreakers)
+	app.Get("/cluster/instances", h.ListClusterInstances)
 
 	return app
 
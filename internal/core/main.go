@@ -10,43 +10,146 @@ import (
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/service"
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+	"github.com/redis/go-redis/v9"
 )
 
+func newRepository(cfg *config.Config) (repository.NoNoodleWorkflowRepository, error) {
+
+	switch cfg.RepositoryConfig.Backend {
+	case config.REPOSITORY_BACKEND_SQLITE:
+		sqliteDB, err := util.NewSQLite(
+			cfg.SQLiteRepoConfig.Path,
+			cfg.SQLiteRepoConfig.BusyTimeoutMs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewSQLiteNoNoodleWorkflow(sqliteDB)
+	case config.REPOSITORY_BACKEND_POSTGRESQL:
+		pgDB, err := util.NewPostgresql(
+			cfg.PostgresqlRepoConfig.Host,
+			cfg.PostgresqlRepoConfig.Port,
+			cfg.PostgresqlRepoConfig.User,
+			cfg.PostgresqlRepoConfig.Password,
+			cfg.PostgresqlRepoConfig.Dbname,
+			cfg.PostgresqlRepoConfig.SSLMode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewPostgreSQLNoNoodleWorkflow(pgDB), nil
+	default:
+		return nil, fmt.Errorf("unknown repository backend: %s", cfg.RepositoryConfig.Backend)
+	}
+}
+
+func newRedisClient(cfg config.RedisMessageBrokerConfig) (redis.UniversalClient, error) {
+
+	opts := &redis.UniversalOptions{
+		Addrs:         cfg.Addrs,
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		DB:            cfg.DB,
+		IsClusterMode: cfg.ClusterMode,
+	}
+
+	if cfg.SentinelMasterName != "" {
+		opts.Addrs = cfg.SentinelAddrs
+		opts.MasterName = cfg.SentinelMasterName
+		opts.SentinelUsername = cfg.SentinelUsername
+		opts.SentinelPassword = cfg.SentinelPassword
+	}
+
+	if cfg.TLSEnabled {
+		tlsConfig, err := util.NewRedisTLSConfig(
+			cfg.TLSCAFile,
+			cfg.TLSCertFile,
+			cfg.TLSKeyFile,
+			cfg.TLSServerName,
+			cfg.TLSInsecureSkipVerify,
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	return util.NewRedis(opts)
+}
+
+func newMessageBroker(cfg *config.Config, repo repository.NoNoodleWorkflowRepository) (msgbroker.MessageBroker, error) {
+
+	switch cfg.MessageBrokerConfig.Type {
+	case config.MESSAGE_BROKER_MEMORY:
+		return msgbroker.NewMemoryMessageBroker(), nil
+	case config.MESSAGE_BROKER_REDIS:
+		client, err := newRedisClient(cfg.RedisMessageBrokerConfig)
+		if err != nil {
+			return nil, err
+		}
+		return msgbroker.NewRedisMessageBroker(client)
+	case config.MESSAGE_BROKER_REDIS_STREAMS:
+		client, err := newRedisClient(cfg.RedisMessageBrokerConfig)
+		if err != nil {
+			return nil, err
+		}
+		return msgbroker.NewRedisStreamMessageBroker(client), nil
+	case config.MESSAGE_BROKER_POSTGRESQL:
+		if cfg.RepositoryConfig.Backend != config.REPOSITORY_BACKEND_POSTGRESQL {
+			return nil, fmt.Errorf("message broker %s requires repository backend %s", cfg.MessageBrokerConfig.Type, config.REPOSITORY_BACKEND_POSTGRESQL)
+		}
+		return msgbroker.NewPostgreSQLMessageBroker(repo.GetDB(), cfg.PostgresqlBrokerConfig.PollInterval), nil
+	default:
+		return nil, fmt.Errorf("unknown message broker: %s", cfg.MessageBrokerConfig.Type)
+	}
+}
+
 func main() {
 
 	config := config.GetConfig()
 
-	pgDB, err := util.NewPostgresql(
-		config.PostgresqlRepoConfig.Host,
-		config.PostgresqlRepoConfig.Port,
-		config.PostgresqlRepoConfig.User,
-		config.PostgresqlRepoConfig.Password,
-		config.PostgresqlRepoConfig.Dbname,
-		config.PostgresqlRepoConfig.SSLMode,
-	)
+	repo, err := newRepository(config)
 	if err != nil {
-		fmt.Println("Error connecting to PostgreSQL:", err)
+		fmt.Println("Error connecting to repository:", err)
 		return
 	}
 
-	repo := repository.NewPostgreSQLNoNoodleWorkflow(pgDB)
-
-	redisBroker, err := msgbroker.NewRedisMessageBroker(
-		config.RedisMessageBrokerConfig.Addr, config.RedisMessageBrokerConfig.Password, config.RedisMessageBrokerConfig.DB,
-	)
+	broker, err := newMessageBroker(config, repo)
 	if err != nil {
 		panic(err)
 	}
-	defer redisBroker.Close()
-
-	msgService := api.NewRedisMessageService(redisBroker)
-
-	noNoodleCoreService := api.NewNoNoodleWorkflowCorePostgresql(repo, msgService)
+	defer broker.Close()
+
+	msgService := api.NewMessageService(broker, config.MessageBrokerConfig.MaxDeliveryAttempts, config.MessageBrokerConfig.DefaultTaskLease, api.DeliveryPolicy{
+		Timeout:          config.DeliveryConfig.Timeout,
+		BackoffBase:      config.DeliveryConfig.BackoffBase,
+		BackoffMax:       config.DeliveryConfig.BackoffMax,
+		RetryAfterMax:    config.DeliveryConfig.RetryAfterMax,
+		BreakerThreshold: config.DeliveryConfig.CircuitBreakerThreshold,
+		BreakerCooldown:  config.DeliveryConfig.CircuitBreakerCooldown,
+	})
+
+	noNoodleCoreService := api.NewNoNoodleWorkflowCorePostgresql(repo, msgService, api.ClusterPolicy{
+		InstanceID:        config.ClusterConfig.InstanceID,
+		LeaseDuration:     config.ClusterConfig.LeaseDuration,
+		HeartbeatInterval: config.ClusterConfig.HeartbeatInterval,
+	})
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	err = service.New(noNoodleCoreService).Run(ctx)
+	backgroundJobs := []service.BackgroundJob{service.BackgroundJobFunc(noNoodleCoreService.RunCluster)}
+	if config.RetentionConfig.Enabled {
+		backgroundJobs = append(backgroundJobs, api.NewRetentionJob(
+			repo,
+			config.ClusterConfig.InstanceID,
+			config.RetentionConfig.ArchiveDir,
+			config.RetentionConfig.Interval,
+			config.RetentionConfig.BatchSize,
+		))
+	}
+
+	err = service.New(noNoodleCoreService, backgroundJobs...).Run(ctx)
 
 	if err != nil {
 		panic(err)
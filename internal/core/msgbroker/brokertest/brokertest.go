@@ -0,0 +1,529 @@
+// Package brokertest is the conformance suite every msgbroker.MessageBroker
+// implementation must pass. Call Run from the implementation's tests.
+package brokertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+)
+
+// Run executes the suite against brokers returned by newBroker. Every subtest
+// uses its own queue name, so a broker backed by a shared server is fine.
+func Run(t *testing.T, newBroker func(t *testing.T) msgbroker.MessageBroker) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, broker msgbroker.MessageBroker, queue string)
+	}{
+		{"EnqueueReserveAck", testEnqueueReserveAck},
+		{"ReserveInOrder", testReserveInOrder},
+		{"ReserveBlocksUntilEnqueue", testReserveBlocksUntilEnqueue},
+		{"ReserveHonoursContext", testReserveHonoursContext},
+		{"ReservedMessageIsHidden", testReservedMessageIsHidden},
+		{"NackRedeliversImmediately", testNackRedeliversImmediately},
+		{"RequeueExpired", testRequeueExpired},
+		{"ExtendVisibility", testExtendVisibility},
+		{"QueuesAreIsolated", testQueuesAreIsolated},
+		{"AttemptsCountRedeliveries", testAttemptsCountRedeliveries},
+		{"ReleaseTakesBackAttempt", testReleaseTakesBackAttempt},
+		{"DeadLetter", testDeadLetter},
+		{"IdenticalPayloadsAreDistinct", testIdenticalPayloadsAreDistinct},
+		{"EnqueueAtDelaysDelivery", testEnqueueAtDelaysDelivery},
+		{"StatsAndListMessages", testStatsAndListMessages},
+		{"DeleteAndPurge", testDeleteAndPurge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newBroker(t)
+			t.Cleanup(func() { broker.Close() })
+			queue := fmt.Sprintf("brokertest:%s:%s", tt.name, uuid.NewString())
+			tt.fn(t, broker, queue)
+		})
+	}
+}
+
+func reserve(t *testing.T, broker msgbroker.MessageBroker, queue string, visibilityTimeout time.Duration) *msgbroker.Envelope {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	envelope, err := broker.Reserve(ctx, queue, visibilityTimeout)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	return envelope
+}
+
+func expectEmpty(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	envelope, err := broker.Reserve(ctx, queue, time.Minute)
+	if err == nil {
+		t.Fatalf("expected empty queue, reserved %q", envelope.Payload)
+	}
+}
+
+func testEnqueueReserveAck(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	if err := broker.Enqueue(ctx, queue, []byte("hello")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	envelope := reserve(t, broker, queue, time.Minute)
+	if string(envelope.Payload) != "hello" {
+		t.Fatalf("reserved %q, want %q", envelope.Payload, "hello")
+	}
+	if envelope.ID == "" || envelope.EnqueuedAt.IsZero() {
+		t.Fatalf("reserved envelope without ID or enqueue time: %+v", envelope)
+	}
+	if err := broker.Ack(ctx, queue, envelope.ID); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if err := broker.RequeueExpired(ctx, queue); err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	expectEmpty(t, broker, queue)
+}
+
+func testReserveInOrder(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := broker.Enqueue(ctx, queue, []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		envelope := reserve(t, broker, queue, time.Minute)
+		if string(envelope.Payload) != fmt.Sprint(i) {
+			t.Fatalf("reserved %q, want %q", envelope.Payload, fmt.Sprint(i))
+		}
+		broker.Ack(ctx, queue, envelope.ID)
+	}
+}
+
+func testReserveBlocksUntilEnqueue(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	reserved := make(chan []byte, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		envelope, err := broker.Reserve(ctx, queue, time.Minute)
+		if err != nil {
+			reserved <- nil
+			return
+		}
+		reserved <- envelope.Payload
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if err := broker.Enqueue(context.Background(), queue, []byte("late")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	select {
+	case message := <-reserved:
+		if string(message) != "late" {
+			t.Fatalf("reserved %q, want %q", message, "late")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reserve did not return after Enqueue")
+	}
+}
+
+func testReserveHonoursContext(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := broker.Reserve(ctx, queue, time.Minute)
+	if err == nil {
+		t.Fatal("Reserve on an empty queue returned without error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		t.Fatalf("Reserve returned %v before the context was done", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Fatalf("Reserve took %s to observe the context", time.Since(start))
+	}
+}
+
+func testReservedMessageIsHidden(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("only"))
+	reserve(t, broker, queue, time.Minute)
+
+	if err := broker.RequeueExpired(ctx, queue); err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	expectEmpty(t, broker, queue)
+}
+
+func testNackRedeliversImmediately(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("first"))
+	broker.Enqueue(ctx, queue, []byte("second"))
+
+	envelope := reserve(t, broker, queue, time.Minute)
+	if err := broker.Nack(ctx, queue, envelope.ID); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+
+	redelivered := reserve(t, broker, queue, time.Minute)
+	if string(redelivered.Payload) != "first" || redelivered.ID != envelope.ID {
+		t.Fatalf("reserved %q after Nack, want %q", redelivered.Payload, "first")
+	}
+}
+
+func testRequeueExpired(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("slow"))
+
+	// Brokers may track deadlines with one second resolution
+	reserve(t, broker, queue, 2*time.Second)
+	if err := broker.RequeueExpired(ctx, queue); err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	expectEmpty(t, broker, queue)
+
+	time.Sleep(3 * time.Second)
+	if err := broker.RequeueExpired(ctx, queue); err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	envelope := reserve(t, broker, queue, time.Minute)
+	if string(envelope.Payload) != "slow" {
+		t.Fatalf("reserved %q after expiry, want %q", envelope.Payload, "slow")
+	}
+}
+
+func testExtendVisibility(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("extended"))
+	broker.Enqueue(ctx, queue, []byte("expired"))
+
+	extended := reserve(t, broker, queue, 2*time.Second)
+	expired := reserve(t, broker, queue, 2*time.Second)
+	ok, err := broker.Extend(ctx, queue, extended.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+	if !ok {
+		t.Fatalf("Extend of a reserved message reported false")
+	}
+
+	time.Sleep(3 * time.Second)
+	ok, err = broker.Extend(ctx, queue, expired.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+	if ok {
+		t.Fatalf("Extend of an expired reservation reported true")
+	}
+
+	if err := broker.RequeueExpired(ctx, queue); err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	envelope := reserve(t, broker, queue, time.Minute)
+	if string(envelope.Payload) != "expired" {
+		t.Fatalf("reserved %q after expiry, want %q", envelope.Payload, "expired")
+	}
+	expectEmpty(t, broker, queue)
+
+	if err := broker.Ack(ctx, queue, extended.ID); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	ok, err = broker.Extend(ctx, queue, extended.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+	if ok {
+		t.Fatalf("Extend of an acked message reported true")
+	}
+}
+
+func testQueuesAreIsolated(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue+":a", []byte("a"))
+
+	expectEmpty(t, broker, queue+":b")
+	envelope := reserve(t, broker, queue+":a", time.Minute)
+	if string(envelope.Payload) != "a" {
+		t.Fatalf("reserved %q, want %q", envelope.Payload, "a")
+	}
+}
+
+func testAttemptsCountRedeliveries(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("retry"))
+
+	envelope := reserve(t, broker, queue, time.Minute)
+	if envelope.Attempts != 1 {
+		t.Fatalf("first delivery has %d attempts, want 1", envelope.Attempts)
+	}
+	if err := broker.Nack(ctx, queue, envelope.ID); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+
+	envelope = reserve(t, broker, queue, time.Minute)
+	if envelope.Attempts != 2 {
+		t.Fatalf("redelivery has %d attempts, want 2", envelope.Attempts)
+	}
+}
+
+func testReleaseTakesBackAttempt(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("first"))
+	broker.Enqueue(ctx, queue, []byte("second"))
+
+	envelope := reserve(t, broker, queue, time.Minute)
+	if err := broker.Release(ctx, queue, envelope.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	released := reserve(t, broker, queue, time.Minute)
+	if string(released.Payload) != "first" || released.ID != envelope.ID {
+		t.Fatalf("reserved %q after Release, want %q", released.Payload, "first")
+	}
+	if released.Attempts != 1 {
+		t.Fatalf("released message has %d attempts, want 1", released.Attempts)
+	}
+
+	// Releasing a message no longer reserved takes nothing back
+	if err := broker.Ack(ctx, queue, released.ID); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if err := broker.Release(ctx, queue, released.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if envelope := reserve(t, broker, queue, time.Minute); string(envelope.Payload) != "second" || envelope.Attempts != 1 {
+		t.Fatalf("reserved %q with %d attempts, want %q with 1", envelope.Payload, envelope.Attempts, "second")
+	}
+	expectEmpty(t, broker, queue)
+}
+
+func testDeadLetter(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("poison"))
+
+	envelope := reserve(t, broker, queue, 2*time.Second)
+	if err := broker.DeadLetter(ctx, queue, envelope.ID, "boom"); err != nil {
+		t.Fatalf("DeadLetter: %v", err)
+	}
+
+	time.Sleep(3 * time.Second)
+	if err := broker.RequeueExpired(ctx, queue); err != nil {
+		t.Fatalf("RequeueExpired: %v", err)
+	}
+	expectEmpty(t, broker, queue)
+
+	deadLetters, err := broker.ListDeadLetters(ctx, queue)
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("listed %d dead letters, want 1", len(deadLetters))
+	}
+	deadLetter := deadLetters[0]
+	if string(deadLetter.Payload) != "poison" || deadLetter.Attempts != 1 || deadLetter.LastError != "boom" || deadLetter.ID != envelope.ID {
+		t.Fatalf("unexpected dead letter %+v", deadLetter)
+	}
+
+	deleted, err := broker.DeleteDeadLetter(ctx, queue, deadLetter.ID)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteDeadLetter = %v, %v, want true", deleted, err)
+	}
+	deleted, err = broker.DeleteDeadLetter(ctx, queue, deadLetter.ID)
+	if err != nil || deleted {
+		t.Fatalf("second DeleteDeadLetter = %v, %v, want false", deleted, err)
+	}
+
+	for _, message := range []string{"a", "b"} {
+		broker.Enqueue(ctx, queue, []byte(message))
+		envelope := reserve(t, broker, queue, time.Minute)
+		broker.DeadLetter(ctx, queue, envelope.ID, "boom")
+	}
+	purged, err := broker.PurgeDeadLetters(ctx, queue)
+	if err != nil || purged != 2 {
+		t.Fatalf("PurgeDeadLetters = %d, %v, want 2", purged, err)
+	}
+	deadLetters, err = broker.ListDeadLetters(ctx, queue)
+	if err != nil || len(deadLetters) != 0 {
+		t.Fatalf("listed %d dead letters after purge, %v", len(deadLetters), err)
+	}
+}
+
+func testIdenticalPayloadsAreDistinct(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("same"))
+	broker.Enqueue(ctx, queue, []byte("same"))
+
+	first := reserve(t, broker, queue, time.Minute)
+	second := reserve(t, broker, queue, time.Minute)
+	if first.ID == second.ID {
+		t.Fatalf("identical payloads share the ID %q", first.ID)
+	}
+
+	// Acking the second delivery must not touch the reservation of the first
+	if err := broker.Ack(ctx, queue, second.ID); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if err := broker.Nack(ctx, queue, first.ID); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+
+	redelivered := reserve(t, broker, queue, time.Minute)
+	if redelivered.ID != first.ID || redelivered.Attempts != 2 {
+		t.Fatalf("reserved %s attempt %d, want %s attempt 2", redelivered.ID, redelivered.Attempts, first.ID)
+	}
+	broker.Ack(ctx, queue, redelivered.ID)
+	expectEmpty(t, broker, queue)
+}
+
+func testEnqueueAtDelaysDelivery(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	if err := broker.EnqueueAt(ctx, queue, []byte("later"), time.Now().Add(2*time.Second)); err != nil {
+		t.Fatalf("EnqueueAt: %v", err)
+	}
+	if err := broker.EnqueueAt(ctx, queue, []byte("past"), time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("EnqueueAt: %v", err)
+	}
+
+	envelope := reserve(t, broker, queue, time.Minute)
+	if string(envelope.Payload) != "past" {
+		t.Fatalf("reserved %q, want the message due in the past", envelope.Payload)
+	}
+	expectEmpty(t, broker, queue)
+
+	// A Reserve waiting on the empty queue picks the message up once it is due
+	start := time.Now()
+	envelope = reserve(t, broker, queue, time.Minute)
+	if string(envelope.Payload) != "later" {
+		t.Fatalf("reserved %q, want %q", envelope.Payload, "later")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("delayed message delivered after %v, before it was due", elapsed)
+	}
+}
+
+func testStatsAndListMessages(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	for _, message := range []string{"expired", "held", "dead", "first", "second"} {
+		broker.Enqueue(ctx, queue, []byte(message))
+	}
+	broker.EnqueueAt(ctx, queue, []byte("later"), time.Now().Add(time.Hour))
+
+	expired := reserve(t, broker, queue, time.Second)
+	held := reserve(t, broker, queue, time.Minute)
+	dead := reserve(t, broker, queue, time.Minute)
+	broker.DeadLetter(ctx, queue, dead.ID, "boom")
+	time.Sleep(1500 * time.Millisecond)
+
+	stats, err := broker.Stats(ctx, queue)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	want := msgbroker.QueueStats{Ready: 2, Delayed: 1, Reserved: 2, InFlight: 1, Dead: 1}
+	if stats.Ready != want.Ready || stats.Delayed != want.Delayed || stats.Reserved != want.Reserved || stats.InFlight != want.InFlight || stats.Dead != want.Dead {
+		t.Fatalf("Stats = %+v, want %+v", stats, want)
+	}
+	if stats.OldestEnqueuedAt == nil || time.Since(*stats.OldestEnqueuedAt) > time.Minute {
+		t.Fatalf("oldest enqueue time %v, want a recent one", stats.OldestEnqueuedAt)
+	}
+
+	messages, err := broker.ListMessages(ctx, queue, 0)
+	if err != nil {
+		t.Fatalf("ListMessages: %v", err)
+	}
+	states := map[string]string{}
+	for _, message := range messages {
+		states[string(message.Payload)] = message.State
+		if message.State != msgbroker.MessageStateReady && message.VisibleAt == nil {
+			t.Fatalf("%s message %q has no visibility time", message.State, message.Payload)
+		}
+	}
+	wantStates := map[string]string{
+		"expired": msgbroker.MessageStateReserved,
+		"held":    msgbroker.MessageStateReserved,
+		"first":   msgbroker.MessageStateReady,
+		"second":  msgbroker.MessageStateReady,
+		"later":   msgbroker.MessageStateDelayed,
+	}
+	if len(messages) != len(wantStates) {
+		t.Fatalf("listed %d messages, want %d: %v", len(messages), len(wantStates), states)
+	}
+	for payload, state := range wantStates {
+		if states[payload] != state {
+			t.Fatalf("message %q is %q, want %q", payload, states[payload], state)
+		}
+	}
+	if last := messages[len(messages)-1]; last.State != msgbroker.MessageStateDelayed {
+		t.Fatalf("listed %s message %q last, want the delayed one", last.State, last.Payload)
+	}
+	for _, message := range messages {
+		if message.ID == held.ID && message.Attempts != 1 {
+			t.Fatalf("held message has %d attempts, want 1", message.Attempts)
+		}
+		if message.ID == expired.ID && message.VisibleAt.After(time.Now()) {
+			t.Fatalf("expired message is visible at %v, want a time in the past", message.VisibleAt)
+		}
+	}
+
+	limited, err := broker.ListMessages(ctx, queue, 2)
+	if err != nil || len(limited) != 2 {
+		t.Fatalf("ListMessages with limit 2 = %d messages, %v", len(limited), err)
+	}
+}
+
+func testDeleteAndPurge(t *testing.T, broker msgbroker.MessageBroker, queue string) {
+	ctx := context.Background()
+	broker.Enqueue(ctx, queue, []byte("reserved"))
+	broker.Enqueue(ctx, queue, []byte("ready"))
+	broker.EnqueueAt(ctx, queue, []byte("later"), time.Now().Add(time.Hour))
+
+	reserved := reserve(t, broker, queue, time.Minute)
+	messages, err := broker.ListMessages(ctx, queue, 0)
+	if err != nil || len(messages) != 3 {
+		t.Fatalf("ListMessages = %d messages, %v, want 3", len(messages), err)
+	}
+
+	for _, message := range messages {
+		deleted, err := broker.Delete(ctx, queue, message.ID)
+		if err != nil || !deleted {
+			t.Fatalf("Delete %s message = %v, %v, want true", message.State, deleted, err)
+		}
+	}
+	deleted, err := broker.Delete(ctx, queue, reserved.ID)
+	if err != nil || deleted {
+		t.Fatalf("second Delete = %v, %v, want false", deleted, err)
+	}
+	if extended, _ := broker.Extend(ctx, queue, reserved.ID, time.Minute); extended {
+		t.Fatalf("extended a deleted message")
+	}
+	expectEmpty(t, broker, queue)
+
+	broker.Enqueue(ctx, queue, []byte("reserved"))
+	broker.Enqueue(ctx, queue, []byte("ready"))
+	broker.EnqueueAt(ctx, queue, []byte("later"), time.Now().Add(time.Hour))
+	reserve(t, broker, queue, time.Minute)
+
+	purged, err := broker.Purge(ctx, queue)
+	if err != nil || purged != 3 {
+		t.Fatalf("Purge = %d, %v, want 3", purged, err)
+	}
+	stats, err := broker.Stats(ctx, queue)
+	if err != nil || stats.Ready+stats.Delayed+stats.Reserved != 0 {
+		t.Fatalf("Stats after purge = %+v, %v", stats, err)
+	}
+
+	// The queue keeps working after a purge
+	broker.Enqueue(ctx, queue, []byte("after"))
+	if envelope := reserve(t, broker, queue, time.Minute); string(envelope.Payload) != "after" {
+		t.Fatalf("reserved %q after purge, want %q", envelope.Payload, "after")
+	}
+}
@@ -0,0 +1,419 @@
+package msgbroker
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryReservation struct {
+	Envelope
+	deadline time.Time
+}
+
+type memoryDelayed struct {
+	Envelope
+	deliverAt time.Time
+}
+
+type memoryQueue struct {
+	ready    []Envelope
+	reserved []memoryReservation
+	// delayed is sorted by deliverAt
+	delayed []memoryDelayed
+	dead    []DeadLetter
+}
+
+// MemoryMessageBroker is an in-process MessageBroker. Nothing survives a
+// restart, it is meant for tests and single process development setups.
+type MemoryMessageBroker struct {
+	mu     sync.Mutex
+	queues map[string]*memoryQueue
+	// notify is closed and replaced on every enqueue to wake blocked Reserve calls
+	notify chan struct{}
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewMemoryMessageBroker creates a new in-process message broker instance
+func NewMemoryMessageBroker() *MemoryMessageBroker {
+	return &MemoryMessageBroker{
+		queues: make(map[string]*memoryQueue),
+		notify: make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+}
+
+func (mb *MemoryMessageBroker) queue(name string) *memoryQueue {
+	q, ok := mb.queues[name]
+	if !ok {
+		q = &memoryQueue{}
+		mb.queues[name] = q
+	}
+	return q
+}
+
+func (mb *MemoryMessageBroker) isClosed() bool {
+	select {
+	case <-mb.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// wake must be called with mb.mu held
+func (mb *MemoryMessageBroker) wake() {
+	close(mb.notify)
+	mb.notify = make(chan struct{})
+}
+
+func (mb *MemoryMessageBroker) Enqueue(ctx context.Context, queue string, message []byte) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	q := mb.queue(queue)
+	q.ready = append(q.ready, Envelope{
+		ID:         newMessageID(),
+		Payload:    bytes.Clone(message),
+		EnqueuedAt: time.Now(),
+	})
+	mb.wake()
+	return nil
+}
+
+func (mb *MemoryMessageBroker) EnqueueAt(ctx context.Context, queue string, message []byte, deliverAt time.Time) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	q := mb.queue(queue)
+	i := sort.Search(len(q.delayed), func(i int) bool {
+		return q.delayed[i].deliverAt.After(deliverAt)
+	})
+	q.delayed = append(q.delayed, memoryDelayed{})
+	copy(q.delayed[i+1:], q.delayed[i:])
+	q.delayed[i] = memoryDelayed{
+		Envelope: Envelope{
+			ID:         newMessageID(),
+			Payload:    bytes.Clone(message),
+			EnqueuedAt: time.Now(),
+		},
+		deliverAt: deliverAt,
+	}
+	// A blocked Reserve recomputes how long to wait for the earliest message
+	mb.wake()
+	return nil
+}
+
+// promoteDelayed moves due delayed messages to the ready queue and returns how
+// long until the next one is due, 0 if none is left. It must be called with
+// mb.mu held.
+func (q *memoryQueue) promoteDelayed(now time.Time) time.Duration {
+	due := 0
+	for due < len(q.delayed) && !q.delayed[due].deliverAt.After(now) {
+		q.ready = append(q.ready, q.delayed[due].Envelope)
+		due++
+	}
+	q.delayed = q.delayed[due:]
+
+	if len(q.delayed) == 0 {
+		return 0
+	}
+	return q.delayed[0].deliverAt.Sub(now)
+}
+
+func (mb *MemoryMessageBroker) Reserve(ctx context.Context, queue string, visibilityTimeout time.Duration) (*Envelope, error) {
+	for {
+		mb.mu.Lock()
+		if mb.isClosed() {
+			mb.mu.Unlock()
+			return nil, ErrBrokerClosed
+		}
+
+		q := mb.queue(queue)
+		nextDue := q.promoteDelayed(time.Now())
+		if len(q.ready) > 0 {
+			envelope := q.ready[0]
+			envelope.Attempts++
+			q.ready = q.ready[1:]
+			q.reserved = append(q.reserved, memoryReservation{
+				Envelope: envelope,
+				deadline: time.Now().Add(visibilityTimeout),
+			})
+			mb.mu.Unlock()
+			envelope.Payload = bytes.Clone(envelope.Payload)
+			return &envelope, nil
+		}
+		notify := mb.notify
+		mb.mu.Unlock()
+
+		// Wake up when the earliest delayed message is due
+		var due <-chan time.Time
+		var timer *time.Timer
+		if nextDue > 0 {
+			timer = time.NewTimer(nextDue)
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-mb.closed:
+			return nil, ErrBrokerClosed
+		case <-notify:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// removeReservation must be called with mb.mu held
+func (q *memoryQueue) removeReservation(id string) (Envelope, bool) {
+	for i, reservation := range q.reserved {
+		if reservation.ID == id {
+			q.reserved = append(q.reserved[:i], q.reserved[i+1:]...)
+			return reservation.Envelope, true
+		}
+	}
+	return Envelope{}, false
+}
+
+func (mb *MemoryMessageBroker) Ack(ctx context.Context, queue string, id string) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.queue(queue).removeReservation(id)
+	return nil
+}
+
+func (mb *MemoryMessageBroker) Nack(ctx context.Context, queue string, id string) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	if envelope, ok := q.removeReservation(id); ok {
+		q.ready = append([]Envelope{envelope}, q.ready...)
+		mb.wake()
+	}
+	return nil
+}
+
+func (mb *MemoryMessageBroker) Release(ctx context.Context, queue string, id string) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	if envelope, ok := q.removeReservation(id); ok {
+		envelope.Attempts--
+		q.ready = append([]Envelope{envelope}, q.ready...)
+		mb.wake()
+	}
+	return nil
+}
+
+func (mb *MemoryMessageBroker) RequeueExpired(ctx context.Context, queue string) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	now := time.Now()
+	stillReserved := q.reserved[:0]
+	expired := []Envelope{}
+	for _, reservation := range q.reserved {
+		if now.Before(reservation.deadline) {
+			stillReserved = append(stillReserved, reservation)
+		} else {
+			expired = append(expired, reservation.Envelope)
+		}
+	}
+	q.reserved = stillReserved
+
+	if len(expired) > 0 {
+		q.ready = append(expired, q.ready...)
+		mb.wake()
+	}
+	return nil
+}
+
+func (mb *MemoryMessageBroker) Extend(ctx context.Context, queue string, id string, visibilityTimeout time.Duration) (bool, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if mb.isClosed() {
+		return false, ErrBrokerClosed
+	}
+
+	q := mb.queue(queue)
+	now := time.Now()
+	for i := range q.reserved {
+		if q.reserved[i].ID == id && now.Before(q.reserved[i].deadline) {
+			q.reserved[i].deadline = now.Add(visibilityTimeout)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (mb *MemoryMessageBroker) DeadLetter(ctx context.Context, queue string, id string, lastError string) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	envelope, ok := q.removeReservation(id)
+	if !ok {
+		return nil
+	}
+	q.dead = append(q.dead, DeadLetter{
+		Envelope:  envelope,
+		LastError: lastError,
+		DeadDate:  time.Now(),
+	})
+	return nil
+}
+
+func (mb *MemoryMessageBroker) ListDeadLetters(ctx context.Context, queue string) ([]DeadLetter, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	deadLetters := []DeadLetter{}
+	for _, deadLetter := range mb.queue(queue).dead {
+		deadLetter.Payload = bytes.Clone(deadLetter.Payload)
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, nil
+}
+
+func (mb *MemoryMessageBroker) DeleteDeadLetter(ctx context.Context, queue string, id string) (bool, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	for i, deadLetter := range q.dead {
+		if deadLetter.ID == id {
+			q.dead = append(q.dead[:i], q.dead[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (mb *MemoryMessageBroker) PurgeDeadLetters(ctx context.Context, queue string) (int, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	purged := len(q.dead)
+	q.dead = nil
+	return purged, nil
+}
+
+func (mb *MemoryMessageBroker) Stats(ctx context.Context, queue string) (QueueStats, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	now := time.Now()
+	q.promoteDelayed(now)
+
+	stats := QueueStats{
+		Ready:    len(q.ready),
+		Delayed:  len(q.delayed),
+		Reserved: len(q.reserved),
+		Dead:     len(q.dead),
+	}
+	for _, reservation := range q.reserved {
+		if now.Before(reservation.deadline) {
+			stats.InFlight++
+		}
+	}
+	for _, envelope := range q.ready {
+		if stats.OldestEnqueuedAt == nil || envelope.EnqueuedAt.Before(*stats.OldestEnqueuedAt) {
+			enqueuedAt := envelope.EnqueuedAt
+			stats.OldestEnqueuedAt = &enqueuedAt
+		}
+	}
+	return stats, nil
+}
+
+func (mb *MemoryMessageBroker) ListMessages(ctx context.Context, queue string, limit int) ([]QueuedMessage, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	q.promoteDelayed(time.Now())
+
+	messages := []QueuedMessage{}
+	for _, envelope := range q.ready {
+		messages = append(messages, QueuedMessage{Envelope: envelope, State: MessageStateReady})
+	}
+	for _, reservation := range q.reserved {
+		deadline := reservation.deadline
+		messages = append(messages, QueuedMessage{Envelope: reservation.Envelope, State: MessageStateReserved, VisibleAt: &deadline})
+	}
+	for _, delayed := range q.delayed {
+		deliverAt := delayed.deliverAt
+		messages = append(messages, QueuedMessage{Envelope: delayed.Envelope, State: MessageStateDelayed, VisibleAt: &deliverAt})
+	}
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+	for i := range messages {
+		messages[i].Payload = bytes.Clone(messages[i].Payload)
+	}
+	return messages, nil
+}
+
+func (mb *MemoryMessageBroker) Delete(ctx context.Context, queue string, id string) (bool, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	if _, ok := q.removeReservation(id); ok {
+		return true, nil
+	}
+	for i, envelope := range q.ready {
+		if envelope.ID == id {
+			q.ready = append(q.ready[:i], q.ready[i+1:]...)
+			return true, nil
+		}
+	}
+	for i, delayed := range q.delayed {
+		if delayed.ID == id {
+			q.delayed = append(q.delayed[:i], q.delayed[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (mb *MemoryMessageBroker) Purge(ctx context.Context, queue string) (int, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	q := mb.queue(queue)
+	purged := len(q.ready) + len(q.reserved) + len(q.delayed)
+	q.ready = nil
+	q.reserved = nil
+	q.delayed = nil
+	return purged, nil
+}
+
+func (mb *MemoryMessageBroker) Close() error {
+	mb.once.Do(func() {
+		close(mb.closed)
+	})
+	return nil
+}
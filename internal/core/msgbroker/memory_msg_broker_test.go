@@ -0,0 +1,14 @@
+package msgbroker_test
+
+import (
+	"testing"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker/brokertest"
+)
+
+func TestMemoryMessageBroker(t *testing.T) {
+	brokertest.Run(t, func(t *testing.T) msgbroker.MessageBroker {
+		return msgbroker.NewMemoryMessageBroker()
+	})
+}
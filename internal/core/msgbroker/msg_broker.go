@@ -0,0 +1,145 @@
+package msgbroker
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrBrokerClosed = errors.New("message broker is closed")
+
+// Envelope wraps every message a broker stores. ID is unique per enqueue, so
+// identical payloads never share reservation state. Attempts counts how many
+// times the message has been reserved, the current reservation included.
+type Envelope struct {
+	ID         string    `json:"id"`
+	Payload    []byte    `json:"payload"`
+	Attempts   int       `json:"attempts"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// DeadLetter is a message taken out of its queue after too many failed
+// deliveries, kept in the queue+":dead" queue until replayed or purged. It
+// keeps the ID of the message.
+type DeadLetter struct {
+	Envelope
+	LastError string    `json:"last_error"`
+	DeadDate  time.Time `json:"dead_date"`
+}
+
+// States of a QueuedMessage
+const (
+	MessageStateReady    = "ready"
+	MessageStateDelayed  = "delayed"
+	MessageStateReserved = "reserved"
+)
+
+// QueueStats counts the messages of one queue by state. Reserved counts every
+// reserved message, InFlight only those whose visibility timeout has not
+// passed yet, the others wait for RequeueExpired. OldestEnqueuedAt is the
+// enqueue time of the oldest ready message, nil when none is ready.
+type QueueStats struct {
+	Ready            int        `json:"ready"`
+	Delayed          int        `json:"delayed"`
+	Reserved         int        `json:"reserved"`
+	InFlight         int        `json:"in_flight"`
+	Dead             int        `json:"dead"`
+	OldestEnqueuedAt *time.Time `json:"oldest_enqueued_at,omitempty"`
+}
+
+// QueuedMessage is a message that is not dead, with its state. VisibleAt is
+// the visibility deadline of a reserved message and the due time of a
+// delayed one.
+type QueuedMessage struct {
+	Envelope
+	State     string     `json:"state"`
+	VisibleAt *time.Time `json:"visible_at,omitempty"`
+}
+
+// MessageBroker is a durable work queue with at-least-once delivery.
+//
+// A reserved message stays invisible to other consumers until it is Ack'ed,
+// Nack'ed, or its visibility timeout passes and RequeueExpired puts it back.
+// Messages of one queue are reserved in the order they were enqueued.
+type MessageBroker interface {
+	// Enqueue appends a message to the queue.
+	Enqueue(ctx context.Context, queue string, message []byte) error
+	// EnqueueAt stores a message that is appended to the queue no earlier than
+	// deliverAt, a time in the past enqueues it right away. Delayed messages are
+	// as durable as queued ones.
+	EnqueueAt(ctx context.Context, queue string, message []byte, deliverAt time.Time) error
+	// Reserve blocks until a message is available or ctx is done, and hides it
+	// from other consumers for visibilityTimeout.
+	Reserve(ctx context.Context, queue string, visibilityTimeout time.Duration) (*Envelope, error)
+	// Ack confirms a reserved message so it is never delivered again.
+	Ack(ctx context.Context, queue string, id string) error
+	// Nack releases a reserved message back to the head of the queue right away.
+	Nack(ctx context.Context, queue string, id string) error
+	// Release gives back a reserved message like Nack, but takes back the
+	// delivery attempt its reservation counted. It is meant for messages the
+	// consumer reserved and never handed out.
+	Release(ctx context.Context, queue string, id string) error
+	// RequeueExpired returns reserved messages whose visibility timeout has passed.
+	RequeueExpired(ctx context.Context, queue string) error
+	// Extend pushes the visibility timeout of a reserved message to
+	// visibilityTimeout from now. It reports false if the message is no longer
+	// reserved, e.g. it was acked or its timeout already passed and it was requeued.
+	Extend(ctx context.Context, queue string, id string, visibilityTimeout time.Duration) (bool, error)
+
+	// DeadLetter moves a reserved message to the queue+":dead" queue.
+	DeadLetter(ctx context.Context, queue string, id string, lastError string) error
+	// ListDeadLetters returns the dead letters of queue, oldest first.
+	ListDeadLetters(ctx context.Context, queue string) ([]DeadLetter, error)
+	// DeleteDeadLetter removes one dead letter, it reports false if id is unknown.
+	DeleteDeadLetter(ctx context.Context, queue string, id string) (bool, error)
+	// PurgeDeadLetters removes every dead letter of queue and returns how many.
+	PurgeDeadLetters(ctx context.Context, queue string) (int, error)
+
+	// Stats counts the messages of queue by state.
+	Stats(ctx context.Context, queue string) (QueueStats, error)
+	// ListMessages returns up to limit messages of queue that are not dead,
+	// queued ones before delayed ones, limit <= 0 returns all of them.
+	ListMessages(ctx context.Context, queue string, limit int) ([]QueuedMessage, error)
+	// Delete removes a message whatever its state, it reports false if id is
+	// unknown. A consumer holding it can no longer extend it.
+	Delete(ctx context.Context, queue string, id string) (bool, error)
+	// Purge removes every message of queue that is not dead and returns how many.
+	Purge(ctx context.Context, queue string) (int, error)
+
+	Close() error
+}
+
+// TxMessageBroker is implemented by brokers living in the workflow database.
+// EnqueueTx makes the enqueue part of tx, so a job is never published for a
+// workflow update that rolled back.
+type TxMessageBroker interface {
+	MessageBroker
+	EnqueueTx(ctx context.Context, tx *sql.Tx, queue string, message []byte) error
+}
+
+// ConsumerMessageBroker is implemented by brokers recording which consumer
+// (see WithConsumer) holds each reserved message. RemoveConsumer forgets a
+// consumer that stopped reserving, messages it still holds stay reserved
+// until they are acked or their visibility timeout passes.
+type ConsumerMessageBroker interface {
+	MessageBroker
+	RemoveConsumer(ctx context.Context, queue string, consumer string) error
+}
+
+var (
+	_ MessageBroker         = (*RedisMessageBroker)(nil)
+	_ ConsumerMessageBroker = (*RedisStreamMessageBroker)(nil)
+	_ MessageBroker         = (*MemoryMessageBroker)(nil)
+	_ TxMessageBroker       = (*PostgreSQLMessageBroker)(nil)
+)
+
+func newMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
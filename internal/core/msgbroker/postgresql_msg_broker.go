@@ -0,0 +1,404 @@
+package msgbroker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultPostgresPollInterval = 500 * time.Millisecond
+
+// PostgreSQLMessageBroker keeps queued jobs in the job_queue table of the
+// workflow database. Reserve claims the oldest visible row with
+// FOR UPDATE SKIP LOCKED and pushes its visible_after forward by the
+// visibility timeout, so an unacked job becomes visible again on its own.
+type PostgreSQLMessageBroker struct {
+	db           *sql.DB
+	pollInterval time.Duration
+	closed       chan struct{}
+	once         sync.Once
+}
+
+// NewPostgreSQLMessageBroker creates a broker on top of an existing connection
+// pool. The pool is owned by the caller and is not closed by Close.
+func NewPostgreSQLMessageBroker(db *sql.DB, pollInterval time.Duration) *PostgreSQLMessageBroker {
+	if pollInterval <= 0 {
+		pollInterval = defaultPostgresPollInterval
+	}
+	return &PostgreSQLMessageBroker{
+		db:           db,
+		pollInterval: pollInterval,
+		closed:       make(chan struct{}),
+	}
+}
+
+func (mb *PostgreSQLMessageBroker) isClosed() bool {
+	select {
+	case <-mb.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (mb *PostgreSQLMessageBroker) Enqueue(ctx context.Context, queue string, message []byte) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	_, err := mb.db.ExecContext(ctx, `INSERT INTO job_queue (queue, payload) VALUES ($1, $2)`, queue, message)
+	return err
+}
+
+// EnqueueAt inserts the job with visible_after in the future, Reserve skips it
+// until then so no mover is needed. The delay is applied to the database clock
+// like every other visibility change.
+func (mb *PostgreSQLMessageBroker) EnqueueAt(ctx context.Context, queue string, message []byte, deliverAt time.Time) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	query := `
+		INSERT INTO job_queue (queue, payload, visible_after)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 millisecond')`
+
+	_, err := mb.db.ExecContext(ctx, query, queue, message, max(time.Until(deliverAt).Milliseconds(), 0))
+	return err
+}
+
+// EnqueueTx inserts the job inside the caller's transaction, it only becomes
+// visible to consumers once tx commits.
+func (mb *PostgreSQLMessageBroker) EnqueueTx(ctx context.Context, tx *sql.Tx, queue string, message []byte) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO job_queue (queue, payload) VALUES ($1, $2)`, queue, message)
+	return err
+}
+
+func (mb *PostgreSQLMessageBroker) Reserve(ctx context.Context, queue string, visibilityTimeout time.Duration) (*Envelope, error) {
+
+	query := `
+		UPDATE job_queue SET reserved = TRUE, attempts = attempts + 1,
+			visible_after = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond'
+		WHERE id = (
+			SELECT id FROM job_queue
+			WHERE queue = $1 AND visible_after <= CURRENT_TIMESTAMP
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, attempts, create_date`
+
+	for {
+		if mb.isClosed() {
+			return nil, ErrBrokerClosed
+		}
+
+		var (
+			envelope Envelope
+			id       int64
+		)
+		err := mb.db.QueryRowContext(ctx, query, queue, visibilityTimeout.Milliseconds()).Scan(&id, &envelope.Payload, &envelope.Attempts, &envelope.EnqueuedAt)
+		if err == nil {
+			envelope.ID = strconv.FormatInt(id, 10)
+			return &envelope, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-mb.closed:
+			return nil, ErrBrokerClosed
+		case <-time.After(mb.pollInterval):
+		}
+	}
+}
+
+// jobID parses a message ID, IDs that are no job_queue id match nothing
+func jobID(id string) (int64, bool) {
+	parsed, err := strconv.ParseInt(id, 10, 64)
+	return parsed, err == nil
+}
+
+func (mb *PostgreSQLMessageBroker) Ack(ctx context.Context, queue string, id string) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+	jobID, ok := jobID(id)
+	if !ok {
+		return nil
+	}
+
+	_, err := mb.db.ExecContext(ctx, `DELETE FROM job_queue WHERE queue = $1 AND id = $2 AND reserved`, queue, jobID)
+	return err
+}
+
+// Nack makes the job visible again right away. Jobs are reserved by id, so it
+// goes back ahead of everything enqueued after it.
+func (mb *PostgreSQLMessageBroker) Nack(ctx context.Context, queue string, id string) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+	jobID, ok := jobID(id)
+	if !ok {
+		return nil
+	}
+
+	query := `
+		UPDATE job_queue SET reserved = FALSE, visible_after = CURRENT_TIMESTAMP
+		WHERE queue = $1 AND id = $2 AND reserved`
+
+	_, err := mb.db.ExecContext(ctx, query, queue, jobID)
+	return err
+}
+
+// Release is Nack taking back the attempt of the reservation, as long as the
+// job is still reserved.
+func (mb *PostgreSQLMessageBroker) Release(ctx context.Context, queue string, id string) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+	jobID, ok := jobID(id)
+	if !ok {
+		return nil
+	}
+
+	query := `
+		UPDATE job_queue SET reserved = FALSE, visible_after = CURRENT_TIMESTAMP, attempts = attempts - 1
+		WHERE queue = $1 AND id = $2 AND reserved AND visible_after > CURRENT_TIMESTAMP`
+
+	_, err := mb.db.ExecContext(ctx, query, queue, jobID)
+	return err
+}
+
+// RequeueExpired only clears the reserved flag of expired jobs, Reserve
+// already picks them up once visible_after has passed.
+func (mb *PostgreSQLMessageBroker) RequeueExpired(ctx context.Context, queue string) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+
+	query := `
+		UPDATE job_queue SET reserved = FALSE
+		WHERE queue = $1 AND reserved AND visible_after <= CURRENT_TIMESTAMP`
+
+	_, err := mb.db.ExecContext(ctx, query, queue)
+	return err
+}
+
+func (mb *PostgreSQLMessageBroker) Extend(ctx context.Context, queue string, id string, visibilityTimeout time.Duration) (bool, error) {
+	if mb.isClosed() {
+		return false, ErrBrokerClosed
+	}
+	jobID, ok := jobID(id)
+	if !ok {
+		return false, nil
+	}
+
+	query := `
+		UPDATE job_queue SET visible_after = CURRENT_TIMESTAMP + $3 * INTERVAL '1 millisecond'
+		WHERE queue = $1 AND id = $2 AND reserved AND visible_after > CURRENT_TIMESTAMP`
+
+	result, err := mb.db.ExecContext(ctx, query, queue, jobID, visibilityTimeout.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	extended, err := result.RowsAffected()
+	return extended > 0, err
+}
+
+// DeadLetter moves the reserved job to job_dead_letter in one statement, the
+// dead letter keeps the job id.
+func (mb *PostgreSQLMessageBroker) DeadLetter(ctx context.Context, queue string, id string, lastError string) error {
+	if mb.isClosed() {
+		return ErrBrokerClosed
+	}
+	jobID, ok := jobID(id)
+	if !ok {
+		return nil
+	}
+
+	query := `
+		WITH dead AS (
+			DELETE FROM job_queue
+			WHERE queue = $1 AND id = $2 AND reserved
+			RETURNING id, queue, payload, attempts, create_date
+		)
+		INSERT INTO job_dead_letter (id, queue, payload, attempts, enqueue_date, last_error)
+		SELECT id, queue, payload, attempts, create_date, $3 FROM dead`
+
+	_, err := mb.db.ExecContext(ctx, query, queue, jobID, lastError)
+	return err
+}
+
+func (mb *PostgreSQLMessageBroker) ListDeadLetters(ctx context.Context, queue string) ([]DeadLetter, error) {
+
+	query := `
+		SELECT id, payload, attempts, enqueue_date, last_error, dead_date
+		FROM job_dead_letter
+		WHERE queue = $1
+		ORDER BY id`
+
+	rows, err := mb.db.QueryContext(ctx, query, queue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadLetters := []DeadLetter{}
+	for rows.Next() {
+		var (
+			deadLetter DeadLetter
+			id         int64
+		)
+		if err := rows.Scan(&id, &deadLetter.Payload, &deadLetter.Attempts, &deadLetter.EnqueuedAt, &deadLetter.LastError, &deadLetter.DeadDate); err != nil {
+			return nil, err
+		}
+		deadLetter.ID = strconv.FormatInt(id, 10)
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, rows.Err()
+}
+
+func (mb *PostgreSQLMessageBroker) DeleteDeadLetter(ctx context.Context, queue string, id string) (bool, error) {
+	deadLetterID, ok := jobID(id)
+	if !ok {
+		return false, nil
+	}
+
+	result, err := mb.db.ExecContext(ctx, `DELETE FROM job_dead_letter WHERE queue = $1 AND id = $2`, queue, deadLetterID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (mb *PostgreSQLMessageBroker) PurgeDeadLetters(ctx context.Context, queue string) (int, error) {
+	result, err := mb.db.ExecContext(ctx, `DELETE FROM job_dead_letter WHERE queue = $1`, queue)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+// Stats counts jobs by state in one statement. An expired reserved job is
+// already visible to Reserve, it is counted as reserved until requeued.
+func (mb *PostgreSQLMessageBroker) Stats(ctx context.Context, queue string) (QueueStats, error) {
+
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE NOT reserved AND visible_after <= CURRENT_TIMESTAMP),
+			COUNT(*) FILTER (WHERE NOT reserved AND visible_after > CURRENT_TIMESTAMP),
+			COUNT(*) FILTER (WHERE reserved),
+			COUNT(*) FILTER (WHERE reserved AND visible_after > CURRENT_TIMESTAMP),
+			(SELECT COUNT(*) FROM job_dead_letter WHERE queue = $1),
+			MIN(create_date) FILTER (WHERE NOT reserved AND visible_after <= CURRENT_TIMESTAMP)
+		FROM job_queue
+		WHERE queue = $1`
+
+	var (
+		stats  QueueStats
+		oldest sql.NullTime
+	)
+	err := mb.db.QueryRowContext(ctx, query, queue).Scan(&stats.Ready, &stats.Delayed, &stats.Reserved, &stats.InFlight, &stats.Dead, &oldest)
+	if err != nil {
+		return QueueStats{}, err
+	}
+	if oldest.Valid {
+		stats.OldestEnqueuedAt = &oldest.Time
+	}
+	return stats, nil
+}
+
+func (mb *PostgreSQLMessageBroker) ListMessages(ctx context.Context, queue string, limit int) ([]QueuedMessage, error) {
+
+	query := `
+		SELECT id, payload, attempts, create_date, reserved, visible_after > CURRENT_TIMESTAMP, visible_after
+		FROM job_queue
+		WHERE queue = $1
+		ORDER BY visible_after > CURRENT_TIMESTAMP AND NOT reserved, id
+		LIMIT NULLIF($2, 0)`
+
+	rows, err := mb.db.QueryContext(ctx, query, queue, max(limit, 0))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []QueuedMessage{}
+	for rows.Next() {
+		var (
+			message      QueuedMessage
+			id           int64
+			reserved     bool
+			pending      bool
+			visibleAfter time.Time
+		)
+		if err := rows.Scan(&id, &message.Payload, &message.Attempts, &message.EnqueuedAt, &reserved, &pending, &visibleAfter); err != nil {
+			return nil, err
+		}
+		message.ID = strconv.FormatInt(id, 10)
+		switch {
+		case reserved:
+			message.State = MessageStateReserved
+			message.VisibleAt = &visibleAfter
+		case pending:
+			message.State = MessageStateDelayed
+			message.VisibleAt = &visibleAfter
+		default:
+			message.State = MessageStateReady
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (mb *PostgreSQLMessageBroker) Delete(ctx context.Context, queue string, id string) (bool, error) {
+	if mb.isClosed() {
+		return false, ErrBrokerClosed
+	}
+	jobID, ok := jobID(id)
+	if !ok {
+		return false, nil
+	}
+
+	result, err := mb.db.ExecContext(ctx, `DELETE FROM job_queue WHERE queue = $1 AND id = $2`, queue, jobID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (mb *PostgreSQLMessageBroker) Purge(ctx context.Context, queue string) (int, error) {
+	if mb.isClosed() {
+		return 0, ErrBrokerClosed
+	}
+
+	result, err := mb.db.ExecContext(ctx, `DELETE FROM job_queue WHERE queue = $1`, queue)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+func (mb *PostgreSQLMessageBroker) Close() error {
+	mb.once.Do(func() {
+		close(mb.closed)
+	})
+	return nil
+}
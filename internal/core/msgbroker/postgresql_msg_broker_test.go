@@ -0,0 +1,108 @@
+package msgbroker_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker/brokertest"
+
+	_ "github.com/lib/pq"
+)
+
+// openPostgres connects to the database named by NO_NOODLE_TEST_POSTGRES_DSN,
+// which must have sql/table.sql applied, and skips the test when it is unset.
+func openPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("NO_NOODLE_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("NO_NOODLE_TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestPostgreSQLMessageBroker(t *testing.T) {
+	db := openPostgres(t)
+
+	brokertest.Run(t, func(t *testing.T) msgbroker.MessageBroker {
+		return msgbroker.NewPostgreSQLMessageBroker(db, 50*time.Millisecond)
+	})
+}
+
+func TestPostgreSQLMessageBrokerEnqueueTx(t *testing.T) {
+	db := openPostgres(t)
+	broker := msgbroker.NewPostgreSQLMessageBroker(db, 50*time.Millisecond)
+	t.Cleanup(func() { broker.Close() })
+
+	ctx := context.Background()
+	queue := "brokertest:EnqueueTx:" + uuid.NewString()
+
+	enqueueTx := func(payload string, commit bool) {
+		t.Helper()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := broker.EnqueueTx(ctx, tx, queue, []byte(payload)); err != nil {
+			tx.Rollback()
+			t.Fatal(err)
+		}
+		if commit {
+			err = tx.Commit()
+		} else {
+			err = tx.Rollback()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A workflow update that rolled back publishes nothing
+	enqueueTx("rolled back", false)
+	stats, err := broker.Stats(ctx, queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Ready != 0 || stats.Delayed != 0 || stats.Reserved != 0 {
+		t.Fatalf("got %+v after rollback, want no job", stats)
+	}
+
+	enqueueTx("committed", true)
+	reserveCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	envelope, err := broker.Reserve(reserveCtx, queue, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(envelope.Payload) != "committed" {
+		t.Fatalf("got payload %q, want %q", envelope.Payload, "committed")
+	}
+	if err := broker.Ack(ctx, queue, envelope.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := broker.ListMessages(ctx, queue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("got %d messages left, want none", len(messages))
+	}
+}
@@ -0,0 +1,71 @@
+package msgbroker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Both Redis brokers keep dead letters as JSON encoded DeadLetter values in
+// the queue+":dead" list, oldest first.
+
+func newRedisDeadLetter(envelope Envelope, lastError string) (string, error) {
+	deadLetter, err := json.Marshal(DeadLetter{
+		Envelope:  envelope,
+		LastError: lastError,
+		DeadDate:  time.Now(),
+	})
+	return string(deadLetter), err
+}
+
+// redisDeadLetters returns the dead letters of queue with their raw list elements
+func redisDeadLetters(ctx context.Context, client redis.UniversalClient, queue string) ([]DeadLetter, []string, error) {
+	elements, err := client.LRange(ctx, queue+":dead", 0, -1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadLetters := make([]DeadLetter, 0, len(elements))
+	raw := make([]string, 0, len(elements))
+	for _, element := range elements {
+		var deadLetter DeadLetter
+		if err := json.Unmarshal([]byte(element), &deadLetter); err != nil {
+			continue
+		}
+		deadLetters = append(deadLetters, deadLetter)
+		raw = append(raw, element)
+	}
+	return deadLetters, raw, nil
+}
+
+func redisListDeadLetters(ctx context.Context, client redis.UniversalClient, queue string) ([]DeadLetter, error) {
+	deadLetters, _, err := redisDeadLetters(ctx, client, queue)
+	return deadLetters, err
+}
+
+func redisDeleteDeadLetter(ctx context.Context, client redis.UniversalClient, queue string, id string) (bool, error) {
+	deadLetters, raw, err := redisDeadLetters(ctx, client, queue)
+	if err != nil {
+		return false, err
+	}
+
+	for i, deadLetter := range deadLetters {
+		if deadLetter.ID == id {
+			removed, err := client.LRem(ctx, queue+":dead", 1, raw[i]).Result()
+			return removed > 0, err
+		}
+	}
+	return false, nil
+}
+
+func redisPurgeDeadLetters(ctx context.Context, client redis.UniversalClient, queue string) (int, error) {
+	pipe := client.TxPipeline()
+	length := pipe.LLen(ctx, queue+":dead")
+	pipe.Del(ctx, queue+":dead")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(length.Val()), nil
+}
@@ -0,0 +1,19 @@
+package msgbroker
+
+import "github.com/redis/go-redis/v9"
+
+func isRedisCluster(client redis.UniversalClient) bool {
+	_, ok := client.(*redis.ClusterClient)
+	return ok
+}
+
+// redisQueueKey returns the name every key of queue is derived from. On a
+// cluster the queue name becomes a hash tag, so all keys of one queue live in
+// one slot and the multi-key scripts and MULTI blocks keep working. Single
+// node and Sentinel deployments keep the plain key names.
+func redisQueueKey(client redis.UniversalClient, queue string) string {
+	if isRedisCluster(client) {
+		return "{" + queue + "}"
+	}
+	return queue
+}
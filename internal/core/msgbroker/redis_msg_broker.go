@@ -2,111 +2,524 @@ package msgbroker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	reserveBlockTimeout = time.Second
+	requeueBatch        = 100
+	// promoteBatch bounds how many due delayed messages one Reserve moves
+	promoteBatch = 100
+)
+
+// Keys of a queue, all derived from the queue name:
+//
+//	queue            list of ready message IDs, reserved from the right
+//	queue:jobs       hash ID -> JSON Envelope
+//	queue:attempts   hash ID -> delivery count
+//	queue:reserved   sorted set ID -> visibility deadline (unix ms)
+//	queue:delayed    sorted set ID -> due time (unix ms) of EnqueueAt messages
+//	queue:signal     at most one token, wakes a blocked Reserve
+//	queue:dead       list of JSON DeadLetter
+//
+// Every state change runs as one Lua script or MULTI block, so a crash
+// never leaves a message reserved without a deadline.
+
+// signal wakes one blocked Reserve, the list never holds more than one token
+const redisSignal = `
+local function signal(key)
+	redis.call('LPUSH', key, 1)
+	redis.call('LTRIM', key, 0, 0)
+end
+`
+
+// KEYS: queue, reserved, jobs, attempts, signal, delayed. ARGV: deadline, now, promote batch
+//
+// Due delayed messages are moved to the tail of the queue first, at most one
+// batch per call, so the mover runs wherever Reserve polls.
+var redisReserveScript = redis.NewScript(redisSignal + `
+local due = redis.call('ZRANGEBYSCORE', KEYS[6], '-inf', ARGV[2], 'LIMIT', 0, ARGV[3])
+for i = 1, #due do
+	redis.call('ZREM', KEYS[6], due[i])
+	redis.call('LPUSH', KEYS[1], due[i])
+end
+while true do
+	local id = redis.call('RPOP', KEYS[1])
+	if not id then
+		return false
+	end
+	local envelope = redis.call('HGET', KEYS[3], id)
+	if envelope then
+		redis.call('ZADD', KEYS[2], ARGV[1], id)
+		local attempts = redis.call('HINCRBY', KEYS[4], id, 1)
+		if redis.call('LLEN', KEYS[1]) > 0 then
+			signal(KEYS[5])
+		end
+		return {envelope, attempts}
+	end
+	-- the message was acked while queued, drop the dangling ID
+end
+`)
+
+// KEYS: queue, reserved, signal. ARGV: id
+var redisNackScript = redis.NewScript(redisSignal + `
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 1 then
+	redis.call('RPUSH', KEYS[1], ARGV[1])
+	signal(KEYS[3])
+end
+return 0
+`)
+
+// KEYS: queue, reserved, signal, attempts. ARGV: id
+var redisReleaseScript = redis.NewScript(redisSignal + `
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 1 then
+	redis.call('HINCRBY', KEYS[4], ARGV[1], -1)
+	redis.call('RPUSH', KEYS[1], ARGV[1])
+	signal(KEYS[3])
+end
+return 0
+`)
+
+// KEYS: queue, reserved, signal. ARGV: now, batch size
+var redisRequeueExpiredScript = redis.NewScript(redisSignal + `
+local ids = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for i = #ids, 1, -1 do
+	redis.call('ZREM', KEYS[2], ids[i])
+	redis.call('RPUSH', KEYS[1], ids[i])
+end
+if #ids > 0 then
+	signal(KEYS[3])
+end
+return #ids
+`)
+
+// KEYS: reserved. ARGV: id, now, deadline
+var redisExtendScript = redis.NewScript(`
+local current = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not current or tonumber(current) <= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], 'XX', ARGV[3], ARGV[1])
+return 1
+`)
+
+// KEYS: reserved, jobs, attempts, dead. ARGV: id, last error, dead date
+var redisDeadLetterScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+local envelope = redis.call('HGET', KEYS[2], ARGV[1])
+if not envelope then
+	return 0
+end
+local deadLetter = cjson.decode(envelope)
+deadLetter['attempts'] = tonumber(redis.call('HGET', KEYS[3], ARGV[1])) or 0
+deadLetter['last_error'] = ARGV[2]
+deadLetter['dead_date'] = ARGV[3]
+redis.call('RPUSH', KEYS[4], cjson.encode(deadLetter))
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1
+`)
+
+// KEYS: queue, reserved, delayed, jobs, attempts. ARGV: id
+var redisDeleteScript = redis.NewScript(`
+redis.call('LREM', KEYS[1], 0, ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+return redis.call('HDEL', KEYS[4], ARGV[1])
+`)
+
+// KEYS: processing, queue, reserved, signal
+//
+// Older versions reserved with BRPOPLPUSH into queue:processing and set the
+// deadline afterwards. IDs left there without a deadline go back to the head
+// of the queue, the others are already tracked by queue:reserved.
+var redisRecoverScript = redis.NewScript(redisSignal + `
+local ids = redis.call('LRANGE', KEYS[1], 0, -1)
+local recovered = 0
+for i = #ids, 1, -1 do
+	if not redis.call('ZSCORE', KEYS[3], ids[i]) then
+		redis.call('RPUSH', KEYS[2], ids[i])
+		recovered = recovered + 1
+	end
+end
+redis.call('DEL', KEYS[1])
+if recovered > 0 then
+	signal(KEYS[4])
+end
+return recovered
+`)
+
 type RedisMessageBroker struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-// NewRedisMessageBroker creates a new Redis message broker instance
-func NewRedisMessageBroker(addr string, password string, db int) (*RedisMessageBroker, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr: addr, // e.g., "localhost:6379"
-		// Username: "default",
-		// Password: password,
-		DB: db,
-	})
-
-	// Test connection
+// NewRedisMessageBroker creates a new Redis message broker instance on a
+// single node, Sentinel or Cluster client (see util.NewRedis). The broker
+// closes client on Close.
+func NewRedisMessageBroker(client redis.UniversalClient) (*RedisMessageBroker, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+	broker := &RedisMessageBroker{
+		client: client,
 	}
 
-	return &RedisMessageBroker{
-		client: client,
-	}, nil
+	if err := broker.recover(ctx); err != nil {
+		return nil, fmt.Errorf("failed to recover orphaned Redis reservations: %v", err)
+	}
+
+	return broker, nil
 }
 
-// Enqueue pushes a message onto a Redis list (queue)
-func (rb *RedisMessageBroker) Enqueue(ctx context.Context, queue string, message []byte) error {
-	return rb.client.RPush(ctx, queue, message).Err()
+func (rb *RedisMessageBroker) key(queue string) string {
+	return redisQueueKey(rb.client, queue)
 }
 
-// Dequeue reserves a message with a visibility timeout.
-//
-// It moves a message from the main queue to a processing queue and records a
-// deadline in a sorted set. Until the message is Ack'ed or the deadline
-// passes and it is re-queued, no other consumer will see it.
-func (rb *RedisMessageBroker) Dequeue(ctx context.Context, queue string, visibilityTimeout time.Duration) ([]byte, error) {
-	processingQueue := queue + ":processing"
-	reservedSet := queue + ":reserved"
-
-	// 0 timeout means block indefinitely until a message arrives or ctx is cancelled
-	msg, err := rb.client.BRPopLPush(ctx, queue, processingQueue, 0).Result()
+// recover requeues message IDs orphaned in queue:processing lists on startup.
+// Brokers that wrote those lists only ran on a single node, so a cluster has
+// nothing to recover.
+func (rb *RedisMessageBroker) recover(ctx context.Context) error {
+	if isRedisCluster(rb.client) {
+		return nil
+	}
+	iter := rb.client.ScanType(ctx, 0, "*:processing", 100, "list").Iterator()
+	for iter.Next(ctx) {
+		processingQueue := iter.Val()
+		queue := strings.TrimSuffix(processingQueue, ":processing")
+
+		recovered, err := redisRecoverScript.Run(ctx, rb.client,
+			[]string{processingQueue, queue, queue + ":reserved", queue + ":signal"},
+		).Int()
+		if err != nil {
+			return err
+		}
+		if recovered > 0 {
+			log.Println("requeued", recovered, "orphaned messages of queue:", queue)
+		}
+	}
+	return iter.Err()
+}
+
+// Enqueue stores the message envelope in the queue+":jobs" hash and pushes its
+// ID onto the tail of the Redis list (queue). Consumers pop from the right, so
+// IDs are pushed on the left to be reserved in order.
+func (rb *RedisMessageBroker) Enqueue(ctx context.Context, queue string, message []byte) error {
+	queue = rb.key(queue)
+	id := newMessageID()
+	envelope, err := json.Marshal(Envelope{
+		ID:         id,
+		Payload:    message,
+		EnqueuedAt: time.Now(),
+	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	pipe := rb.client.TxPipeline()
+	pipe.HSet(ctx, queue+":jobs", id, envelope)
+	pipe.LPush(ctx, queue, id)
+	pipe.LPush(ctx, queue+":signal", 1)
+	pipe.LTrim(ctx, queue+":signal", 0, 0)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// EnqueueAt stores the envelope right away and schedules its ID in
+// queue:delayed, Reserve moves it to the queue once it is due. Reserve polls
+// at least every reserveBlockTimeout, which bounds the delivery delay.
+func (rb *RedisMessageBroker) EnqueueAt(ctx context.Context, queue string, message []byte, deliverAt time.Time) error {
+	if !deliverAt.After(time.Now()) {
+		return rb.Enqueue(ctx, queue, message)
 	}
+	queue = rb.key(queue)
 
-	// Record visibility timeout deadline
-	deadline := time.Now().Add(visibilityTimeout).Unix()
-	err = rb.client.ZAdd(ctx, reservedSet, redis.Z{
-		Score:  float64(deadline),
-		Member: msg,
-	}).Err()
+	id := newMessageID()
+	envelope, err := json.Marshal(Envelope{
+		ID:         id,
+		Payload:    message,
+		EnqueuedAt: time.Now(),
+	})
 	if err != nil {
+		return err
+	}
+
+	pipe := rb.client.TxPipeline()
+	pipe.HSet(ctx, queue+":jobs", id, envelope)
+	pipe.ZAdd(ctx, queue+":delayed", redis.Z{Score: float64(deliverAt.UnixMilli()), Member: id})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Reserve reserves a message with a visibility timeout.
+//
+// Popping the ID, recording its deadline in queue:reserved and counting the
+// attempt happen in one script. Until the message is Ack'ed or the deadline
+// passes and it is re-queued, no other consumer will see it. An empty queue
+// is waited on through queue:signal.
+func (rb *RedisMessageBroker) Reserve(ctx context.Context, queue string, visibilityTimeout time.Duration) (*Envelope, error) {
+	queue = rb.key(queue)
+	keys := []string{queue, queue + ":reserved", queue + ":jobs", queue + ":attempts", queue + ":signal", queue + ":delayed"}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		deadline := now.Add(visibilityTimeout).UnixMilli()
+		result, err := redisReserveScript.Run(ctx, rb.client, keys, deadline, now.UnixMilli(), promoteBatch).Slice()
+		if err == nil {
+			return decodeRedisReservation(result)
+		}
+		if err != redis.Nil {
+			return nil, err
+		}
+
+		// Block in short rounds, a blocking command is not interrupted by ctx cancellation
+		err = rb.client.BLPop(ctx, reserveBlockTimeout, queue+":signal").Err()
+		if err != nil && err != redis.Nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+	}
+}
+
+func decodeRedisReservation(result []interface{}) (*Envelope, error) {
+	if len(result) != 2 {
+		return nil, fmt.Errorf("unexpected reserve result: %v", result)
+	}
+
+	data, _ := result[0].(string)
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil {
 		return nil, err
 	}
+	attempts, _ := result[1].(int64)
+	envelope.Attempts = int(attempts)
 
-	return []byte(msg), nil
+	return &envelope, nil
 }
 
 // Ack confirms successful processing of a message, removing it from the
-// processing queue and the reserved set so it will not be re-delivered.
-func (rb *RedisMessageBroker) Ack(ctx context.Context, queue string, message []byte) error {
-	processingQueue := queue + ":processing"
-	reservedSet := queue + ":reserved"
-	msg := string(message)
-
+// reserved set and the jobs hash so it will not be re-delivered.
+func (rb *RedisMessageBroker) Ack(ctx context.Context, queue string, id string) error {
+	queue = rb.key(queue)
 	pipe := rb.client.TxPipeline()
-	pipe.LRem(ctx, processingQueue, 1, msg)
-	pipe.ZRem(ctx, reservedSet, msg)
+	pipe.ZRem(ctx, queue+":reserved", id)
+	pipe.HDel(ctx, queue+":jobs", id)
+	pipe.HDel(ctx, queue+":attempts", id)
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
-// RequeueExpired scans for messages whose visibility timeout has expired and
-// moves them back to the main queue so they can be processed again.
+// Nack releases a reserved message back to the head of the queue so it is
+// delivered again right away instead of waiting for its visibility timeout.
+func (rb *RedisMessageBroker) Nack(ctx context.Context, queue string, id string) error {
+	queue = rb.key(queue)
+	return redisNackScript.Run(ctx, rb.client,
+		[]string{queue, queue + ":reserved", queue + ":signal"}, id,
+	).Err()
+}
+
+// Release is Nack taking back the delivery count of the reservation
+func (rb *RedisMessageBroker) Release(ctx context.Context, queue string, id string) error {
+	queue = rb.key(queue)
+	return redisReleaseScript.Run(ctx, rb.client,
+		[]string{queue, queue + ":reserved", queue + ":signal", queue + ":attempts"}, id,
+	).Err()
+}
+
+// RequeueExpired moves messages whose visibility timeout has expired back to
+// the head of the main queue, in batches so a large backlog does not block
+// Redis for long. Each batch is one script, so concurrent core instances
+// never requeue the same message twice.
 func (rb *RedisMessageBroker) RequeueExpired(ctx context.Context, queue string) error {
-	processingQueue := queue + ":processing"
-	reservedSet := queue + ":reserved"
-	now := time.Now().Unix()
-
-	expired, err := rb.client.ZRangeByScore(ctx, reservedSet, &redis.ZRangeBy{
-		Min: "-inf",
-		Max: fmt.Sprint(now),
-	}).Result()
+	queue = rb.key(queue)
+	keys := []string{queue, queue + ":reserved", queue + ":signal"}
+	now := time.Now().UnixMilli()
+
+	for {
+		requeued, err := redisRequeueExpiredScript.Run(ctx, rb.client, keys, now, requeueBatch).Int()
+		if err != nil {
+			return err
+		}
+		if requeued < requeueBatch {
+			return nil
+		}
+	}
+}
+
+// Extend moves the deadline of a reserved message, a deadline that already
+// passed is final even if RequeueExpired has not run yet.
+func (rb *RedisMessageBroker) Extend(ctx context.Context, queue string, id string, visibilityTimeout time.Duration) (bool, error) {
+	queue = rb.key(queue)
+	now := time.Now()
+	extended, err := redisExtendScript.Run(ctx, rb.client,
+		[]string{queue + ":reserved"}, id, now.UnixMilli(), now.Add(visibilityTimeout).UnixMilli(),
+	).Int()
+	return extended == 1, err
+}
+
+// DeadLetter removes a reserved message and appends it to the queue+":dead" list
+func (rb *RedisMessageBroker) DeadLetter(ctx context.Context, queue string, id string, lastError string) error {
+	queue = rb.key(queue)
+	deadDate, err := time.Now().MarshalText()
 	if err != nil {
 		return err
 	}
-	if len(expired) == 0 {
-		return nil
+
+	return redisDeadLetterScript.Run(ctx, rb.client,
+		[]string{queue + ":reserved", queue + ":jobs", queue + ":attempts", queue + ":dead"},
+		id, lastError, string(deadDate),
+	).Err()
+}
+
+func (rb *RedisMessageBroker) ListDeadLetters(ctx context.Context, queue string) ([]DeadLetter, error) {
+	return redisListDeadLetters(ctx, rb.client, rb.key(queue))
+}
+
+func (rb *RedisMessageBroker) DeleteDeadLetter(ctx context.Context, queue string, id string) (bool, error) {
+	return redisDeleteDeadLetter(ctx, rb.client, rb.key(queue), id)
+}
+
+func (rb *RedisMessageBroker) PurgeDeadLetters(ctx context.Context, queue string) (int, error) {
+	return redisPurgeDeadLetters(ctx, rb.client, rb.key(queue))
+}
+
+// Stats reports the message next in line as the oldest ready one, a Nack'ed
+// message goes ahead of older ones.
+func (rb *RedisMessageBroker) Stats(ctx context.Context, queue string) (QueueStats, error) {
+	queue = rb.key(queue)
+	pipe := rb.client.Pipeline()
+	ready := pipe.LLen(ctx, queue)
+	delayed := pipe.ZCard(ctx, queue+":delayed")
+	reserved := pipe.ZCard(ctx, queue+":reserved")
+	inFlight := pipe.ZCount(ctx, queue+":reserved", "("+strconv.FormatInt(time.Now().UnixMilli(), 10), "+inf")
+	dead := pipe.LLen(ctx, queue+":dead")
+	next := pipe.LIndex(ctx, queue, -1)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return QueueStats{}, err
 	}
 
+	stats := QueueStats{
+		Ready:    int(ready.Val()),
+		Delayed:  int(delayed.Val()),
+		Reserved: int(reserved.Val()),
+		InFlight: int(inFlight.Val()),
+		Dead:     int(dead.Val()),
+	}
+	if next.Val() != "" {
+		data, err := rb.client.HGet(ctx, queue+":jobs", next.Val()).Result()
+		if err != nil && err != redis.Nil {
+			return QueueStats{}, err
+		}
+		var envelope Envelope
+		if err == nil && json.Unmarshal([]byte(data), &envelope) == nil {
+			stats.OldestEnqueuedAt = &envelope.EnqueuedAt
+		}
+	}
+	return stats, nil
+}
+
+// ListMessages returns ready messages in the order they are reserved, then
+// reserved and delayed ones by deadline.
+func (rb *RedisMessageBroker) ListMessages(ctx context.Context, queue string, limit int) ([]QueuedMessage, error) {
+	queue = rb.key(queue)
+	start, stop := int64(0), int64(-1)
+	if limit > 0 {
+		start, stop = -int64(limit), int64(limit)-1
+	}
+
+	pipe := rb.client.Pipeline()
+	ready := pipe.LRange(ctx, queue, start, -1)
+	reserved := pipe.ZRangeWithScores(ctx, queue+":reserved", 0, stop)
+	delayed := pipe.ZRangeWithScores(ctx, queue+":delayed", 0, stop)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	// Ready IDs are reserved from the right of the list
+	messages := []QueuedMessage{}
+	readyIDs := ready.Val()
+	for i := len(readyIDs) - 1; i >= 0; i-- {
+		messages = append(messages, QueuedMessage{Envelope: Envelope{ID: readyIDs[i]}, State: MessageStateReady})
+	}
+	messages = appendRedisScheduled(messages, MessageStateReserved, reserved.Val())
+	messages = appendRedisScheduled(messages, MessageStateDelayed, delayed.Val())
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	pipe = rb.client.Pipeline()
+	envelopes := pipe.HMGet(ctx, queue+":jobs", ids...)
+	attempts := pipe.HMGet(ctx, queue+":attempts", ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	// IDs without an envelope were acked while queued
+	result := make([]QueuedMessage, 0, len(messages))
+	for i, message := range messages {
+		data, ok := envelopes.Val()[i].(string)
+		if !ok || json.Unmarshal([]byte(data), &message.Envelope) != nil {
+			continue
+		}
+		if count, ok := attempts.Val()[i].(string); ok {
+			message.Attempts, _ = strconv.Atoi(count)
+		}
+		result = append(result, message)
+	}
+	return result, nil
+}
+
+// appendRedisScheduled appends the members of a sorted set scored by unix ms
+func appendRedisScheduled(messages []QueuedMessage, state string, members []redis.Z) []QueuedMessage {
+	for _, member := range members {
+		id, _ := member.Member.(string)
+		visibleAt := time.UnixMilli(int64(member.Score))
+		messages = append(messages, QueuedMessage{Envelope: Envelope{ID: id}, State: state, VisibleAt: &visibleAt})
+	}
+	return messages
+}
+
+func (rb *RedisMessageBroker) Delete(ctx context.Context, queue string, id string) (bool, error) {
+	queue = rb.key(queue)
+	deleted, err := redisDeleteScript.Run(ctx, rb.client,
+		[]string{queue, queue + ":reserved", queue + ":delayed", queue + ":jobs", queue + ":attempts"}, id,
+	).Int()
+	return deleted == 1, err
+}
+
+// Purge counts the envelopes in queue:jobs, every message that is not dead
+// has one.
+func (rb *RedisMessageBroker) Purge(ctx context.Context, queue string) (int, error) {
+	queue = rb.key(queue)
 	pipe := rb.client.TxPipeline()
-	for _, msg := range expired {
-		pipe.LRem(ctx, processingQueue, 1, msg)
-		pipe.RPush(ctx, queue, msg)
-		pipe.ZRem(ctx, reservedSet, msg)
+	purged := pipe.HLen(ctx, queue+":jobs")
+	pipe.Del(ctx, queue, queue+":reserved", queue+":delayed", queue+":jobs", queue+":attempts")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
 	}
-	_, err = pipe.Exec(ctx)
-	return err
+	return int(purged.Val()), nil
 }
 
 // Close closes the Redis connection
@@ -0,0 +1,79 @@
+package msgbroker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker/brokertest"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisMessageBroker(t *testing.T) {
+	addr := redisAddr(t)
+
+	brokertest.Run(t, func(t *testing.T) msgbroker.MessageBroker {
+		broker, err := msgbroker.NewRedisMessageBroker(newRedisClient(t, addr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return broker
+	})
+}
+
+// TestRedisMessageBrokerRecoversProcessing starts a broker on a queue left by
+// an older version with one message orphaned in queue:processing and one
+// whose deadline was already recorded in queue:reserved.
+func TestRedisMessageBrokerRecoversProcessing(t *testing.T) {
+	client := newRedisClient(t, redisAddr(t))
+	ctx := context.Background()
+	queue := "brokertest:RecoversProcessing:" + uuid.NewString()
+
+	for _, id := range []string{"orphaned", "reserved"} {
+		envelope, err := json.Marshal(msgbroker.Envelope{ID: id, Payload: []byte(id), EnqueuedAt: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.HSet(ctx, queue+":jobs", id, envelope).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.RPush(ctx, queue+":processing", "orphaned", "reserved").Err(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := float64(time.Now().Add(time.Minute).UnixMilli())
+	if err := client.ZAdd(ctx, queue+":reserved", redis.Z{Score: deadline, Member: "reserved"}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	broker, err := msgbroker.NewRedisMessageBroker(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { broker.Close() })
+
+	if exists := client.Exists(ctx, queue+":processing").Val(); exists != 0 {
+		t.Fatalf("%s:processing still exists after recovery", queue)
+	}
+
+	reserveCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	envelope, err := broker.Reserve(reserveCtx, queue, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.ID != "orphaned" {
+		t.Fatalf("reserved %s, want the orphaned message", envelope.ID)
+	}
+
+	// The message with a recorded deadline stays reserved until it passes
+	reserveCtx, cancel = context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if envelope, err := broker.Reserve(reserveCtx, queue, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, %v, want the reserved message to stay hidden", envelope, err)
+	}
+}
@@ -0,0 +1,706 @@
+package msgbroker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamGroup        = "no_noodle_workflow"
+	streamPayloadField = "payload"
+	// streamReleasedConsumer owns entries given back by Nack or RequeueExpired
+	streamReleasedConsumer = "released"
+	// streamDetachedConsumer owns entries still reserved by a removed consumer
+	streamDetachedConsumer = "detached"
+	// streamReleasedIdle is set as the idle time of released entries, far above
+	// any real visibility timeout, so Reserve can tell them apart in the PEL
+	streamReleasedIdle = 10 * 365 * 24 * time.Hour
+	streamClaimBatch   = 100
+	// streamDefaultVisibility applies to pending entries without a recorded timeout
+	streamDefaultVisibility = 30 * time.Second
+)
+
+// KEYS: stream, visibility. ARGV: id, visibility ms, group, released consumer, default visibility ms
+//
+// XCLAIM to the current owner resets the idle time of the entry, which is what
+// RequeueExpired measures the visibility timeout against.
+var streamExtendScript = redis.NewScript(`
+local pending = redis.call('XPENDING', KEYS[1], ARGV[3], ARGV[1], ARGV[1], 1)
+if #pending == 0 or pending[1][2] == ARGV[4] then
+	return 0
+end
+local visibility = tonumber(redis.call('HGET', KEYS[2], ARGV[1])) or tonumber(ARGV[5])
+if pending[1][3] >= visibility then
+	return 0
+end
+redis.call('XCLAIM', KEYS[1], ARGV[3], pending[1][2], 0, ARGV[1], 'JUSTID')
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+return 1
+`)
+
+// KEYS: stream, attempts. ARGV: id, group, released consumer, released idle ms
+//
+// Only an entry still held by a consumer is released, so the delivery it
+// counted is taken back at most once.
+var streamReleaseScript = redis.NewScript(`
+local pending = redis.call('XPENDING', KEYS[1], ARGV[2], ARGV[1], ARGV[1], 1)
+if #pending == 0 or pending[1][2] == ARGV[3] then
+	return 0
+end
+redis.call('XCLAIM', KEYS[1], ARGV[2], ARGV[3], 0, ARGV[1], 'IDLE', ARGV[4], 'JUSTID')
+redis.call('HINCRBY', KEYS[2], ARGV[1], -1)
+return 1
+`)
+
+// KEYS: stream. ARGV: group, consumer, detached consumer, batch
+//
+// XCLAIM with the IDLE of the entry keeps its visibility timeout running, so
+// RequeueExpired still releases it on time once the consumer is gone.
+var streamRemoveConsumerScript = redis.NewScript(`
+while true do
+	local pending = redis.call('XPENDING', KEYS[1], ARGV[1], '-', '+', ARGV[4], ARGV[2])
+	if #pending == 0 then
+		break
+	end
+	for i = 1, #pending do
+		redis.call('XCLAIM', KEYS[1], ARGV[1], ARGV[3], 0, pending[i][1], 'IDLE', pending[i][3], 'JUSTID')
+	end
+end
+return redis.call('XGROUP', 'DELCONSUMER', KEYS[1], ARGV[1], ARGV[2])
+`)
+
+// KEYS: delayed, delayed jobs, stream. ARGV: now, batch, payload field
+//
+// Entry IDs are assigned by XADD, so a delayed message gets its stream ID,
+// which is also its message ID, only once it is due.
+var streamPromoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for i = 1, #due do
+	local payload = redis.call('HGET', KEYS[2], due[i])
+	if payload then
+		redis.call('XADD', KEYS[3], '*', ARGV[3], payload)
+	end
+	redis.call('ZREM', KEYS[1], due[i])
+	redis.call('HDEL', KEYS[2], due[i])
+end
+return #due
+`)
+
+type consumerKey struct{}
+
+// WithConsumer names the consumer reserving through ctx. Brokers with
+// per-consumer ownership (Redis Streams) record pending messages under it.
+func WithConsumer(ctx context.Context, consumer string) context.Context {
+	return context.WithValue(ctx, consumerKey{}, consumer)
+}
+
+func consumerFromContext(ctx context.Context) string {
+	if consumer, ok := ctx.Value(consumerKey{}).(string); ok && consumer != "" {
+		return consumer
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// RedisStreamMessageBroker stores every queue as a Redis stream read by one
+// consumer group. A reserved message is an entry of the group's pending
+// entries list (PEL), owned by the consumer that read it, so XPENDING shows
+// who holds what. The entry ID is the message ID and carries the enqueue
+// time. The visibility timeout and delivery count of each pending entry are
+// kept in the queue+":visibility" and queue+":attempts" hashes. Messages of
+// EnqueueAt wait in the queue+":delayed" sorted set, their payload in the
+// queue+":delayed:jobs" hash, until Reserve adds them to the stream.
+type RedisStreamMessageBroker struct {
+	client redis.UniversalClient
+
+	mu     sync.Mutex
+	groups map[string]bool
+}
+
+// NewRedisStreamMessageBroker creates a new Redis Streams message broker
+// instance on a single node, Sentinel or Cluster client (see util.NewRedis).
+// The broker closes client on Close.
+func NewRedisStreamMessageBroker(client redis.UniversalClient) *RedisStreamMessageBroker {
+	return &RedisStreamMessageBroker{
+		client: client,
+		groups: make(map[string]bool),
+	}
+}
+
+func (rb *RedisStreamMessageBroker) key(queue string) string {
+	return redisQueueKey(rb.client, queue)
+}
+
+// ensureGroup creates the consumer group reading queue from its first entry
+func (rb *RedisStreamMessageBroker) ensureGroup(ctx context.Context, queue string) error {
+	rb.mu.Lock()
+	ok := rb.groups[queue]
+	rb.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	err := rb.client.XGroupCreateMkStream(ctx, queue, streamGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	rb.mu.Lock()
+	rb.groups[queue] = true
+	rb.mu.Unlock()
+	return nil
+}
+
+func (rb *RedisStreamMessageBroker) Enqueue(ctx context.Context, queue string, message []byte) error {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return err
+	}
+
+	return rb.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: queue,
+		Values: map[string]interface{}{streamPayloadField: message},
+	}).Err()
+}
+
+// EnqueueAt keeps the payload outside the stream until deliverAt, Reserve adds
+// it to the stream once it is due, at most promoteBatch messages per round.
+func (rb *RedisStreamMessageBroker) EnqueueAt(ctx context.Context, queue string, message []byte, deliverAt time.Time) error {
+	if !deliverAt.After(time.Now()) {
+		return rb.Enqueue(ctx, queue, message)
+	}
+	queue = rb.key(queue)
+
+	id := newMessageID()
+	pipe := rb.client.TxPipeline()
+	pipe.HSet(ctx, queue+":delayed:jobs", id, message)
+	pipe.ZAdd(ctx, queue+":delayed", redis.Z{Score: float64(deliverAt.UnixMilli()), Member: id})
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (rb *RedisStreamMessageBroker) promoteDelayed(ctx context.Context, queue string) error {
+	return streamPromoteScript.Run(ctx, rb.client,
+		[]string{queue + ":delayed", queue + ":delayed:jobs", queue},
+		time.Now().UnixMilli(), promoteBatch, streamPayloadField,
+	).Err()
+}
+
+// Reserve first takes over released entries, oldest first, then reads new
+// entries for the consumer named in ctx (see WithConsumer). Due delayed
+// messages are added to the stream on every round.
+func (rb *RedisStreamMessageBroker) Reserve(ctx context.Context, queue string, visibilityTimeout time.Duration) (*Envelope, error) {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return nil, err
+	}
+	consumer := consumerFromContext(ctx)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := rb.promoteDelayed(ctx, queue); err != nil {
+			return nil, err
+		}
+
+		entry, ok, err := rb.claimReleased(ctx, queue, consumer)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return rb.reserve(ctx, queue, entry, visibilityTimeout)
+		}
+
+		// Block in short rounds, a blocking command is not interrupted by ctx cancellation
+		streams, err := rb.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    streamGroup,
+			Consumer: consumer,
+			Streams:  []string{queue, ">"},
+			Count:    1,
+			Block:    reserveBlockTimeout,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				if _, ok := entry.Values[streamPayloadField].(string); ok {
+					return rb.reserve(ctx, queue, entry, visibilityTimeout)
+				}
+				rb.client.XAck(ctx, queue, streamGroup, entry.ID)
+			}
+		}
+	}
+}
+
+// reserve records the visibility timeout of a freshly claimed entry and
+// counts the delivery.
+func (rb *RedisStreamMessageBroker) reserve(ctx context.Context, queue string, entry redis.XMessage, visibilityTimeout time.Duration) (*Envelope, error) {
+	pipe := rb.client.TxPipeline()
+	pipe.HSet(ctx, queue+":visibility", entry.ID, visibilityTimeout.Milliseconds())
+	attempts := pipe.HIncrBy(ctx, queue+":attempts", entry.ID, 1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	envelope := streamEnvelope(entry)
+	envelope.Attempts = int(attempts.Val())
+	return &envelope, nil
+}
+
+// streamEnvelope builds the envelope of an entry, its ID starts with the
+// millisecond time XADD stored it at.
+func streamEnvelope(entry redis.XMessage) Envelope {
+	payload, _ := entry.Values[streamPayloadField].(string)
+	envelope := Envelope{
+		ID:      entry.ID,
+		Payload: []byte(payload),
+	}
+
+	millis, _, _ := strings.Cut(entry.ID, "-")
+	if ms, err := strconv.ParseInt(millis, 10, 64); err == nil {
+		envelope.EnqueuedAt = time.UnixMilli(ms)
+	}
+	return envelope
+}
+
+// claimReleased moves the oldest released entry to consumer. Released entries
+// are the only ones idle for streamReleasedIdle, XAUTOCLAIM resets the idle
+// time so no other consumer can take the same entry.
+func (rb *RedisStreamMessageBroker) claimReleased(ctx context.Context, queue string, consumer string) (redis.XMessage, bool, error) {
+	start := "0-0"
+	for {
+		entries, next, err := rb.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   queue,
+			Group:    streamGroup,
+			Consumer: consumer,
+			MinIdle:  streamReleasedIdle,
+			Start:    start,
+			Count:    1,
+		}).Result()
+		if err != nil {
+			return redis.XMessage{}, false, err
+		}
+
+		for _, entry := range entries {
+			if _, ok := entry.Values[streamPayloadField].(string); ok {
+				return entry, true, nil
+			}
+			rb.client.XAck(ctx, queue, streamGroup, entry.ID)
+		}
+
+		if next == "0-0" {
+			return redis.XMessage{}, false, nil
+		}
+		start = next
+	}
+}
+
+// Ack acknowledges the entry and deletes it from the stream
+func (rb *RedisStreamMessageBroker) Ack(ctx context.Context, queue string, id string) error {
+	queue = rb.key(queue)
+	pipe := rb.client.TxPipeline()
+	pipe.XAck(ctx, queue, streamGroup, id)
+	pipe.XDel(ctx, queue, id)
+	pipe.HDel(ctx, queue+":visibility", id)
+	pipe.HDel(ctx, queue+":attempts", id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Nack hands the entry to the released consumer so the next Reserve takes it
+// over before reading new entries.
+func (rb *RedisStreamMessageBroker) Nack(ctx context.Context, queue string, id string) error {
+	return rb.release(ctx, rb.key(queue), id, 0)
+}
+
+// Release is Nack taking back the delivery count of the reservation
+func (rb *RedisStreamMessageBroker) Release(ctx context.Context, queue string, id string) error {
+	queue = rb.key(queue)
+	return streamReleaseScript.Run(ctx, rb.client,
+		[]string{queue, queue + ":attempts"}, id, streamGroup, streamReleasedConsumer, streamReleasedIdle.Milliseconds(),
+	).Err()
+}
+
+// release hands the entry to streamReleasedConsumer if it has been idle for at
+// least minIdle, XCLAIM checks and sets the idle time atomically.
+func (rb *RedisStreamMessageBroker) release(ctx context.Context, queue string, id string, minIdle time.Duration) error {
+	return rb.client.Do(ctx,
+		"XCLAIM", queue, streamGroup, streamReleasedConsumer, minIdle.Milliseconds(), id,
+		"IDLE", streamReleasedIdle.Milliseconds(), "JUSTID",
+	).Err()
+}
+
+// RequeueExpired releases pending entries, of any consumer, idle for longer
+// than the visibility timeout they were reserved with.
+func (rb *RedisStreamMessageBroker) RequeueExpired(ctx context.Context, queue string) error {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return err
+	}
+
+	start := "-"
+	for {
+		pending, err := rb.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: queue,
+			Group:  streamGroup,
+			Start:  start,
+			End:    "+",
+			Count:  streamClaimBatch,
+		}).Result()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(pending))
+		for i, entry := range pending {
+			ids[i] = entry.ID
+		}
+		timeouts, err := rb.client.HMGet(ctx, queue+":visibility", ids...).Result()
+		if err != nil {
+			return err
+		}
+
+		for i, entry := range pending {
+			if entry.Consumer == streamReleasedConsumer {
+				continue
+			}
+			visibility := streamDefaultVisibility
+			if ms, ok := timeouts[i].(string); ok {
+				if parsed, err := strconv.ParseInt(ms, 10, 64); err == nil {
+					visibility = time.Duration(parsed) * time.Millisecond
+				}
+			}
+			if entry.Idle < visibility {
+				continue
+			}
+			if err := rb.release(ctx, queue, entry.ID, visibility); err != nil {
+				return err
+			}
+		}
+
+		if len(pending) < streamClaimBatch {
+			return nil
+		}
+		start = "(" + pending[len(pending)-1].ID
+	}
+}
+
+// RemoveConsumer deletes consumer from the group of queue. Entries it still
+// holds are handed to streamDetachedConsumer first, reserved for what is left
+// of their visibility timeout, so they can still be acked or extended.
+func (rb *RedisStreamMessageBroker) RemoveConsumer(ctx context.Context, queue string, consumer string) error {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return err
+	}
+
+	return streamRemoveConsumerScript.Run(ctx, rb.client,
+		[]string{queue},
+		streamGroup, consumer, streamDetachedConsumer, streamClaimBatch,
+	).Err()
+}
+
+// Extend restarts the idle time of a pending entry, keeping its owner, and
+// records the new visibility timeout.
+func (rb *RedisStreamMessageBroker) Extend(ctx context.Context, queue string, id string, visibilityTimeout time.Duration) (bool, error) {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return false, err
+	}
+
+	extended, err := streamExtendScript.Run(ctx, rb.client,
+		[]string{queue, queue + ":visibility"},
+		id, visibilityTimeout.Milliseconds(), streamGroup, streamReleasedConsumer, streamDefaultVisibility.Milliseconds(),
+	).Int()
+	return extended == 1, err
+}
+
+// DeadLetter acknowledges the entry, deletes it from the stream and appends it
+// to the queue+":dead" list
+func (rb *RedisStreamMessageBroker) DeadLetter(ctx context.Context, queue string, id string, lastError string) error {
+	queue = rb.key(queue)
+	pipe := rb.client.Pipeline()
+	entries := pipe.XRangeN(ctx, queue, id, id, 1)
+	attempts := pipe.HGet(ctx, queue+":attempts", id)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return err
+	}
+	if len(entries.Val()) == 0 {
+		return nil
+	}
+
+	envelope := streamEnvelope(entries.Val()[0])
+	envelope.Attempts, _ = strconv.Atoi(attempts.Val())
+	deadLetter, err := newRedisDeadLetter(envelope, lastError)
+	if err != nil {
+		return err
+	}
+
+	pipe = rb.client.TxPipeline()
+	pipe.XAck(ctx, queue, streamGroup, id)
+	pipe.XDel(ctx, queue, id)
+	pipe.HDel(ctx, queue+":visibility", id)
+	pipe.HDel(ctx, queue+":attempts", id)
+	pipe.RPush(ctx, queue+":dead", deadLetter)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (rb *RedisStreamMessageBroker) ListDeadLetters(ctx context.Context, queue string) ([]DeadLetter, error) {
+	return redisListDeadLetters(ctx, rb.client, rb.key(queue))
+}
+
+func (rb *RedisStreamMessageBroker) DeleteDeadLetter(ctx context.Context, queue string, id string) (bool, error) {
+	return redisDeleteDeadLetter(ctx, rb.client, rb.key(queue), id)
+}
+
+func (rb *RedisStreamMessageBroker) PurgeDeadLetters(ctx context.Context, queue string) (int, error) {
+	return redisPurgeDeadLetters(ctx, rb.client, rb.key(queue))
+}
+
+// pendingReservations returns the reserved entries of queue, those owned by a
+// consumer other than streamReleasedConsumer, with their visibility deadline.
+func (rb *RedisStreamMessageBroker) pendingReservations(ctx context.Context, queue string) (map[string]time.Time, error) {
+	reservations := map[string]time.Time{}
+	now := time.Now()
+
+	start := "-"
+	for {
+		pending, err := rb.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: queue,
+			Group:  streamGroup,
+			Start:  start,
+			End:    "+",
+			Count:  streamClaimBatch,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		if len(pending) == 0 {
+			return reservations, nil
+		}
+
+		ids := make([]string, len(pending))
+		for i, entry := range pending {
+			ids[i] = entry.ID
+		}
+		timeouts, err := rb.client.HMGet(ctx, queue+":visibility", ids...).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, entry := range pending {
+			if entry.Consumer == streamReleasedConsumer {
+				continue
+			}
+			visibility := streamDefaultVisibility
+			if ms, ok := timeouts[i].(string); ok {
+				if parsed, err := strconv.ParseInt(ms, 10, 64); err == nil {
+					visibility = time.Duration(parsed) * time.Millisecond
+				}
+			}
+			reservations[entry.ID] = now.Add(visibility - entry.Idle)
+		}
+
+		if len(pending) < streamClaimBatch {
+			return reservations, nil
+		}
+		start = "(" + pending[len(pending)-1].ID
+	}
+}
+
+// Stats counts stream entries outside the reserved ones as ready, released
+// entries included. Acked entries are deleted, so the first entry that is
+// not reserved is the oldest ready one.
+func (rb *RedisStreamMessageBroker) Stats(ctx context.Context, queue string) (QueueStats, error) {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return QueueStats{}, err
+	}
+
+	reservations, err := rb.pendingReservations(ctx, queue)
+	if err != nil {
+		return QueueStats{}, err
+	}
+
+	pipe := rb.client.Pipeline()
+	length := pipe.XLen(ctx, queue)
+	delayed := pipe.ZCard(ctx, queue+":delayed")
+	dead := pipe.LLen(ctx, queue+":dead")
+	entries := pipe.XRangeN(ctx, queue, "-", "+", int64(len(reservations))+1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return QueueStats{}, err
+	}
+
+	stats := QueueStats{
+		Ready:    int(length.Val()) - len(reservations),
+		Delayed:  int(delayed.Val()),
+		Reserved: len(reservations),
+		Dead:     int(dead.Val()),
+	}
+	now := time.Now()
+	for _, deadline := range reservations {
+		if now.Before(deadline) {
+			stats.InFlight++
+		}
+	}
+	for _, entry := range entries.Val() {
+		if _, ok := reservations[entry.ID]; !ok {
+			enqueuedAt := streamEnvelope(entry).EnqueuedAt
+			stats.OldestEnqueuedAt = &enqueuedAt
+			break
+		}
+	}
+	return stats, nil
+}
+
+// ListMessages returns stream entries by ID, then delayed messages by due
+// time. Delayed messages have no enqueue time.
+func (rb *RedisStreamMessageBroker) ListMessages(ctx context.Context, queue string, limit int) ([]QueuedMessage, error) {
+	queue = rb.key(queue)
+	if err := rb.ensureGroup(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	reservations, err := rb.pendingReservations(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := int64(-1)
+	pipe := rb.client.Pipeline()
+	var entries *redis.XMessageSliceCmd
+	if limit > 0 {
+		stop = int64(limit) - 1
+		entries = pipe.XRangeN(ctx, queue, "-", "+", int64(limit))
+	} else {
+		entries = pipe.XRange(ctx, queue, "-", "+")
+	}
+	delayed := pipe.ZRangeWithScores(ctx, queue+":delayed", 0, stop)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	messages := []QueuedMessage{}
+	ids := []string{}
+	for _, entry := range entries.Val() {
+		if _, ok := entry.Values[streamPayloadField].(string); !ok {
+			continue
+		}
+		message := QueuedMessage{Envelope: streamEnvelope(entry), State: MessageStateReady}
+		if deadline, ok := reservations[entry.ID]; ok {
+			message.State = MessageStateReserved
+			message.VisibleAt = &deadline
+		}
+		messages = append(messages, message)
+		ids = append(ids, entry.ID)
+	}
+	if len(ids) > 0 {
+		attempts, err := rb.client.HMGet(ctx, queue+":attempts", ids...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i := range messages {
+			if count, ok := attempts[i].(string); ok {
+				messages[i].Attempts, _ = strconv.Atoi(count)
+			}
+		}
+	}
+
+	if len(delayed.Val()) > 0 {
+		delayedIDs := make([]string, len(delayed.Val()))
+		for i, member := range delayed.Val() {
+			delayedIDs[i], _ = member.Member.(string)
+		}
+		payloads, err := rb.client.HMGet(ctx, queue+":delayed:jobs", delayedIDs...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, member := range delayed.Val() {
+			payload, ok := payloads[i].(string)
+			if !ok {
+				continue
+			}
+			deliverAt := time.UnixMilli(int64(member.Score))
+			messages = append(messages, QueuedMessage{
+				Envelope:  Envelope{ID: delayedIDs[i], Payload: []byte(payload)},
+				State:     MessageStateDelayed,
+				VisibleAt: &deliverAt,
+			})
+		}
+	}
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+// isStreamEntryID tells entry IDs, <ms>-<seq>, from IDs of delayed messages
+func isStreamEntryID(id string) bool {
+	return strings.Contains(id, "-")
+}
+
+func (rb *RedisStreamMessageBroker) Delete(ctx context.Context, queue string, id string) (bool, error) {
+	queue = rb.key(queue)
+
+	if !isStreamEntryID(id) {
+		pipe := rb.client.TxPipeline()
+		removed := pipe.ZRem(ctx, queue+":delayed", id)
+		pipe.HDel(ctx, queue+":delayed:jobs", id)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return false, err
+		}
+		return removed.Val() > 0, nil
+	}
+
+	pipe := rb.client.TxPipeline()
+	pipe.XAck(ctx, queue, streamGroup, id)
+	removed := pipe.XDel(ctx, queue, id)
+	pipe.HDel(ctx, queue+":visibility", id)
+	pipe.HDel(ctx, queue+":attempts", id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return removed.Val() > 0, nil
+}
+
+// Purge deletes the stream and creates the consumer group again in the same
+// transaction, so consumers never see the queue without its group.
+func (rb *RedisStreamMessageBroker) Purge(ctx context.Context, queue string) (int, error) {
+	queue = rb.key(queue)
+	pipe := rb.client.TxPipeline()
+	length := pipe.XLen(ctx, queue)
+	delayed := pipe.ZCard(ctx, queue+":delayed")
+	pipe.Del(ctx, queue, queue+":visibility", queue+":attempts", queue+":delayed", queue+":delayed:jobs")
+	pipe.XGroupCreateMkStream(ctx, queue, streamGroup, "0")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(length.Val() + delayed.Val()), nil
+}
+
+// Close closes the Redis connection
+func (rb *RedisStreamMessageBroker) Close() error {
+	return rb.client.Close()
+}
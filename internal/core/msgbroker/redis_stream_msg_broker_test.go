@@ -0,0 +1,41 @@
+package msgbroker_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/msgbroker/brokertest"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisAddr returns the Redis server named by NO_NOODLE_TEST_REDIS_ADDR and
+// skips the test when it is unset.
+func redisAddr(t *testing.T) string {
+	t.Helper()
+
+	addr := os.Getenv("NO_NOODLE_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("NO_NOODLE_TEST_REDIS_ADDR is not set")
+	}
+	return addr
+}
+
+func newRedisClient(t *testing.T, addr string) redis.UniversalClient {
+	t.Helper()
+
+	client, err := util.NewRedis(&redis.UniversalOptions{Addrs: []string{addr}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRedisStreamMessageBroker(t *testing.T) {
+	addr := redisAddr(t)
+
+	brokertest.Run(t, func(t *testing.T) msgbroker.MessageBroker {
+		return msgbroker.NewRedisStreamMessageBroker(newRedisClient(t, addr))
+	})
+}
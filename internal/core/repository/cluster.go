@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func renewInstance(db *sql.DB, instance entitites.CoreInstance, placeholder func(n int) string) error {
+	query := "INSERT INTO core_instance (instance_id, start_date, lease_expire_date) VALUES " + inPlaceholders(3, 1, placeholder) +
+		" ON CONFLICT (instance_id) DO UPDATE SET lease_expire_date = excluded.lease_expire_date"
+	_, err := db.Exec(query, instance.InstanceID, instance.StartDate, instance.LeaseExpireDate)
+	return err
+}
+
+func removeInstance(db *sql.DB, instanceID string, placeholder func(n int) string) error {
+	_, err := db.Exec("DELETE FROM core_instance WHERE instance_id = "+placeholder(1), instanceID)
+	return err
+}
+
+// listInstances returns the instances whose lease has not run out at now,
+// ordered by instance ID.
+func listInstances(db *sql.DB, now time.Time, placeholder func(n int) string) ([]entitites.CoreInstance, error) {
+	rows, err := db.Query("SELECT instance_id, start_date, lease_expire_date FROM core_instance WHERE lease_expire_date > "+placeholder(1)+" ORDER BY instance_id", now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	instances := []entitites.CoreInstance{}
+	for rows.Next() {
+		var instance entitites.CoreInstance
+		if err := rows.Scan(&instance.InstanceID, &instance.StartDate, &instance.LeaseExpireDate); err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, rows.Err()
+}
+
+func removeExpiredInstances(db *sql.DB, before time.Time, placeholder func(n int) string) (int, error) {
+	result, err := db.Exec("DELETE FROM core_instance WHERE lease_expire_date < "+placeholder(1), before)
+	if err != nil {
+		return 0, err
+	}
+	removed, err := result.RowsAffected()
+	return int(removed), err
+}
+
+// claimSubscriber leases the subscription of sessionKey to instanceID unless
+// another instance holds a lease on it that has not run out at now. Claiming
+// a subscription the instance already holds renews the lease.
+func claimSubscriber(db *sql.DB, sessionKey string, instanceID string, now time.Time, leaseExpireDate time.Time, placeholder func(n int) string) (bool, error) {
+	query := "UPDATE subscription SET owner_instance_id = " + placeholder(1) +
+		", owner_lease_expire_date = " + placeholder(2) +
+		" WHERE session_key = " + placeholder(3) +
+		" AND (owner_instance_id IS NULL OR owner_instance_id = " + placeholder(4) +
+		" OR owner_lease_expire_date < " + placeholder(5) + ")"
+	result, err := db.Exec(query, instanceID, leaseExpireDate, sessionKey, instanceID, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// releaseSubscribers gives up the leases instanceID holds on the
+// subscriptions of sessionKeys.
+func releaseSubscribers(db *sql.DB, instanceID string, sessionKeys []string, placeholder func(n int) string) error {
+	if len(sessionKeys) == 0 {
+		return nil
+	}
+
+	args := append([]any{instanceID}, stringsToArgs(sessionKeys)...)
+	query := "UPDATE subscription SET owner_instance_id = NULL, owner_lease_expire_date = NULL WHERE owner_instance_id = " + placeholder(1) +
+		" AND session_key IN " + inPlaceholders(len(sessionKeys), 2, placeholder)
+	_, err := db.Exec(query, args...)
+	return err
+}
+
+func renewSubscriber(db *sql.DB, sessionKey string, renewDate time.Time, placeholder func(n int) string) (bool, error) {
+	result, err := db.Exec("UPDATE subscription SET renew_date = "+placeholder(1)+" WHERE session_key = "+placeholder(2), renewDate, sessionKey)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// claimClusterLease leases the duty name to instanceID unless another
+// instance holds a lease on it that has not run out at now.
+func claimClusterLease(db *sql.DB, name string, instanceID string, now time.Time, leaseExpireDate time.Time, placeholder func(n int) string) (bool, error) {
+	query := strings.Join([]string{
+		"INSERT INTO cluster_lease (name, owner_instance_id, lease_expire_date) VALUES " + inPlaceholders(3, 1, placeholder),
+		"ON CONFLICT (name) DO UPDATE SET owner_instance_id = excluded.owner_instance_id, lease_expire_date = excluded.lease_expire_date",
+		"WHERE cluster_lease.owner_instance_id = excluded.owner_instance_id OR cluster_lease.lease_expire_date < " + placeholder(4),
+	}, " ")
+	result, err := db.Exec(query, name, instanceID, leaseExpireDate, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func releaseClusterLeases(db *sql.DB, instanceID string, placeholder func(n int) string) error {
+	_, err := db.Exec("DELETE FROM cluster_lease WHERE owner_instance_id = "+placeholder(1), instanceID)
+	return err
+}
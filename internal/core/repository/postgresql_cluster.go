@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func (p *PostgreSQLNoNoodleWorkflow) RenewInstance(instance entitites.CoreInstance) error {
+	return renewInstance(p.db, instance, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) RemoveInstance(instanceID string) error {
+	return removeInstance(p.db, instanceID, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ListInstances(now time.Time) ([]entitites.CoreInstance, error) {
+	return listInstances(p.db, now, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) RemoveExpiredInstances(before time.Time) (int, error) {
+	return removeExpiredInstances(p.db, before, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ClaimSubscriber(sessionKey string, instanceID string, now time.Time, leaseExpireDate time.Time) (bool, error) {
+	return claimSubscriber(p.db, sessionKey, instanceID, now, leaseExpireDate, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ReleaseSubscribers(instanceID string, sessionKeys []string) error {
+	return releaseSubscribers(p.db, instanceID, sessionKeys, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) RenewSubscriber(sessionKey string, renewDate time.Time) (bool, error) {
+	return renewSubscriber(p.db, sessionKey, renewDate, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ClaimClusterLease(name string, instanceID string, now time.Time, leaseExpireDate time.Time) (bool, error) {
+	return claimClusterLease(p.db, name, instanceID, now, leaseExpireDate, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ReleaseClusterLeases(instanceID string) error {
+	return releaseClusterLeases(p.db, instanceID, postgresPlaceholder)
+}
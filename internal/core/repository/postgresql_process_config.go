@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
 )
 
+// InsertProcessConfig deploys config as the next version of its process and
+// writes the assigned version back into config.Version.
 func (p *PostgreSQLNoNoodleWorkflow) InsertProcessConfig(tx *sql.Tx, config *entitites.ProcessConfig) error {
 
 	// Marshal maps to JSON
@@ -18,39 +21,97 @@ func (p *PostgreSQLNoNoodleWorkflow) InsertProcessConfig(tx *sql.Tx, config *ent
 	if err != nil {
 		return err
 	}
+	mapTaskLeaseJSON, err := marshalTaskLease(config.MapTaskLeaseSeconds)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO process (process_id) VALUES ($1) ON CONFLICT (process_id) DO NOTHING", config.ProcessID)
+	if err != nil {
+		return err
+	}
 
-	_, err = tx.Exec("INSERT INTO process_config (process_id, map_stage_task, map_stage_ready) VALUES ($1, $2, $3)", config.ProcessID, mapStageTaskJSON, mapStageReadyJSON)
+	// Bumping the counter locks the process row, so concurrent deploys get
+	// distinct versions and a deleted version is never handed out again
+	var enabled bool
+	var version int
+	err = tx.QueryRow("UPDATE process SET last_version = last_version + 1 WHERE process_id = $1 RETURNING enabled, last_version", config.ProcessID).Scan(&enabled, &version)
 	if err != nil {
 		return err
 	}
+
+	createDate := util.GetCurrentTime()
+	_, err = tx.Exec("INSERT INTO process_config (process_id, version, map_stage_task, map_stage_ready, map_task_lease, create_date) VALUES ($1, $2, $3, $4, $5, $6)", config.ProcessID, version, mapStageTaskJSON, mapStageReadyJSON, mapTaskLeaseJSON, createDate)
+	if err != nil {
+		return err
+	}
+
+	config.Version = version
+	config.Enabled = enabled
+	config.CreateDate = createDate
 	return nil
 }
 
+// GetProcessConfigByProcessID returns the latest deployed version of a process.
 func (p *PostgreSQLNoNoodleWorkflow) GetProcessConfigByProcessID(tx *sql.Tx, ProcessID string) (entitites.ProcessConfig, error) {
-	var config entitites.ProcessConfig
-	var mapStageTaskJSON []byte
-	var mapStageReadyJSON []byte
+	return scanProcessConfig(tx.QueryRow("SELECT "+processConfigColumns+" FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.process_id = $1 ORDER BY pc.version DESC LIMIT 1", ProcessID))
+}
 
-	err := tx.QueryRow("SELECT process_id, map_stage_task, map_stage_ready FROM process_config WHERE process_id = $1", ProcessID).Scan(&config.ProcessID, &mapStageTaskJSON, &mapStageReadyJSON)
+func (p *PostgreSQLNoNoodleWorkflow) GetProcessConfigByVersion(tx *sql.Tx, ProcessID string, version int) (entitites.ProcessConfig, error) {
+	return scanProcessConfig(tx.QueryRow("SELECT "+processConfigColumns+" FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.process_id = $1 AND pc.version = $2", ProcessID, version))
+}
+
+// ListProcessConfigs returns the latest version of every process.
+func (p *PostgreSQLNoNoodleWorkflow) ListProcessConfigs(tx *sql.Tx) ([]entitites.ProcessConfig, error) {
+	rows, err := tx.Query("SELECT DISTINCT ON (pc.process_id) " + processConfigColumns + " FROM process_config pc JOIN process p ON p.process_id = pc.process_id ORDER BY pc.process_id, pc.version DESC")
 	if err != nil {
-		return config, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	err = json.Unmarshal(mapStageTaskJSON, &config.MapStageTask)
+	return scanProcessConfigs(rows)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ListProcessConfigVersions(tx *sql.Tx, ProcessID string) ([]entitites.ProcessConfig, error) {
+	rows, err := tx.Query("SELECT "+processConfigColumns+" FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.process_id = $1 ORDER BY pc.version", ProcessID)
 	if err != nil {
-		return config, err
+		return nil, err
 	}
-	err = json.Unmarshal(mapStageReadyJSON, &config.MapStageReady)
+	defer rows.Close()
+
+	return scanProcessConfigs(rows)
+}
+
+// SetProcessEnabled enables or disables every version of a process, it returns
+// false when the process does not exist.
+func (p *PostgreSQLNoNoodleWorkflow) SetProcessEnabled(tx *sql.Tx, ProcessID string, enabled bool) (bool, error) {
+	result, err := tx.Exec("UPDATE process SET enabled = $1, update_date = $2 WHERE process_id = $3", enabled, util.GetCurrentTime(), ProcessID)
 	if err != nil {
-		return config, err
+		return false, err
 	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
 
-	return config, nil
+func (p *PostgreSQLNoNoodleWorkflow) CountWorkflowsByProcessVersion(tx *sql.Tx, ProcessID string, version int) (int, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM workflow WHERE process_id = $1 AND process_version = $2", ProcessID, version).Scan(&count)
+	return count, err
+}
+
+// DeleteProcessConfig removes one version, it returns false when the version does not exist.
+func (p *PostgreSQLNoNoodleWorkflow) DeleteProcessConfig(tx *sql.Tx, ProcessID string, version int) (bool, error) {
+	result, err := tx.Exec("DELETE FROM process_config WHERE process_id = $1 AND version = $2", ProcessID, version)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
 }
 
 func (p *PostgreSQLNoNoodleWorkflow) GetMapStageTaskByProcessID(tx *sql.Tx, ProcessID string) (map[string][]string, error) {
 	var mapStageTaskJSON []byte
-	err := tx.QueryRow("SELECT map_stage_task FROM process_config WHERE process_id = $1", ProcessID).Scan(&mapStageTaskJSON)
+	err := tx.QueryRow("SELECT map_stage_task FROM process_config WHERE process_id = $1 ORDER BY version DESC LIMIT 1", ProcessID).Scan(&mapStageTaskJSON)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+func (p *PostgreSQLNoNoodleWorkflow) SetRetentionPolicy(tx *sql.Tx, policy entitites.RetentionPolicy) (bool, error) {
+	result, err := tx.Exec("UPDATE process SET completed_retention_days = $1, failed_retention_days = $2, archive_before_delete = $3, update_date = $4 WHERE process_id = $5", policy.CompletedRetentionDays, policy.FailedRetentionDays, policy.Archive, util.GetCurrentTime(), policy.ProcessID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ListRetentionPolicies() ([]entitites.RetentionPolicy, error) {
+	rows, err := p.db.Query("SELECT process_id, completed_retention_days, failed_retention_days, archive_before_delete FROM process ORDER BY process_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []entitites.RetentionPolicy{}
+	for rows.Next() {
+		var policy entitites.RetentionPolicy
+		err := rows.Scan(&policy.ProcessID, &policy.CompletedRetentionDays, &policy.FailedRetentionDays, &policy.Archive)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// GetExpiredWorkflowIDs returns up to limit workflows of a process that reached
+// status before the given time, served by idx_workflow_process_status_update_date.
+func (p *PostgreSQLNoNoodleWorkflow) GetExpiredWorkflowIDs(processID string, status string, before time.Time, limit int) ([]string, error) {
+	rows, err := p.db.Query("SELECT workflow_id FROM workflow WHERE process_id = $1 AND status = $2 AND update_date < $3 ORDER BY update_date LIMIT $4", processID, status, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) DeleteWorkflows(tx *sql.Tx, workflowIDs []string) (int, error) {
+	return deleteWorkflows(tx, workflowIDs, postgresPlaceholder)
+}
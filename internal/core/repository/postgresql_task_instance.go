@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+const taskInstanceColumns = "workflow_id, process_id, task, status, attempts, worker, job_id, lease_expire_date, start_date, end_date, create_date, update_date"
+
+func (p *PostgreSQLNoNoodleWorkflow) InsertTaskInstances(tx *sql.Tx, workflowID string, processID string, taskStatus map[string]entitites.TaskStatusData) error {
+	stmt, err := tx.Prepare("INSERT INTO task_instance (workflow_id, process_id, task, status, attempts, create_date, update_date) VALUES ($1, $2, $3, $4, $5, $6, $6)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for task, data := range taskStatus {
+		_, err = stmt.Exec(workflowID, processID, task, data.Status, data.Attempts, data.UpdateDate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) UpdateTaskStatus(tx *sql.Tx, workflowID string, task string, status string, updateDate time.Time) error {
+	attemptsInc, startDate, endDate := taskStatusTransition(status, updateDate)
+
+	query := `
+		UPDATE task_instance
+		SET status = $1,
+			update_date = $2,
+			attempts = attempts + $3,
+			start_date = COALESCE($4, start_date),
+			end_date = $5,
+			job_id = CASE WHEN $5 IS NULL THEN job_id END,
+			lease_expire_date = CASE WHEN $5 IS NULL THEN lease_expire_date END
+		WHERE workflow_id = $6 AND task = $7
+	`
+
+	result, err := tx.Exec(query, status, updateDate, attemptsInc, startDate, endDate, workflowID, task)
+	if err != nil {
+		return err
+	}
+	err = checkTaskInstanceUpdated(result, workflowID, task)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE workflow SET update_date = $1 WHERE workflow_id = $2", updateDate, workflowID)
+	return err
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) UpdateTaskWorker(workflowID string, task string, worker string) error {
+	_, err := p.db.Exec("UPDATE task_instance SET worker = $1 WHERE workflow_id = $2 AND task = $3", worker, workflowID, task)
+	return err
+}
+
+// UpdateTaskLease records the job delivered for a task and when its lease
+// expires, it returns false when the task is already finished.
+func (p *PostgreSQLNoNoodleWorkflow) UpdateTaskLease(workflowID string, task string, jobID string, leaseExpireDate time.Time) (bool, error) {
+	result, err := p.db.Exec("UPDATE task_instance SET job_id = $1, lease_expire_date = $2 WHERE workflow_id = $3 AND task = $4 AND end_date IS NULL", jobID, leaseExpireDate, workflowID, task)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) GetTaskInstancesByWorkflowID(tx *sql.Tx, workflowID string) ([]entitites.TaskInstance, error) {
+	rows, err := tx.Query("SELECT "+taskInstanceColumns+" FROM task_instance WHERE workflow_id = $1", workflowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskInstances(rows)
+}
+
+// GetTaskInstancesByStatus lists instances of one task type in a given status,
+// served by idx_task_instance_process_task_status.
+func (p *PostgreSQLNoNoodleWorkflow) GetTaskInstancesByStatus(processID string, task string, status string, limit int) ([]entitites.TaskInstance, error) {
+	rows, err := p.db.Query("SELECT "+taskInstanceColumns+" FROM task_instance WHERE process_id = $1 AND task = $2 AND status = $3 ORDER BY update_date LIMIT $4", processID, task, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskInstances(rows)
+}
+
+// GetStaleTaskInstances lists instances that have been in a status since before
+// the given time, served by idx_task_instance_status_update_date.
+func (p *PostgreSQLNoNoodleWorkflow) GetStaleTaskInstances(status string, before time.Time, limit int) ([]entitites.TaskInstance, error) {
+	rows, err := p.db.Query("SELECT "+taskInstanceColumns+" FROM task_instance WHERE status = $1 AND update_date < $2 ORDER BY update_date LIMIT $3", status, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskInstances(rows)
+}
+
+// taskStatusTransition derives the bookkeeping columns for a status change:
+// every activation counts as an attempt, and only finished tasks carry an end date.
+func taskStatusTransition(status string, updateDate time.Time) (int, *time.Time, *time.Time) {
+	switch status {
+	case entitites.TASK_STATUS_IN_ACTIVE:
+		return 1, &updateDate, nil
+	case entitites.TASK_STATUS_COMPLETED, entitites.TASK_STATUS_FAILED:
+		return 0, nil, &updateDate
+	default:
+		return 0, nil, nil
+	}
+}
+
+func checkTaskInstanceUpdated(result sql.Result, workflowID string, task string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("task %s not found in workflow %s", task, workflowID)
+	}
+	return nil
+}
+
+func scanTaskInstances(rows *sql.Rows) ([]entitites.TaskInstance, error) {
+	taskInstances := []entitites.TaskInstance{}
+	for rows.Next() {
+		var taskInstance entitites.TaskInstance
+		var worker, jobID sql.NullString
+		var leaseExpireDate, startDate, endDate sql.NullTime
+		err := rows.Scan(
+			&taskInstance.WorkflowID,
+			&taskInstance.ProcessID,
+			&taskInstance.Task,
+			&taskInstance.Status,
+			&taskInstance.Attempts,
+			&worker,
+			&jobID,
+			&leaseExpireDate,
+			&startDate,
+			&endDate,
+			&taskInstance.CreateDate,
+			&taskInstance.UpdateDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		taskInstance.Worker = worker.String
+		taskInstance.JobID = jobID.String
+		if leaseExpireDate.Valid {
+			taskInstance.LeaseExpireDate = &leaseExpireDate.Time
+		}
+		if startDate.Valid {
+			taskInstance.StartDate = &startDate.Time
+		}
+		if endDate.Valid {
+			taskInstance.EndDate = &endDate.Time
+		}
+		taskInstances = append(taskInstances, taskInstance)
+	}
+	return taskInstances, rows.Err()
+}
+
+func taskInstancesToTaskStatus(taskInstances []entitites.TaskInstance) map[string]entitites.TaskStatusData {
+	taskStatus := make(map[string]entitites.TaskStatusData, len(taskInstances))
+	for _, taskInstance := range taskInstances {
+		taskStatus[taskInstance.Task] = taskInstance.ToTaskStatusData()
+	}
+	return taskStatus
+}
@@ -3,88 +3,55 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
-
 	"time"
 
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
 )
 
-// func (p *PostgreSQLNoNoodleWorkflow) GetTaskStatusFromWorkflowID(tx *sql.Tx, workflowID string) (map[string]entitites.TaskStatusData, error) {
-// 	var workflow entitites.Workflow
-// 	var taskStatusJSON []byte
-
-// 	err := tx.QueryRow("SELECT task_status FROM workflow WHERE workflow_id = $1", workflowID).Scan(&taskStatusJSON)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	err = json.Unmarshal(taskStatusJSON, &workflow.TaskStatus)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	return workflow.TaskStatus, nil
-// }
-
 func (p *PostgreSQLNoNoodleWorkflow) GetWorkflowByWorkflowID(tx *sql.Tx, workflowID string) (*entitites.Workflow, error) {
-	var workflow entitites.Workflow
-	var taskStatusJSON []byte
-	var publishedStageJSON []byte
 
-	err := tx.QueryRow("SELECT workflow_id, process_id, task_status, published_stage, create_date FROM workflow WHERE workflow_id = $1", workflowID).Scan(&workflow.WorkflowID, &workflow.ProcessID, &taskStatusJSON, &publishedStageJSON, &workflow.CreateDate)
+	workflow, err := scanWorkflow(tx.QueryRow("SELECT "+workflowColumns+" FROM workflow WHERE workflow_id = $1", workflowID))
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(taskStatusJSON, &workflow.TaskStatus)
+	taskInstances, err := p.GetTaskInstancesByWorkflowID(tx, workflowID)
 	if err != nil {
 		return nil, err
 	}
+	workflow.TaskStatus = taskInstancesToTaskStatus(taskInstances)
 
-	err = json.Unmarshal(publishedStageJSON, &workflow.PublishedStage)
-	if err != nil {
-		return nil, err
-	}
+	return workflow, nil
+}
 
-	return &workflow, nil
+func (p *PostgreSQLNoNoodleWorkflow) SearchWorkflows(query entitites.WorkflowQuery) (*entitites.WorkflowPage, error) {
+	return searchWorkflows(p.db, query, postgresPlaceholder)
 }
 
-func (p *PostgreSQLNoNoodleWorkflow) InitializeWorkflow(tx *sql.Tx, workflowID string, processID string, taskStatus map[string]entitites.TaskStatusData, publishedStage map[string]bool) error {
+func (p *PostgreSQLNoNoodleWorkflow) InitializeWorkflow(tx *sql.Tx, workflowID string, processID string, processVersion int, businessKey string, taskStatus map[string]entitites.TaskStatusData, publishedStage map[string]bool) error {
 
-	taskStatusBytes, err := json.Marshal(taskStatus)
+	publishedStageBytes, err := json.Marshal(publishedStage)
 	if err != nil {
 		return err
 	}
 
-	publishedStageBytes, err := json.Marshal(publishedStage)
-	if err != nil {
-		return err
+	var businessKeyValue sql.NullString
+	if businessKey != "" {
+		businessKeyValue = sql.NullString{String: businessKey, Valid: true}
 	}
 
-	_, err = tx.Exec("INSERT INTO workflow (workflow_id, process_id, task_status, published_stage, create_date) VALUES ($1, $2, $3, $4, $5)", workflowID, processID, taskStatusBytes, publishedStageBytes, util.GetCurrentTime())
+	now := util.GetCurrentTime()
+	_, err = tx.Exec("INSERT INTO workflow (workflow_id, process_id, process_version, status, business_key, published_stage, create_date, update_date) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)", workflowID, processID, processVersion, entitites.WORKFLOW_STATUS_RUNNING, businessKeyValue, publishedStageBytes, now, now)
 	if err != nil {
 		return err
 	}
-	return nil
-}
 
-func (p *PostgreSQLNoNoodleWorkflow) UpdateTaskStatus(tx *sql.Tx, workflowID string, task string, status string, updateDate time.Time) error {
-	// Use PostgreSQL JSONB operators to update specific keys directly
-	query := `
-		UPDATE workflow 
-		SET task_status = jsonb_set(
-			jsonb_set(
-				task_status, 
-				ARRAY[$1, 'status'], 
-				to_jsonb($2::text)
-			),
-			ARRAY[$1, 'update_date'], 
-			to_jsonb($3::text)
-		)
-		WHERE workflow_id = $4
-	`
+	return p.InsertTaskInstances(tx, workflowID, processID, taskStatus)
+}
 
-	_, err := tx.Exec(query, task, status, updateDate.Format(time.RFC3339Nano), workflowID)
+func (p *PostgreSQLNoNoodleWorkflow) UpdateWorkflowStatus(tx *sql.Tx, workflowID string, status string, updateDate time.Time) error {
+	_, err := tx.Exec("UPDATE workflow SET status = $1, update_date = $2 WHERE workflow_id = $3", status, updateDate, workflowID)
 	return err
 }
 
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func (p *PostgreSQLNoNoodleWorkflow) InsertWorkflowEvents(tx *sql.Tx, events []entitites.WorkflowEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Held until the transaction ends, see workflowEventLock
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", workflowEventLock); err != nil {
+		return err
+	}
+	return insertWorkflowEvents(tx, events, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) ListWorkflowEvents(query entitites.WorkflowEventQuery) ([]entitites.WorkflowEvent, error) {
+	return listWorkflowEvents(p.db, query, postgresPlaceholder)
+}
+
+func (p *PostgreSQLNoNoodleWorkflow) GetLatestWorkflowEventID() (int64, error) {
+	return getLatestWorkflowEventID(p.db)
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+const processConfigColumns = "pc.process_id, pc.version, p.enabled, pc.map_stage_task, pc.map_stage_ready, pc.map_task_lease, pc.create_date"
+
+func scanProcessConfig(row rowScanner) (entitites.ProcessConfig, error) {
+	var config entitites.ProcessConfig
+	var mapStageTaskJSON []byte
+	var mapStageReadyJSON []byte
+	var mapTaskLeaseJSON []byte
+
+	err := row.Scan(&config.ProcessID, &config.Version, &config.Enabled, &mapStageTaskJSON, &mapStageReadyJSON, &mapTaskLeaseJSON, &config.CreateDate)
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(mapStageTaskJSON, &config.MapStageTask)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(mapStageReadyJSON, &config.MapStageReady)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(mapTaskLeaseJSON, &config.MapTaskLeaseSeconds)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// marshalTaskLease stores a missing lease map as {}, the column is NOT NULL
+func marshalTaskLease(mapTaskLeaseSeconds map[string]int) ([]byte, error) {
+	if mapTaskLeaseSeconds == nil {
+		mapTaskLeaseSeconds = map[string]int{}
+	}
+	return json.Marshal(mapTaskLeaseSeconds)
+}
+
+func scanProcessConfigs(rows *sql.Rows) ([]entitites.ProcessConfig, error) {
+	configs := []entitites.ProcessConfig{}
+	for rows.Next() {
+		config, err := scanProcessConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, rows.Err()
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// NoNoodleWorkflowRepository is the storage contract used by the workflow core.
+// Every backend (PostgreSQL, SQLite) goes through database/sql so the core can
+// keep driving its own transactions.
+type NoNoodleWorkflowRepository interface {
+	GetDB() *sql.DB
+
+	InsertProcessConfig(tx *sql.Tx, config *entitites.ProcessConfig) error
+	GetProcessConfigByProcessID(tx *sql.Tx, ProcessID string) (entitites.ProcessConfig, error)
+	GetProcessConfigByVersion(tx *sql.Tx, ProcessID string, version int) (entitites.ProcessConfig, error)
+	ListProcessConfigs(tx *sql.Tx) ([]entitites.ProcessConfig, error)
+	ListProcessConfigVersions(tx *sql.Tx, ProcessID string) ([]entitites.ProcessConfig, error)
+	SetProcessEnabled(tx *sql.Tx, ProcessID string, enabled bool) (bool, error)
+	CountWorkflowsByProcessVersion(tx *sql.Tx, ProcessID string, version int) (int, error)
+	DeleteProcessConfig(tx *sql.Tx, ProcessID string, version int) (bool, error)
+	GetMapStageTaskByProcessID(tx *sql.Tx, ProcessID string) (map[string][]string, error)
+
+	GetWorkflowByWorkflowID(tx *sql.Tx, workflowID string) (*entitites.Workflow, error)
+	SearchWorkflows(query entitites.WorkflowQuery) (*entitites.WorkflowPage, error)
+	InitializeWorkflow(tx *sql.Tx, workflowID string, processID string, processVersion int, businessKey string, taskStatus map[string]entitites.TaskStatusData, publishedStage map[string]bool) error
+	UpdateTaskStatus(tx *sql.Tx, workflowID string, task string, status string, updateDate time.Time) error
+	UpdatePublishedStage(tx *sql.Tx, workflowID string, stage string, isPublished bool) error
+	UpdateWorkflowStatus(tx *sql.Tx, workflowID string, status string, updateDate time.Time) error
+
+	InsertTaskInstances(tx *sql.Tx, workflowID string, processID string, taskStatus map[string]entitites.TaskStatusData) error
+	UpdateTaskWorker(workflowID string, task string, worker string) error
+	UpdateTaskLease(workflowID string, task string, jobID string, leaseExpireDate time.Time) (bool, error)
+	GetTaskInstancesByWorkflowID(tx *sql.Tx, workflowID string) ([]entitites.TaskInstance, error)
+	GetTaskInstancesByStatus(processID string, task string, status string, limit int) ([]entitites.TaskInstance, error)
+	GetStaleTaskInstances(status string, before time.Time, limit int) ([]entitites.TaskInstance, error)
+
+	InsertWorkflowEvents(tx *sql.Tx, events []entitites.WorkflowEvent) error
+	ListWorkflowEvents(query entitites.WorkflowEventQuery) ([]entitites.WorkflowEvent, error)
+	GetLatestWorkflowEventID() (int64, error)
+
+	SetRetentionPolicy(tx *sql.Tx, policy entitites.RetentionPolicy) (bool, error)
+	ListRetentionPolicies() ([]entitites.RetentionPolicy, error)
+	GetExpiredWorkflowIDs(processID string, status string, before time.Time, limit int) ([]string, error)
+	DeleteWorkflows(tx *sql.Tx, workflowIDs []string) (int, error)
+
+	SaveSubscriber(subscriber *entitites.SubscriberRegistry) (bool, error)
+	GetSubscriberBySessionKey(sessionKey string) (*entitites.SubscriberRegistry, error)
+	GetAllSubscribers() (*[]entitites.SubscriberRegistry, error)
+	RemoveSubscriber(sessionKey string) error
+	ClaimSubscriber(sessionKey string, instanceID string, now time.Time, leaseExpireDate time.Time) (bool, error)
+	ReleaseSubscribers(instanceID string, sessionKeys []string) error
+	RenewSubscriber(sessionKey string, renewDate time.Time) (bool, error)
+
+	RenewInstance(instance entitites.CoreInstance) error
+	RemoveInstance(instanceID string) error
+	ListInstances(now time.Time) ([]entitites.CoreInstance, error)
+	RemoveExpiredInstances(before time.Time) (int, error)
+	ClaimClusterLease(name string, instanceID string, now time.Time, leaseExpireDate time.Time) (bool, error)
+	ReleaseClusterLeases(instanceID string) error
+}
+
+var (
+	_ NoNoodleWorkflowRepository = (*PostgreSQLNoNoodleWorkflow)(nil)
+	_ NoNoodleWorkflowRepository = (*SQLiteNoNoodleWorkflow)(nil)
+)
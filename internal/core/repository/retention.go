@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// inPlaceholders renders "(p1, p2, ...)" for an IN clause starting at bind parameter start.
+func inPlaceholders(count int, start int, placeholder func(n int) string) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = placeholder(start + i)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+func stringsToArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func deleteWorkflows(tx *sql.Tx, workflowIDs []string, placeholder func(n int) string) (int, error) {
+	if len(workflowIDs) == 0 {
+		return 0, nil
+	}
+
+	// task_instance rows go with their workflow through ON DELETE CASCADE
+	result, err := tx.Exec("DELETE FROM workflow WHERE workflow_id IN "+inPlaceholders(len(workflowIDs), 1, placeholder), stringsToArgs(workflowIDs)...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func (s *SQLiteNoNoodleWorkflow) RenewInstance(instance entitites.CoreInstance) error {
+	return renewInstance(s.db, instance, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) RemoveInstance(instanceID string) error {
+	return removeInstance(s.db, instanceID, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ListInstances(now time.Time) ([]entitites.CoreInstance, error) {
+	return listInstances(s.db, now, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) RemoveExpiredInstances(before time.Time) (int, error) {
+	return removeExpiredInstances(s.db, before, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ClaimSubscriber(sessionKey string, instanceID string, now time.Time, leaseExpireDate time.Time) (bool, error) {
+	return claimSubscriber(s.db, sessionKey, instanceID, now, leaseExpireDate, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ReleaseSubscribers(instanceID string, sessionKeys []string) error {
+	return releaseSubscribers(s.db, instanceID, sessionKeys, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) RenewSubscriber(sessionKey string, renewDate time.Time) (bool, error) {
+	return renewSubscriber(s.db, sessionKey, renewDate, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ClaimClusterLease(name string, instanceID string, now time.Time, leaseExpireDate time.Time) (bool, error) {
+	return claimClusterLease(s.db, name, instanceID, now, leaseExpireDate, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ReleaseClusterLeases(instanceID string) error {
+	return releaseClusterLeases(s.db, instanceID, sqlitePlaceholder)
+}
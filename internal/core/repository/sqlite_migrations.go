@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// sqliteMigrations mirror sql/table.sql and sql/migrations for SQLite. JSONB
+// columns are stored as TEXT and updated with the json1 functions. The applied
+// version is tracked in PRAGMA user_version, append new steps at the end.
+var sqliteMigrations = []string{
+	// 1: baseline schema
+	`
+	CREATE TABLE IF NOT EXISTS process_config (
+		process_id TEXT PRIMARY KEY,
+		map_stage_task TEXT NOT NULL,
+		map_stage_ready TEXT NOT NULL,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS workflow (
+		workflow_id TEXT PRIMARY KEY,
+		process_id TEXT NOT NULL,
+		task_status TEXT NOT NULL,
+		published_stage TEXT NOT NULL,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (process_id) REFERENCES process_config (process_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS subscription (
+		session_key TEXT PRIMARY KEY,
+		process_id TEXT NOT NULL,
+		task TEXT NOT NULL,
+		health_check_url TEXT NOT NULL,
+		callback_url TEXT NOT NULL,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (process_id) REFERENCES process_config (process_id)
+	);
+	`,
+	// 2: sql/migrations/0001_task_instance.sql, databases created before
+	// user_version was tracked may already have task_instance, the task_status
+	// copy runs in sqliteMigrationSteps
+	`
+	CREATE TABLE IF NOT EXISTS task_instance (
+		workflow_id TEXT NOT NULL,
+		process_id TEXT NOT NULL,
+		task TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		worker TEXT,
+		start_date TIMESTAMP,
+		end_date TIMESTAMP,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (workflow_id, task),
+		FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_task_instance_process_task_status ON task_instance (process_id, task, status);
+	CREATE INDEX IF NOT EXISTS idx_task_instance_status_update_date ON task_instance (status, update_date);
+	`,
+	// 3: sql/migrations/0002_workflow_query.sql
+	`
+	ALTER TABLE workflow ADD COLUMN status TEXT NOT NULL DEFAULT 'running';
+	ALTER TABLE workflow ADD COLUMN business_key TEXT;
+	ALTER TABLE workflow ADD COLUMN update_date TIMESTAMP;
+
+	UPDATE workflow SET update_date = COALESCE(
+		(SELECT MAX(ti.update_date) FROM task_instance ti WHERE ti.workflow_id = workflow.workflow_id),
+		create_date
+	);
+	UPDATE workflow SET status = 'failed'
+	WHERE EXISTS (SELECT 1 FROM task_instance ti WHERE ti.workflow_id = workflow.workflow_id AND ti.status = 'failed');
+	UPDATE workflow SET status = 'completed'
+	WHERE status = 'running'
+	AND NOT EXISTS (SELECT 1 FROM task_instance ti WHERE ti.workflow_id = workflow.workflow_id AND ti.status <> 'completed');
+
+	CREATE INDEX idx_workflow_create_date ON workflow (create_date, workflow_id);
+	CREATE INDEX idx_workflow_update_date ON workflow (update_date, workflow_id);
+	CREATE INDEX idx_workflow_process_create_date ON workflow (process_id, create_date, workflow_id);
+	CREATE INDEX idx_workflow_status_create_date ON workflow (status, create_date, workflow_id);
+	CREATE INDEX idx_workflow_business_key ON workflow (business_key);
+	`,
+	// 4: sql/migrations/0003_process_config_version.sql, SQLite cannot alter
+	// constraints so the affected tables are rebuilt
+	`
+	CREATE TABLE process (
+		process_id TEXT PRIMARY KEY,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	INSERT INTO process (process_id, enabled, create_date, update_date)
+	SELECT process_id, 1, create_date, create_date FROM process_config;
+
+	CREATE TABLE process_config_new (
+		process_id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		map_stage_task TEXT NOT NULL,
+		map_stage_ready TEXT NOT NULL,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (process_id, version),
+		FOREIGN KEY (process_id) REFERENCES process (process_id)
+	);
+	INSERT INTO process_config_new (process_id, version, map_stage_task, map_stage_ready, create_date)
+	SELECT process_id, 1, map_stage_task, map_stage_ready, create_date FROM process_config;
+	DROP TABLE process_config;
+	ALTER TABLE process_config_new RENAME TO process_config;
+
+	CREATE TABLE workflow_new (
+		workflow_id TEXT PRIMARY KEY,
+		process_id TEXT NOT NULL,
+		process_version INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'running',
+		business_key TEXT,
+		published_stage TEXT NOT NULL,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (process_id, process_version) REFERENCES process_config (process_id, version)
+	);
+	INSERT INTO workflow_new (workflow_id, process_id, process_version, status, business_key, published_stage, create_date, update_date)
+	SELECT workflow_id, process_id, 1, status, business_key, published_stage, create_date, update_date FROM workflow;
+	DROP TABLE workflow;
+	ALTER TABLE workflow_new RENAME TO workflow;
+
+	CREATE INDEX idx_workflow_create_date ON workflow (create_date, workflow_id);
+	CREATE INDEX idx_workflow_update_date ON workflow (update_date, workflow_id);
+	CREATE INDEX idx_workflow_process_create_date ON workflow (process_id, create_date, workflow_id);
+	CREATE INDEX idx_workflow_status_create_date ON workflow (status, create_date, workflow_id);
+	CREATE INDEX idx_workflow_business_key ON workflow (business_key);
+	CREATE INDEX idx_workflow_process_version ON workflow (process_id, process_version);
+
+	CREATE TABLE subscription_new (
+		session_key TEXT PRIMARY KEY,
+		process_id TEXT NOT NULL,
+		task TEXT NOT NULL,
+		health_check_url TEXT NOT NULL,
+		callback_url TEXT NOT NULL,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (process_id) REFERENCES process (process_id)
+	);
+	INSERT INTO subscription_new SELECT session_key, process_id, task, health_check_url, callback_url, create_date FROM subscription;
+	DROP TABLE subscription;
+	ALTER TABLE subscription_new RENAME TO subscription;
+	`,
+	// 5: sql/migrations/0004_retention.sql
+	`
+	ALTER TABLE process ADD COLUMN completed_retention_days INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE process ADD COLUMN failed_retention_days INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE process ADD COLUMN archive_before_delete INTEGER NOT NULL DEFAULT 1;
+
+	CREATE INDEX idx_workflow_process_status_update_date ON workflow (process_id, status, update_date);
+	`,
+	// 6: sql/migrations/0008_task_lease.sql
+	`
+	ALTER TABLE process_config ADD COLUMN map_task_lease TEXT NOT NULL DEFAULT '{}';
+
+	ALTER TABLE task_instance ADD COLUMN job_id TEXT;
+	ALTER TABLE task_instance ADD COLUMN lease_expire_date TIMESTAMP;
+	`,
+	// 7: sql/migrations/0009_workflow_event.sql
+	`
+	CREATE TABLE workflow_event (
+		event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		workflow_id TEXT NOT NULL,
+		process_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		stage TEXT,
+		task TEXT,
+		create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX idx_workflow_event_workflow_event_id ON workflow_event (workflow_id, event_id);
+	CREATE INDEX idx_workflow_event_process_event_id ON workflow_event (process_id, event_id);
+	`,
+	// 8: sql/migrations/0010_subscription_signing_secret.sql
+	`
+	ALTER TABLE subscription ADD COLUMN signing_secret TEXT;
+	`,
+	// 9: sql/migrations/0011_subscription_health_check.sql
+	`
+	ALTER TABLE subscription ADD COLUMN health_check_interval_sec INTEGER NOT NULL DEFAULT 5;
+	ALTER TABLE subscription ADD COLUMN health_check_timeout_sec INTEGER NOT NULL DEFAULT 5;
+	ALTER TABLE subscription ADD COLUMN health_check_failure_threshold INTEGER NOT NULL DEFAULT 10;
+	ALTER TABLE subscription ADD COLUMN health_check_method TEXT NOT NULL DEFAULT 'GET';
+	ALTER TABLE subscription ADD COLUMN health_check_status_codes TEXT NOT NULL DEFAULT '[200]';
+	`,
+	// 10: sql/migrations/0012_cluster.sql
+	`
+	CREATE TABLE core_instance (
+		instance_id TEXT PRIMARY KEY,
+		start_date TIMESTAMP NOT NULL,
+		lease_expire_date TIMESTAMP NOT NULL
+	);
+	CREATE TABLE cluster_lease (
+		name TEXT PRIMARY KEY,
+		owner_instance_id TEXT NOT NULL,
+		lease_expire_date TIMESTAMP NOT NULL
+	);
+	ALTER TABLE subscription ADD COLUMN owner_instance_id TEXT;
+	ALTER TABLE subscription ADD COLUMN owner_lease_expire_date TIMESTAMP;
+	ALTER TABLE subscription ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE subscription ADD COLUMN renew_date TIMESTAMP;
+	`,
+	// 11: sql/migrations/0013_subscription_unique_callback.sql
+	`
+	DELETE FROM subscription
+	WHERE rowid NOT IN (
+		SELECT MIN(rowid) FROM subscription GROUP BY process_id, task, callback_url
+	);
+	CREATE UNIQUE INDEX idx_subscription_process_task_callback ON subscription (process_id, task, callback_url);
+	`,
+	// 12: sql/migrations/0014_process_last_version.sql
+	`
+	ALTER TABLE process ADD COLUMN last_version INTEGER NOT NULL DEFAULT 0;
+	UPDATE process
+	SET last_version = COALESCE((SELECT MAX(version) FROM process_config pc WHERE pc.process_id = process.process_id), 0);
+	`,
+}
+
+// sqliteMigrationSteps run after the SQL of the migration with the same
+// version, for changes that depend on what the database already holds.
+var sqliteMigrationSteps = map[int]func(tx *sql.Tx) error{
+	2: migrateSQLiteTaskStatus,
+}
+
+// migrateSQLiteTaskStatus copies workflow.task_status into task_instance rows
+// and drops the column. Databases that already dropped it are left alone.
+func migrateSQLiteTaskStatus(tx *sql.Tx) error {
+	var legacyColumns int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info('workflow') WHERE name = 'task_status'").Scan(&legacyColumns)
+	if err != nil {
+		return err
+	}
+	if legacyColumns == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO task_instance (workflow_id, process_id, task, status, attempts, start_date, end_date, create_date, update_date)
+		SELECT
+			w.workflow_id,
+			w.process_id,
+			t.key,
+			json_extract(t.value, '$.status'),
+			CASE WHEN json_extract(t.value, '$.status') = 'waiting' THEN 0 ELSE 1 END,
+			CASE WHEN json_extract(t.value, '$.status') = 'waiting' THEN NULL ELSE json_extract(t.value, '$.update_date') END,
+			CASE WHEN json_extract(t.value, '$.status') IN ('completed', 'failed') THEN json_extract(t.value, '$.update_date') ELSE NULL END,
+			w.create_date,
+			json_extract(t.value, '$.update_date')
+		FROM workflow w, json_each(w.task_status) t
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("ALTER TABLE workflow DROP COLUMN task_status")
+	return err
+}
+
+// migrateSQLite applies the pending migrations on one pinned connection with
+// foreign keys switched off, as SQLite requires for rebuilding tables. Each
+// step is verified with foreign_key_check before it commits.
+func migrateSQLite(db *sql.DB) error {
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var version int
+	err = conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+	if version >= len(sqliteMigrations) {
+		return nil
+	}
+
+	_, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	if err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	for ; version < len(sqliteMigrations); version++ {
+		err = applySQLiteMigration(ctx, conn, version+1, sqliteMigrations[version])
+		if err != nil {
+			return fmt.Errorf("sqlite migration %d: %v", version+1, err)
+		}
+	}
+	return nil
+}
+
+func applySQLiteMigration(ctx context.Context, conn *sql.Conn, version int, migration string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.Exec(migration)
+	if err != nil {
+		return err
+	}
+	if step, ok := sqliteMigrationSteps[version]; ok {
+		err = step(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	violations := rows.Next()
+	rows.Close()
+	if violations {
+		err = fmt.Errorf("foreign key violations after migration")
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version))
+	return err
+}
@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/repository"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// legacyProcessConfig is the process_config and subscription schema both
+// legacy releases created, before user_version was tracked.
+const legacyProcessConfig = `
+CREATE TABLE process_config (
+    process_id TEXT PRIMARY KEY,
+    map_stage_task TEXT NOT NULL,
+    map_stage_ready TEXT NOT NULL,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE subscription (
+    session_key TEXT PRIMARY KEY,
+    process_id TEXT NOT NULL,
+    task TEXT NOT NULL,
+    health_check_url TEXT NOT NULL,
+    callback_url TEXT NOT NULL,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (process_id) REFERENCES process_config (process_id)
+);
+
+INSERT INTO process_config (process_id, map_stage_task, map_stage_ready)
+VALUES ('mp', '{"start":["a","b"]}', '{}');
+`
+
+// taskStatusSchema is the schema of user-026, task state lived in the
+// workflow.task_status JSON column.
+const taskStatusSchema = legacyProcessConfig + `
+CREATE TABLE workflow (
+    workflow_id TEXT PRIMARY KEY,
+    process_id TEXT NOT NULL,
+    task_status TEXT NOT NULL,
+    published_stage TEXT NOT NULL,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (process_id) REFERENCES process_config (process_id)
+);
+
+INSERT INTO workflow (workflow_id, process_id, task_status, published_stage)
+VALUES (
+    'wf',
+    'mp',
+    '{"a":{"status":"completed","update_date":"2026-01-01T00:00:00Z"},"b":{"status":"completed","update_date":"2026-01-02T00:00:00Z"}}',
+    '{"start":true}'
+);
+`
+
+// taskInstanceSchema is the schema of user-027, task_instance was created
+// with IF NOT EXISTS and task_status was already dropped.
+const taskInstanceSchema = legacyProcessConfig + `
+CREATE TABLE workflow (
+    workflow_id TEXT PRIMARY KEY,
+    process_id TEXT NOT NULL,
+    published_stage TEXT NOT NULL,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (process_id) REFERENCES process_config (process_id)
+);
+
+CREATE TABLE task_instance (
+    workflow_id TEXT NOT NULL,
+    process_id TEXT NOT NULL,
+    task TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    worker TEXT,
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, task),
+    FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_instance_process_task_status ON task_instance (process_id, task, status);
+CREATE INDEX idx_task_instance_status_update_date ON task_instance (status, update_date);
+
+INSERT INTO workflow (workflow_id, process_id, published_stage) VALUES ('wf', 'mp', '{"start":true}');
+INSERT INTO task_instance (workflow_id, process_id, task, status, attempts, update_date) VALUES
+    ('wf', 'mp', 'a', 'completed', 1, '2026-01-01T00:00:00Z'),
+    ('wf', 'mp', 'b', 'completed', 1, '2026-01-02T00:00:00Z');
+`
+
+func TestSQLiteMigratesLegacySchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "task_status", schema: taskStatusSchema},
+		{name: "task_instance", schema: taskInstanceSchema},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := util.NewSQLite(filepath.Join(t.TempDir(), "legacy.db"), 5000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if _, err := db.Exec(tt.schema); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := repository.NewSQLiteNoNoodleWorkflow(db); err != nil {
+				t.Fatal(err)
+			}
+
+			assertMigratedWorkflow(t, db)
+
+			// Opening a migrated database again is a no-op
+			if _, err := repository.NewSQLiteNoNoodleWorkflow(db); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func assertMigratedWorkflow(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	var legacyColumns int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('workflow') WHERE name = 'task_status'").Scan(&legacyColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacyColumns != 0 {
+		t.Fatal("workflow.task_status still exists")
+	}
+
+	rows, err := db.Query("SELECT task, status FROM task_instance WHERE workflow_id = 'wf' ORDER BY task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var tasks []string
+	for rows.Next() {
+		var task, status string
+		if err := rows.Scan(&task, &status); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task+":"+status)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0] != "a:completed" || tasks[1] != "b:completed" {
+		t.Fatalf("got task instances %v, want [a:completed b:completed]", tasks)
+	}
+
+	var status string
+	var processVersion int
+	err = db.QueryRow("SELECT status, process_version FROM workflow WHERE workflow_id = 'wf'").Scan(&status, &processVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "completed" || processVersion != 1 {
+		t.Fatalf("got workflow %s at version %d, want completed at version 1", status, processVersion)
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+// InsertProcessConfig deploys config as the next version of its process and
+// writes the assigned version back into config.Version.
+func (s *SQLiteNoNoodleWorkflow) InsertProcessConfig(tx *sql.Tx, config *entitites.ProcessConfig) error {
+
+	mapStageTaskJSON, err := json.Marshal(config.MapStageTask)
+	if err != nil {
+		return err
+	}
+	mapStageReadyJSON, err := json.Marshal(config.MapStageReady)
+	if err != nil {
+		return err
+	}
+	mapTaskLeaseJSON, err := marshalTaskLease(config.MapTaskLeaseSeconds)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO process (process_id) VALUES (?) ON CONFLICT (process_id) DO NOTHING", config.ProcessID)
+	if err != nil {
+		return err
+	}
+
+	// SQLite transactions are serialized, the counter alone keeps versions
+	// distinct and a deleted version is never handed out again
+	var enabled bool
+	var version int
+	err = tx.QueryRow("UPDATE process SET last_version = last_version + 1 WHERE process_id = ? RETURNING enabled, last_version", config.ProcessID).Scan(&enabled, &version)
+	if err != nil {
+		return err
+	}
+
+	createDate := util.GetCurrentTime()
+	_, err = tx.Exec("INSERT INTO process_config (process_id, version, map_stage_task, map_stage_ready, map_task_lease, create_date) VALUES (?, ?, ?, ?, ?, ?)", config.ProcessID, version, string(mapStageTaskJSON), string(mapStageReadyJSON), string(mapTaskLeaseJSON), createDate)
+	if err != nil {
+		return err
+	}
+
+	config.Version = version
+	config.Enabled = enabled
+	config.CreateDate = createDate
+	return nil
+}
+
+// GetProcessConfigByProcessID returns the latest deployed version of a process.
+func (s *SQLiteNoNoodleWorkflow) GetProcessConfigByProcessID(tx *sql.Tx, ProcessID string) (entitites.ProcessConfig, error) {
+	return scanProcessConfig(tx.QueryRow("SELECT "+processConfigColumns+" FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.process_id = ? ORDER BY pc.version DESC LIMIT 1", ProcessID))
+}
+
+func (s *SQLiteNoNoodleWorkflow) GetProcessConfigByVersion(tx *sql.Tx, ProcessID string, version int) (entitites.ProcessConfig, error) {
+	return scanProcessConfig(tx.QueryRow("SELECT "+processConfigColumns+" FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.process_id = ? AND pc.version = ?", ProcessID, version))
+}
+
+// ListProcessConfigs returns the latest version of every process.
+func (s *SQLiteNoNoodleWorkflow) ListProcessConfigs(tx *sql.Tx) ([]entitites.ProcessConfig, error) {
+	rows, err := tx.Query("SELECT " + processConfigColumns + " FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.version = (SELECT MAX(version) FROM process_config WHERE process_id = pc.process_id) ORDER BY pc.process_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanProcessConfigs(rows)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ListProcessConfigVersions(tx *sql.Tx, ProcessID string) ([]entitites.ProcessConfig, error) {
+	rows, err := tx.Query("SELECT "+processConfigColumns+" FROM process_config pc JOIN process p ON p.process_id = pc.process_id WHERE pc.process_id = ? ORDER BY pc.version", ProcessID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanProcessConfigs(rows)
+}
+
+// SetProcessEnabled enables or disables every version of a process, it returns
+// false when the process does not exist.
+func (s *SQLiteNoNoodleWorkflow) SetProcessEnabled(tx *sql.Tx, ProcessID string, enabled bool) (bool, error) {
+	result, err := tx.Exec("UPDATE process SET enabled = ?, update_date = ? WHERE process_id = ?", enabled, util.GetCurrentTime(), ProcessID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *SQLiteNoNoodleWorkflow) CountWorkflowsByProcessVersion(tx *sql.Tx, ProcessID string, version int) (int, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM workflow WHERE process_id = ? AND process_version = ?", ProcessID, version).Scan(&count)
+	return count, err
+}
+
+// DeleteProcessConfig removes one version, it returns false when the version does not exist.
+func (s *SQLiteNoNoodleWorkflow) DeleteProcessConfig(tx *sql.Tx, ProcessID string, version int) (bool, error) {
+	result, err := tx.Exec("DELETE FROM process_config WHERE process_id = ? AND version = ?", ProcessID, version)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *SQLiteNoNoodleWorkflow) GetMapStageTaskByProcessID(tx *sql.Tx, ProcessID string) (map[string][]string, error) {
+	var mapStageTaskJSON []byte
+	err := tx.QueryRow("SELECT map_stage_task FROM process_config WHERE process_id = ? ORDER BY version DESC LIMIT 1", ProcessID).Scan(&mapStageTaskJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapStageTask map[string][]string
+	err = json.Unmarshal(mapStageTaskJSON, &mapStageTask)
+	if err != nil {
+		return nil, err
+	}
+	return mapStageTask, nil
+}
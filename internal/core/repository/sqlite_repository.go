@@ -0,0 +1,20 @@
+package repository
+
+import "database/sql"
+
+type SQLiteNoNoodleWorkflow struct {
+	db *sql.DB
+}
+
+// NewSQLiteNoNoodleWorkflow creates the SQLite backed repository and brings the
+// schema up to date, so a single-node deployment needs nothing but a file path.
+func NewSQLiteNoNoodleWorkflow(db *sql.DB) (*SQLiteNoNoodleWorkflow, error) {
+	if err := migrateSQLite(db); err != nil {
+		return nil, err
+	}
+	return &SQLiteNoNoodleWorkflow{db: db}, nil
+}
+
+func (s *SQLiteNoNoodleWorkflow) GetDB() *sql.DB {
+	return s.db
+}
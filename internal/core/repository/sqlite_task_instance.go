@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+func (s *SQLiteNoNoodleWorkflow) InsertTaskInstances(tx *sql.Tx, workflowID string, processID string, taskStatus map[string]entitites.TaskStatusData) error {
+	stmt, err := tx.Prepare("INSERT INTO task_instance (workflow_id, process_id, task, status, attempts, create_date, update_date) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for task, data := range taskStatus {
+		_, err = stmt.Exec(workflowID, processID, task, data.Status, data.Attempts, data.UpdateDate, data.UpdateDate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteNoNoodleWorkflow) UpdateTaskStatus(tx *sql.Tx, workflowID string, task string, status string, updateDate time.Time) error {
+	attemptsInc, startDate, endDate := taskStatusTransition(status, updateDate)
+
+	query := `
+		UPDATE task_instance
+		SET status = ?,
+			update_date = ?,
+			attempts = attempts + ?,
+			start_date = COALESCE(?, start_date),
+			end_date = ?,
+			job_id = CASE WHEN ? IS NULL THEN job_id END,
+			lease_expire_date = CASE WHEN ? IS NULL THEN lease_expire_date END
+		WHERE workflow_id = ? AND task = ?
+	`
+
+	result, err := tx.Exec(query, status, updateDate, attemptsInc, startDate, endDate, endDate, endDate, workflowID, task)
+	if err != nil {
+		return err
+	}
+	err = checkTaskInstanceUpdated(result, workflowID, task)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE workflow SET update_date = ? WHERE workflow_id = ?", updateDate, workflowID)
+	return err
+}
+
+func (s *SQLiteNoNoodleWorkflow) UpdateTaskWorker(workflowID string, task string, worker string) error {
+	_, err := s.db.Exec("UPDATE task_instance SET worker = ? WHERE workflow_id = ? AND task = ?", worker, workflowID, task)
+	return err
+}
+
+// UpdateTaskLease records the job delivered for a task and when its lease
+// expires, it returns false when the task is already finished.
+func (s *SQLiteNoNoodleWorkflow) UpdateTaskLease(workflowID string, task string, jobID string, leaseExpireDate time.Time) (bool, error) {
+	result, err := s.db.Exec("UPDATE task_instance SET job_id = ?, lease_expire_date = ? WHERE workflow_id = ? AND task = ? AND end_date IS NULL", jobID, leaseExpireDate, workflowID, task)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *SQLiteNoNoodleWorkflow) GetTaskInstancesByWorkflowID(tx *sql.Tx, workflowID string) ([]entitites.TaskInstance, error) {
+	rows, err := tx.Query("SELECT "+taskInstanceColumns+" FROM task_instance WHERE workflow_id = ?", workflowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskInstances(rows)
+}
+
+// GetTaskInstancesByStatus lists instances of one task type in a given status,
+// served by idx_task_instance_process_task_status.
+func (s *SQLiteNoNoodleWorkflow) GetTaskInstancesByStatus(processID string, task string, status string, limit int) ([]entitites.TaskInstance, error) {
+	rows, err := s.db.Query("SELECT "+taskInstanceColumns+" FROM task_instance WHERE process_id = ? AND task = ? AND status = ? ORDER BY update_date LIMIT ?", processID, task, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskInstances(rows)
+}
+
+// GetStaleTaskInstances lists instances that have been in a status since before
+// the given time, served by idx_task_instance_status_update_date.
+func (s *SQLiteNoNoodleWorkflow) GetStaleTaskInstances(status string, before time.Time, limit int) ([]entitites.TaskInstance, error) {
+	rows, err := s.db.Query("SELECT "+taskInstanceColumns+" FROM task_instance WHERE status = ? AND update_date < ? ORDER BY update_date LIMIT ?", status, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskInstances(rows)
+}
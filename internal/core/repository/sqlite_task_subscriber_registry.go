@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// SaveSubscriber stores subscriber. A subscriber of the same process, task
+// and callback URL is taken over instead: subscriber.SessionKey is set to its
+// session, its row is updated and true is returned.
+func (r *SQLiteNoNoodleWorkflow) SaveSubscriber(subscriber *entitites.SubscriberRegistry) (bool, error) {
+	sessionKey, err := saveSubscriber(r.db, subscriber, sqlitePlaceholder)
+	if err != nil {
+		return false, err
+	}
+
+	takenOver := sessionKey != subscriber.SessionKey
+	subscriber.SessionKey = sessionKey
+	return takenOver, nil
+}
+
+func (r *SQLiteNoNoodleWorkflow) GetSubscriberBySessionKey(sessionKey string) (*entitites.SubscriberRegistry, error) {
+	query := "SELECT " + subscriberColumns + " FROM subscription WHERE session_key = ?"
+	subscriber, err := scanSubscriber(r.db.QueryRow(query, sessionKey))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// not found
+			return nil, nil
+		}
+		// real DB error
+		return nil, err
+	}
+
+	return subscriber, nil
+}
+
+func (r *SQLiteNoNoodleWorkflow) GetAllSubscribers() (*[]entitites.SubscriberRegistry, error) {
+	var subscription []entitites.SubscriberRegistry
+	query := "SELECT " + subscriberColumns + " FROM subscription"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		subscriber, err := scanSubscriber(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscription = append(subscription, *subscriber)
+	}
+
+	return &subscription, nil
+}
+
+func (r *SQLiteNoNoodleWorkflow) RemoveSubscriber(sessionKey string) error {
+	query := "DELETE FROM subscription WHERE session_key = ?"
+	_, err := r.db.Exec(query, sessionKey)
+	return err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+func (s *SQLiteNoNoodleWorkflow) GetWorkflowByWorkflowID(tx *sql.Tx, workflowID string) (*entitites.Workflow, error) {
+
+	workflow, err := scanWorkflow(tx.QueryRow("SELECT "+workflowColumns+" FROM workflow WHERE workflow_id = ?", workflowID))
+	if err != nil {
+		return nil, err
+	}
+
+	taskInstances, err := s.GetTaskInstancesByWorkflowID(tx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	workflow.TaskStatus = taskInstancesToTaskStatus(taskInstances)
+
+	return workflow, nil
+}
+
+func (s *SQLiteNoNoodleWorkflow) SearchWorkflows(query entitites.WorkflowQuery) (*entitites.WorkflowPage, error) {
+	return searchWorkflows(s.db, query, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) InitializeWorkflow(tx *sql.Tx, workflowID string, processID string, processVersion int, businessKey string, taskStatus map[string]entitites.TaskStatusData, publishedStage map[string]bool) error {
+
+	publishedStageBytes, err := json.Marshal(publishedStage)
+	if err != nil {
+		return err
+	}
+
+	var businessKeyValue sql.NullString
+	if businessKey != "" {
+		businessKeyValue = sql.NullString{String: businessKey, Valid: true}
+	}
+
+	now := util.GetCurrentTime()
+	_, err = tx.Exec("INSERT INTO workflow (workflow_id, process_id, process_version, status, business_key, published_stage, create_date, update_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", workflowID, processID, processVersion, entitites.WORKFLOW_STATUS_RUNNING, businessKeyValue, string(publishedStageBytes), now, now)
+	if err != nil {
+		return err
+	}
+
+	return s.InsertTaskInstances(tx, workflowID, processID, taskStatus)
+}
+
+func (s *SQLiteNoNoodleWorkflow) UpdateWorkflowStatus(tx *sql.Tx, workflowID string, status string, updateDate time.Time) error {
+	_, err := tx.Exec("UPDATE workflow SET status = ?, update_date = ? WHERE workflow_id = ?", status, updateDate, workflowID)
+	return err
+}
+
+func (s *SQLiteNoNoodleWorkflow) UpdatePublishedStage(tx *sql.Tx, workflowID string, stage string, isPublished bool) error {
+	query := `
+		UPDATE workflow
+		SET published_stage = json_set(
+			published_stage,
+			?,
+			json(?)
+		)
+		WHERE workflow_id = ?
+	`
+	published := "false"
+	if isPublished {
+		published = "true"
+	}
+	_, err := tx.Exec(query, sqliteJSONPath(stage), published, workflowID)
+	return err
+}
+
+// sqliteJSONPath builds a json1 path for a top level key. Keys are quoted so
+// task and stage names containing dots or brackets are addressed literally.
+func sqliteJSONPath(key string) string {
+	return `$."` + key + `"`
+}
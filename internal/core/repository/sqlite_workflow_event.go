@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// InsertWorkflowEvents needs no extra locking, SQLite serializes writers.
+func (s *SQLiteNoNoodleWorkflow) InsertWorkflowEvents(tx *sql.Tx, events []entitites.WorkflowEvent) error {
+	return insertWorkflowEvents(tx, events, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) ListWorkflowEvents(query entitites.WorkflowEventQuery) ([]entitites.WorkflowEvent, error) {
+	return listWorkflowEvents(s.db, query, sqlitePlaceholder)
+}
+
+func (s *SQLiteNoNoodleWorkflow) GetLatestWorkflowEventID() (int64, error) {
+	return getLatestWorkflowEventID(s.db)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/util"
+)
+
+const subscriberColumns = "session_key, process_id, task, health_check_url, callback_url, signing_secret, health_check_interval_sec, health_check_timeout_sec, health_check_failure_threshold, health_check_method, health_check_status_codes, owner_instance_id, owner_lease_expire_date, revision, renew_date, create_date"
+
+// saveSubscriber inserts subscriber, or takes over the subscription of the
+// same process, task and callback URL in the same statement: everything but
+// its identity and owner is replaced and its revision bumped. The session of
+// the stored row is returned, a session other than subscriber.SessionKey
+// means the subscription was taken over.
+func saveSubscriber(db *sql.DB, subscriber *entitites.SubscriberRegistry, placeholder func(n int) string) (string, error) {
+
+	statusCodesJSON, err := json.Marshal(subscriber.HealthCheck.StatusCodes)
+	if err != nil {
+		return "", err
+	}
+
+	query := "INSERT INTO subscription (" + subscriberColumns + ") VALUES " + inPlaceholders(16, 1, placeholder) +
+		" ON CONFLICT (process_id, task, callback_url) DO UPDATE SET health_check_url = excluded.health_check_url" +
+		", signing_secret = excluded.signing_secret" +
+		", health_check_interval_sec = excluded.health_check_interval_sec" +
+		", health_check_timeout_sec = excluded.health_check_timeout_sec" +
+		", health_check_failure_threshold = excluded.health_check_failure_threshold" +
+		", health_check_method = excluded.health_check_method" +
+		", health_check_status_codes = excluded.health_check_status_codes" +
+		", revision = subscription.revision + 1" +
+		" RETURNING session_key"
+	var sessionKey string
+	err = db.QueryRow(query,
+		subscriber.SessionKey,
+		subscriber.ProcessID,
+		subscriber.Task,
+		subscriber.HealthCheckURL,
+		subscriber.CallbackURL,
+		nullString(subscriber.SigningSecret),
+		subscriber.HealthCheck.IntervalSeconds,
+		subscriber.HealthCheck.TimeoutSeconds,
+		subscriber.HealthCheck.FailureThreshold,
+		subscriber.HealthCheck.Method,
+		string(statusCodesJSON),
+		nullString(subscriber.OwnerInstanceID),
+		subscriber.OwnerLeaseExpireDate,
+		subscriber.Revision,
+		subscriber.RenewDate,
+		util.GetCurrentTime(),
+	).Scan(&sessionKey)
+	return sessionKey, err
+}
+
+func scanSubscriber(row rowScanner) (*entitites.SubscriberRegistry, error) {
+	var subscriber entitites.SubscriberRegistry
+	var signingSecret, ownerInstanceID sql.NullString
+	var ownerLeaseExpireDate, renewDate sql.NullTime
+	var statusCodesJSON []byte
+
+	err := row.Scan(
+		&subscriber.SessionKey,
+		&subscriber.ProcessID,
+		&subscriber.Task,
+		&subscriber.HealthCheckURL,
+		&subscriber.CallbackURL,
+		&signingSecret,
+		&subscriber.HealthCheck.IntervalSeconds,
+		&subscriber.HealthCheck.TimeoutSeconds,
+		&subscriber.HealthCheck.FailureThreshold,
+		&subscriber.HealthCheck.Method,
+		&statusCodesJSON,
+		&ownerInstanceID,
+		&ownerLeaseExpireDate,
+		&subscriber.Revision,
+		&renewDate,
+		&subscriber.CreateDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	subscriber.SigningSecret = signingSecret.String
+	subscriber.OwnerInstanceID = ownerInstanceID.String
+	if ownerLeaseExpireDate.Valid {
+		subscriber.OwnerLeaseExpireDate = &ownerLeaseExpireDate.Time
+	}
+	if renewDate.Valid {
+		subscriber.RenewDate = &renewDate.Time
+	}
+
+	if err := json.Unmarshal(statusCodesJSON, &subscriber.HealthCheck.StatusCodes); err != nil {
+		return nil, err
+	}
+
+	return &subscriber, nil
+}
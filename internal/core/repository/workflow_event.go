@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+// workflowEventLock is the advisory lock key serializing event inserts on
+// PostgreSQL, so event IDs become visible in the order they were assigned and
+// a watcher resuming after an ID never skips a late commit.
+const workflowEventLock = 6160
+
+func insertWorkflowEvents(tx *sql.Tx, events []entitites.WorkflowEvent, placeholder func(n int) string) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(events))
+	args := make([]any, 0, len(events)*6)
+	for _, event := range events {
+		n := len(args)
+		values = append(values, "("+placeholder(n+1)+", "+placeholder(n+2)+", "+placeholder(n+3)+", "+placeholder(n+4)+", "+placeholder(n+5)+", "+placeholder(n+6)+")")
+		args = append(args, event.WorkflowID, event.ProcessID, event.Type, nullString(event.Stage), nullString(event.Task), event.CreateDate)
+	}
+
+	_, err := tx.Exec("INSERT INTO workflow_event (workflow_id, process_id, type, stage, task, create_date) VALUES "+strings.Join(values, ", "), args...)
+	return err
+}
+
+// listWorkflowEvents returns the events after query.AfterEventID in event ID
+// order, served by the (workflow_id, event_id) and (process_id, event_id) indexes.
+func listWorkflowEvents(db *sql.DB, query entitites.WorkflowEventQuery, placeholder func(n int) string) ([]entitites.WorkflowEvent, error) {
+	args := []any{query.AfterEventID}
+	where := []string{"event_id > " + placeholder(1)}
+	if query.WorkflowID != "" {
+		args = append(args, query.WorkflowID)
+		where = append(where, "workflow_id = "+placeholder(len(args)))
+	}
+	if query.ProcessID != "" {
+		args = append(args, query.ProcessID)
+		where = append(where, "process_id = "+placeholder(len(args)))
+	}
+	if !query.RecordedBefore.IsZero() {
+		args = append(args, query.RecordedBefore)
+		where = append(where, "create_date < "+placeholder(len(args)))
+	}
+
+	rows, err := db.Query("SELECT event_id, workflow_id, process_id, type, stage, task, create_date FROM workflow_event WHERE "+strings.Join(where, " AND ")+" ORDER BY event_id LIMIT "+strconv.Itoa(query.Limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []entitites.WorkflowEvent{}
+	for rows.Next() {
+		var event entitites.WorkflowEvent
+		var stage, task sql.NullString
+		if err := rows.Scan(&event.EventID, &event.WorkflowID, &event.ProcessID, &event.Type, &stage, &task, &event.CreateDate); err != nil {
+			return nil, err
+		}
+		event.Stage = stage.String
+		event.Task = task.String
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func getLatestWorkflowEventID(db *sql.DB) (int64, error) {
+	var eventID int64
+	err := db.QueryRow("SELECT COALESCE(MAX(event_id), 0) FROM workflow_event").Scan(&eventID)
+	return eventID, err
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keerapon-som/no_noodle_workflow/internal/core/entitites"
+)
+
+const (
+	DEFAULT_WORKFLOW_PAGE_SIZE = 50
+	MAX_WORKFLOW_PAGE_SIZE     = 500
+)
+
+// ErrInvalidWorkflowQuery wraps every validation failure of a workflow search.
+var ErrInvalidWorkflowQuery = errors.New("invalid workflow query")
+
+const workflowColumns = "workflow_id, process_id, process_version, status, business_key, published_stage, create_date, update_date"
+
+// workflowCursor is the keyset position of the last row of a page: the value of
+// the sort column plus workflow_id as a tie breaker.
+type workflowCursor struct {
+	SortValue  time.Time `json:"v"`
+	WorkflowID string    `json:"id"`
+}
+
+func encodeWorkflowCursor(cursor workflowCursor) string {
+	cursorJSON, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+func decodeWorkflowCursor(encoded string) (*workflowCursor, error) {
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor: %v", ErrInvalidWorkflowQuery, err)
+	}
+	var cursor workflowCursor
+	if err := json.Unmarshal(cursorJSON, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor: %v", ErrInvalidWorkflowQuery, err)
+	}
+	return &cursor, nil
+}
+
+// searchWorkflows runs a keyset paginated workflow search. placeholder renders the
+// n-th bind parameter in the dialect of the calling backend.
+func searchWorkflows(db *sql.DB, query entitites.WorkflowQuery, placeholder func(n int) string) (*entitites.WorkflowPage, error) {
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = entitites.WORKFLOW_SORT_BY_CREATE_DATE
+	}
+	if sortBy != entitites.WORKFLOW_SORT_BY_CREATE_DATE && sortBy != entitites.WORKFLOW_SORT_BY_UPDATE_DATE {
+		return nil, fmt.Errorf("%w: invalid sort_by: %s", ErrInvalidWorkflowQuery, sortBy)
+	}
+
+	sortOrder := strings.ToLower(query.SortOrder)
+	if sortOrder == "" {
+		sortOrder = entitites.SORT_ORDER_DESC
+	}
+	if sortOrder != entitites.SORT_ORDER_ASC && sortOrder != entitites.SORT_ORDER_DESC {
+		return nil, fmt.Errorf("%w: invalid sort order: %s", ErrInvalidWorkflowQuery, query.SortOrder)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DEFAULT_WORKFLOW_PAGE_SIZE
+	}
+	if limit > MAX_WORKFLOW_PAGE_SIZE {
+		limit = MAX_WORKFLOW_PAGE_SIZE
+	}
+
+	conditions := []string{}
+	args := []any{}
+	bind := func(value any) string {
+		args = append(args, value)
+		return placeholder(len(args))
+	}
+
+	if query.ProcessID != "" {
+		conditions = append(conditions, "w.process_id = "+bind(query.ProcessID))
+	}
+	if query.Status != "" {
+		conditions = append(conditions, "w.status = "+bind(query.Status))
+	}
+	if query.BusinessKey != "" {
+		conditions = append(conditions, "w.business_key = "+bind(query.BusinessKey))
+	}
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, "w.create_date >= "+bind(*query.CreatedFrom))
+	}
+	if query.CreatedTo != nil {
+		conditions = append(conditions, "w.create_date < "+bind(*query.CreatedTo))
+	}
+	if query.TaskStatus != "" {
+		taskCondition := "ti.status = " + bind(query.TaskStatus)
+		if query.Task != "" {
+			taskCondition += " AND ti.task = " + bind(query.Task)
+		}
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM task_instance ti WHERE ti.workflow_id = w.workflow_id AND "+taskCondition+")")
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeWorkflowCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		comparator := ">"
+		if sortOrder == entitites.SORT_ORDER_DESC {
+			comparator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"(w.%s %s %s OR (w.%s = %s AND w.workflow_id %s %s))",
+			sortBy, comparator, bind(cursor.SortValue), sortBy, bind(cursor.SortValue), comparator, bind(cursor.WorkflowID),
+		))
+	}
+
+	sqlQuery := "SELECT " + prefixColumns("w.", workflowColumns) + " FROM workflow w"
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// Fetch one extra row to know whether another page exists
+	sqlQuery += fmt.Sprintf(" ORDER BY w.%s %s, w.workflow_id %s LIMIT %s", sortBy, sortOrder, sortOrder, bind(limit+1))
+
+	rows, err := db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workflows, err := scanWorkflows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entitites.WorkflowPage{Workflows: workflows}
+	if len(workflows) > limit {
+		page.Workflows = workflows[:limit]
+		last := page.Workflows[limit-1]
+		sortValue := last.CreateDate
+		if sortBy == entitites.WORKFLOW_SORT_BY_UPDATE_DATE {
+			sortValue = last.UpdateDate
+		}
+		page.NextCursor = encodeWorkflowCursor(workflowCursor{SortValue: sortValue, WorkflowID: last.WorkflowID})
+	}
+
+	return page, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWorkflow(row rowScanner) (*entitites.Workflow, error) {
+	var workflow entitites.Workflow
+	var businessKey sql.NullString
+	var publishedStageJSON []byte
+
+	err := row.Scan(
+		&workflow.WorkflowID,
+		&workflow.ProcessID,
+		&workflow.ProcessVersion,
+		&workflow.Status,
+		&businessKey,
+		&publishedStageJSON,
+		&workflow.CreateDate,
+		&workflow.UpdateDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	workflow.BusinessKey = businessKey.String
+
+	err = json.Unmarshal(publishedStageJSON, &workflow.PublishedStage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workflow, nil
+}
+
+func scanWorkflows(rows *sql.Rows) ([]entitites.Workflow, error) {
+	workflows := []entitites.Workflow{}
+	for rows.Next() {
+		workflow, err := scanWorkflow(rows)
+		if err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, *workflow)
+	}
+	return workflows, rows.Err()
+}
+
+func prefixColumns(prefix string, columns string) string {
+	split := strings.Split(columns, ", ")
+	for i := range split {
+		split[i] = prefix + split[i]
+	}
+	return strings.Join(split, ", ")
+}
+
+func postgresPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func sqlitePlaceholder(n int) string {
+	return "?"
+}
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
+	grpcCatchup "github.com/keerapon-som/no_noodle_workflow/internal/core/grpc"
 	httpCatchup "github.com/keerapon-som/no_noodle_workflow/internal/core/http"
 
 	"github.com/keerapon-som/no_noodle_workflow/internal/core/api"
@@ -13,16 +16,37 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
+// grpcShutdownTimeout is how long open job streams get to finish on shutdown
+const grpcShutdownTimeout = 10 * time.Second
+
+// BackgroundJob is a long running task started alongside the HTTP server.
+// Run must return once ctx is cancelled.
+type BackgroundJob interface {
+	Run(ctx context.Context) error
+}
+
+// BackgroundJobFunc runs a function as a BackgroundJob.
+type BackgroundJobFunc func(ctx context.Context) error
+
+func (f BackgroundJobFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
 type Service struct {
-	fiberApp *fiber.App
+	fiberApp       *fiber.App
+	grpcServer     *grpc.Server
+	backgroundJobs []BackgroundJob
 }
 
-func New(noNoodleCore api.NoNoodleCoreInterface) *Service {
+func New(noNoodleCore api.NoNoodleCoreInterface, backgroundJobs ...BackgroundJob) *Service {
 
 	return &Service{
-		fiberApp: httpCatchup.NewHTTPRouter(noNoodleCore),
+		fiberApp:       httpCatchup.NewHTTPRouter(noNoodleCore),
+		grpcServer:     grpcCatchup.NewGRPCServer(noNoodleCore),
+		backgroundJobs: backgroundJobs,
 	}
 
 }
@@ -46,9 +70,43 @@ func (s *Service) Run(ctx context.Context) error {
 		return nil
 	})
 
+	if grpcPort := config.GetConfig().ServerConfig.GRPC.Port; grpcPort != "" {
+		errgroup.Go(func() error {
+
+			listener, err := net.Listen("tcp", ":"+grpcPort)
+			if err != nil {
+				return err
+			}
+
+			err = s.grpcServer.Serve(listener)
+			if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	for _, job := range s.backgroundJobs {
+		errgroup.Go(func() error {
+			return job.Run(ctx)
+		})
+	}
+
 	errgroup.Go(func() error {
 		<-ctx.Done()
 
+		stopped := make(chan struct{})
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(grpcShutdownTimeout):
+			s.grpcServer.Stop()
+		}
+
 		return s.fiberApp.Shutdown()
 	})
 
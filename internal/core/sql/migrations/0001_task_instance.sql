@@ -0,0 +1,38 @@
+-- Move task state out of workflow.task_status JSONB into task_instance rows.
+BEGIN;
+
+CREATE TABLE task_instance (
+    workflow_id VARCHAR(255) NOT NULL,
+    process_id VARCHAR(255) NOT NULL,
+    task VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    worker TEXT,
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, task),
+    FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_instance_process_task_status ON task_instance (process_id, task, status);
+CREATE INDEX idx_task_instance_status_update_date ON task_instance (status, update_date);
+
+-- Anything that left "waiting" has been published at least once
+INSERT INTO task_instance (workflow_id, process_id, task, status, attempts, start_date, end_date, create_date, update_date)
+SELECT
+    w.workflow_id,
+    w.process_id,
+    t.key,
+    t.value->>'status',
+    CASE WHEN t.value->>'status' = 'waiting' THEN 0 ELSE 1 END,
+    CASE WHEN t.value->>'status' = 'waiting' THEN NULL ELSE (t.value->>'update_date')::timestamptz::timestamp END,
+    CASE WHEN t.value->>'status' IN ('completed', 'failed') THEN (t.value->>'update_date')::timestamptz::timestamp ELSE NULL END,
+    w.create_date,
+    (t.value->>'update_date')::timestamptz::timestamp
+FROM workflow w, jsonb_each(w.task_status) t;
+
+ALTER TABLE workflow DROP COLUMN task_status;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Workflow status, business key and the indexes behind the workflow search API.
+BEGIN;
+
+ALTER TABLE workflow
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'running',
+    ADD COLUMN business_key VARCHAR(255),
+    ADD COLUMN update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE workflow w SET update_date = COALESCE(
+    (SELECT MAX(ti.update_date) FROM task_instance ti WHERE ti.workflow_id = w.workflow_id),
+    w.create_date
+);
+UPDATE workflow w SET status = 'failed'
+WHERE EXISTS (SELECT 1 FROM task_instance ti WHERE ti.workflow_id = w.workflow_id AND ti.status = 'failed');
+UPDATE workflow w SET status = 'completed'
+WHERE w.status = 'running'
+AND NOT EXISTS (SELECT 1 FROM task_instance ti WHERE ti.workflow_id = w.workflow_id AND ti.status <> 'completed');
+
+CREATE INDEX idx_workflow_create_date ON workflow (create_date, workflow_id);
+CREATE INDEX idx_workflow_update_date ON workflow (update_date, workflow_id);
+CREATE INDEX idx_workflow_process_create_date ON workflow (process_id, create_date, workflow_id);
+CREATE INDEX idx_workflow_status_create_date ON workflow (status, create_date, workflow_id);
+CREATE INDEX idx_workflow_business_key ON workflow (business_key);
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- Versioned process configs with a process level enabled switch.
+BEGIN;
+
+CREATE TABLE process (
+    process_id VARCHAR(255) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO process (process_id, enabled, create_date, update_date)
+SELECT process_id, TRUE, create_date, create_date FROM process_config;
+
+ALTER TABLE workflow DROP CONSTRAINT workflow_process_id_fkey;
+ALTER TABLE subscription DROP CONSTRAINT subscription_process_id_fkey;
+
+-- Every existing config becomes version 1
+ALTER TABLE process_config ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE process_config ALTER COLUMN version DROP DEFAULT;
+ALTER TABLE process_config DROP CONSTRAINT process_config_pkey;
+ALTER TABLE process_config ADD PRIMARY KEY (process_id, version);
+ALTER TABLE process_config ADD FOREIGN KEY (process_id) REFERENCES process (process_id);
+
+ALTER TABLE workflow ADD COLUMN process_version INT NOT NULL DEFAULT 1;
+ALTER TABLE workflow ALTER COLUMN process_version DROP DEFAULT;
+ALTER TABLE workflow ADD FOREIGN KEY (process_id, process_version) REFERENCES process_config (process_id, version);
+CREATE INDEX idx_workflow_process_version ON workflow (process_id, process_version);
+
+ALTER TABLE subscription ADD FOREIGN KEY (process_id) REFERENCES process (process_id);
+
+COMMIT;
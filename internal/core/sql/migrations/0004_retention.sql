@@ -0,0 +1,11 @@
+-- Per process retention of finished workflows.
+BEGIN;
+
+ALTER TABLE process
+    ADD COLUMN completed_retention_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN failed_retention_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN archive_before_delete BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX idx_workflow_process_status_update_date ON workflow (process_id, status, update_date);
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Job queue table backing the postgresql message broker.
+BEGIN;
+
+CREATE TABLE job_queue (
+    id BIGSERIAL PRIMARY KEY,
+    queue VARCHAR(512) NOT NULL,
+    payload BYTEA NOT NULL,
+    reserved BOOLEAN NOT NULL DEFAULT FALSE,
+    visible_after TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_job_queue_queue_visible_after ON job_queue (queue, visible_after, id);
+
+COMMIT;
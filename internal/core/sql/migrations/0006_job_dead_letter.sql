@@ -0,0 +1,17 @@
+-- Delivery attempts and dead letters of the postgresql message broker.
+BEGIN;
+
+ALTER TABLE job_queue ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+
+CREATE TABLE job_dead_letter (
+    id BIGSERIAL PRIMARY KEY,
+    queue VARCHAR(512) NOT NULL,
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL,
+    last_error TEXT NOT NULL,
+    dead_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_job_dead_letter_queue ON job_dead_letter (queue, id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Dead letters keep the id and enqueue time of their job.
+BEGIN;
+
+ALTER TABLE job_dead_letter ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS job_dead_letter_id_seq;
+ALTER TABLE job_dead_letter ADD COLUMN enqueue_date TIMESTAMP;
+
+COMMIT;
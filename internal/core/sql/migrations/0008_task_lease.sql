@@ -0,0 +1,11 @@
+-- Per task leases of delivered jobs, extended by worker heartbeats.
+BEGIN;
+
+ALTER TABLE process_config
+    ADD COLUMN map_task_lease JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE task_instance
+    ADD COLUMN job_id VARCHAR(255),
+    ADD COLUMN lease_expire_date TIMESTAMP;
+
+COMMIT;
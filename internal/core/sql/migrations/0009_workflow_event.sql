@@ -0,0 +1,18 @@
+-- Event history of workflows, streamed to watchers over SSE.
+BEGIN;
+
+CREATE TABLE workflow_event (
+    event_id BIGSERIAL PRIMARY KEY,
+    workflow_id VARCHAR(255) NOT NULL,
+    process_id VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    stage VARCHAR(255),
+    task VARCHAR(255),
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_workflow_event_workflow_event_id ON workflow_event (workflow_id, event_id);
+CREATE INDEX idx_workflow_event_process_event_id ON workflow_event (process_id, event_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Per subscription secret signing the callback deliveries.
+BEGIN;
+
+ALTER TABLE subscription
+    ADD COLUMN signing_secret VARCHAR(64);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Per subscription health check settings, the defaults are the former fixed ones.
+BEGIN;
+
+ALTER TABLE subscription
+    ADD COLUMN health_check_interval_sec INT NOT NULL DEFAULT 5,
+    ADD COLUMN health_check_timeout_sec INT NOT NULL DEFAULT 5,
+    ADD COLUMN health_check_failure_threshold INT NOT NULL DEFAULT 10,
+    ADD COLUMN health_check_method VARCHAR(16) NOT NULL DEFAULT 'GET',
+    ADD COLUMN health_check_status_codes JSONB NOT NULL DEFAULT '[200]';
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- Core instances sharing the database, and which of them owns each subscription.
+BEGIN;
+
+CREATE TABLE core_instance (
+    instance_id VARCHAR(255) PRIMARY KEY,
+    start_date TIMESTAMP NOT NULL,
+    lease_expire_date TIMESTAMP NOT NULL
+);
+
+-- Cluster wide duties held by one instance at a time
+CREATE TABLE cluster_lease (
+    name VARCHAR(255) PRIMARY KEY,
+    owner_instance_id VARCHAR(255) NOT NULL,
+    lease_expire_date TIMESTAMP NOT NULL
+);
+
+ALTER TABLE subscription
+    ADD COLUMN owner_instance_id VARCHAR(255),
+    ADD COLUMN owner_lease_expire_date TIMESTAMP,
+    ADD COLUMN revision INT NOT NULL DEFAULT 0,
+    ADD COLUMN renew_date TIMESTAMP;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- One subscription per process, task and callback URL, so concurrent re-subscribes upsert the same row.
+BEGIN;
+
+-- Keep the oldest of any duplicates left by earlier versions
+DELETE FROM subscription s
+USING subscription older
+WHERE s.process_id = older.process_id
+  AND s.task = older.task
+  AND s.callback_url = older.callback_url
+  AND (older.create_date < s.create_date
+       OR (older.create_date = s.create_date AND older.session_key < s.session_key));
+
+CREATE UNIQUE INDEX idx_subscription_process_task_callback ON subscription (process_id, task, callback_url);
+
+COMMIT;
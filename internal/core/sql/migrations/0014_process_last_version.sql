@@ -0,0 +1,9 @@
+-- Per-process version counter, so a deleted highest version is never handed out again.
+BEGIN;
+
+ALTER TABLE process ADD COLUMN last_version INT NOT NULL DEFAULT 0;
+
+UPDATE process p
+SET last_version = COALESCE((SELECT MAX(version) FROM process_config pc WHERE pc.process_id = p.process_id), 0);
+
+COMMIT;
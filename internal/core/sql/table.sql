@@ -1,10 +1,30 @@
--- Table 1: process_config
--- Stores process configuration with stage-to-task mappings
-CREATE TABLE process_config (
+-- Table 1: process
+-- One row per process id, enabling or disabling all of its versions
+CREATE TABLE process (
     process_id VARCHAR(255) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- highest version ever deployed, versions are never reused after a delete
+    last_version INT NOT NULL DEFAULT 0,
+    -- retention of finished workflows in days, 0 keeps them forever
+    completed_retention_days INT NOT NULL DEFAULT 0,
+    failed_retention_days INT NOT NULL DEFAULT 0,
+    archive_before_delete BOOLEAN NOT NULL DEFAULT TRUE,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Table 1.1: process_config
+-- Stores every deployed version of a process with its stage-to-task mappings
+CREATE TABLE process_config (
+    process_id VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
     map_stage_task JSONB NOT NULL,
     map_stage_ready JSONB NOT NULL,
-    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    -- task -> lease in seconds, tasks not listed use TASK_LEASE_DEFAULT_SEC
+    map_task_lease JSONB NOT NULL DEFAULT '{}',
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (process_id, version),
+    FOREIGN KEY (process_id) REFERENCES process (process_id)
 );
 
 -- Table 2: workflow
@@ -12,18 +32,131 @@ CREATE TABLE process_config (
 CREATE TABLE workflow (
     workflow_id VARCHAR(255) PRIMARY KEY,
     process_id VARCHAR(255) NOT NULL,
-    task_status JSONB NOT NULL,
+    process_version INT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'running',
+    business_key VARCHAR(255),
     published_stage JSONB NOT NULL,
     create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (process_id) REFERENCES process_config (process_id)
+    update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (process_id, process_version) REFERENCES process_config (process_id, version)
 );
 
+-- workflow search, every index ends with workflow_id for keyset pagination
+CREATE INDEX idx_workflow_create_date ON workflow (create_date, workflow_id);
+CREATE INDEX idx_workflow_update_date ON workflow (update_date, workflow_id);
+CREATE INDEX idx_workflow_process_create_date ON workflow (process_id, create_date, workflow_id);
+CREATE INDEX idx_workflow_status_create_date ON workflow (status, create_date, workflow_id);
+CREATE INDEX idx_workflow_business_key ON workflow (business_key);
+-- retention sweep
+CREATE INDEX idx_workflow_process_status_update_date ON workflow (process_id, status, update_date);
+-- process config delete guard
+CREATE INDEX idx_workflow_process_version ON workflow (process_id, process_version);
+
+-- Table 3: task_instance
+-- One row per workflow/task, replaces the former workflow.task_status JSONB
+CREATE TABLE task_instance (
+    workflow_id VARCHAR(255) NOT NULL,
+    process_id VARCHAR(255) NOT NULL,
+    task VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    worker TEXT,
+    -- broker message of the latest delivery and when its lease runs out
+    job_id VARCHAR(255),
+    lease_expire_date TIMESTAMP,
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    update_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, task),
+    FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+);
+
+-- "all active tasks of type X"
+CREATE INDEX idx_task_instance_process_task_status ON task_instance (process_id, task, status);
+-- sweeper: tasks stuck in a status since before a given time
+CREATE INDEX idx_task_instance_status_update_date ON task_instance (status, update_date);
+
 CREATE TABLE subscription (
     session_key VARCHAR(255) PRIMARY KEY,
     process_id VARCHAR(255) NOT NULL,
     task VARCHAR(255) NOT NULL,
     health_check_url TEXT NOT NULL,
     callback_url TEXT NOT NULL,
+    -- HMAC key of the callback deliveries, issued at subscribe time
+    signing_secret VARCHAR(64),
+    health_check_interval_sec INT NOT NULL DEFAULT 5,
+    health_check_timeout_sec INT NOT NULL DEFAULT 5,
+    health_check_failure_threshold INT NOT NULL DEFAULT 10,
+    health_check_method VARCHAR(16) NOT NULL DEFAULT 'GET',
+    -- JSON array of the status codes accepted as healthy
+    health_check_status_codes JSONB NOT NULL DEFAULT '[200]',
+    -- core instance consuming the subscription until its lease runs out
+    owner_instance_id VARCHAR(255),
+    owner_lease_expire_date TIMESTAMP,
+    -- bumped whenever a re-subscribe takes the subscription over
+    revision INT NOT NULL DEFAULT 0,
+    -- last time the worker vouched for its own health
+    renew_date TIMESTAMP,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (process_id) REFERENCES process (process_id)
+);
+
+CREATE UNIQUE INDEX idx_subscription_process_task_callback ON subscription (process_id, task, callback_url);
+
+-- Core instances sharing the database, alive until their lease runs out
+CREATE TABLE core_instance (
+    instance_id VARCHAR(255) PRIMARY KEY,
+    start_date TIMESTAMP NOT NULL,
+    lease_expire_date TIMESTAMP NOT NULL
+);
+
+-- Cluster wide duties held by one instance at a time
+CREATE TABLE cluster_lease (
+    name VARCHAR(255) PRIMARY KEY,
+    owner_instance_id VARCHAR(255) NOT NULL,
+    lease_expire_date TIMESTAMP NOT NULL
+);
+-- Event history of workflows, event_id orders events across all workflows
+CREATE TABLE workflow_event (
+    event_id BIGSERIAL PRIMARY KEY,
+    workflow_id VARCHAR(255) NOT NULL,
+    process_id VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    stage VARCHAR(255),
+    task VARCHAR(255),
     create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (process_id) REFERENCES process_config (process_id)
-);
\ No newline at end of file
+    FOREIGN KEY (workflow_id) REFERENCES workflow (workflow_id) ON DELETE CASCADE
+);
+
+-- watchers of a single workflow or process
+CREATE INDEX idx_workflow_event_workflow_event_id ON workflow_event (workflow_id, event_id);
+CREATE INDEX idx_workflow_event_process_event_id ON workflow_event (process_id, event_id);
+
+-- Jobs of the postgresql message broker (MESSAGE_BROKER=postgresql)
+CREATE TABLE job_queue (
+    id BIGSERIAL PRIMARY KEY,
+    queue VARCHAR(512) NOT NULL,
+    payload BYTEA NOT NULL,
+    reserved BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INT NOT NULL DEFAULT 0,
+    visible_after TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    create_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- reserve: oldest visible job of a queue
+CREATE INDEX idx_job_queue_queue_visible_after ON job_queue (queue, visible_after, id);
+
+-- Jobs of the postgresql message broker moved out after too many failed
+-- deliveries, id is the job_queue id of the job
+CREATE TABLE job_dead_letter (
+    id BIGINT PRIMARY KEY,
+    queue VARCHAR(512) NOT NULL,
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL,
+    enqueue_date TIMESTAMP,
+    last_error TEXT NOT NULL,
+    dead_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_job_dead_letter_queue ON job_dead_letter (queue, id);
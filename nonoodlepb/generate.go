@@ -0,0 +1,5 @@
+// Package nonoodlepb is the gRPC contract of the core, generated from
+// no_noodle_workflow.proto.
+package nonoodlepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative no_noodle_workflow.proto
@@ -0,0 +1,2346 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: no_noodle_workflow.proto
+
+package nonoodlepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TaskList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []string               `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskList) Reset() {
+	*x = TaskList{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskList) ProtoMessage() {}
+
+func (x *TaskList) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskList.ProtoReflect.Descriptor instead.
+func (*TaskList) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TaskList) GetTasks() []string {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type ProcessConfig struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ProcessId           string                 `protobuf:"bytes,1,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	Version             int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Enabled             bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	MapStageTask        map[string]*TaskList   `protobuf:"bytes,4,rep,name=map_stage_task,json=mapStageTask,proto3" json:"map_stage_task,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	MapStageReady       map[string]*TaskList   `protobuf:"bytes,5,rep,name=map_stage_ready,json=mapStageReady,proto3" json:"map_stage_ready,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	MapTaskLeaseSeconds map[string]int32       `protobuf:"bytes,6,rep,name=map_task_lease_seconds,json=mapTaskLeaseSeconds,proto3" json:"map_task_lease_seconds,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	CreateDate          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=create_date,json=createDate,proto3" json:"create_date,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ProcessConfig) Reset() {
+	*x = ProcessConfig{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessConfig) ProtoMessage() {}
+
+func (x *ProcessConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessConfig.ProtoReflect.Descriptor instead.
+func (*ProcessConfig) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProcessConfig) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *ProcessConfig) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ProcessConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ProcessConfig) GetMapStageTask() map[string]*TaskList {
+	if x != nil {
+		return x.MapStageTask
+	}
+	return nil
+}
+
+func (x *ProcessConfig) GetMapStageReady() map[string]*TaskList {
+	if x != nil {
+		return x.MapStageReady
+	}
+	return nil
+}
+
+func (x *ProcessConfig) GetMapTaskLeaseSeconds() map[string]int32 {
+	if x != nil {
+		return x.MapTaskLeaseSeconds
+	}
+	return nil
+}
+
+func (x *ProcessConfig) GetCreateDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateDate
+	}
+	return nil
+}
+
+type TaskStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Status          string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts        int32                  `protobuf:"varint,2,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Worker          string                 `protobuf:"bytes,3,opt,name=worker,proto3" json:"worker,omitempty"`
+	JobId           string                 `protobuf:"bytes,4,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	LeaseExpireDate *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=lease_expire_date,json=leaseExpireDate,proto3" json:"lease_expire_date,omitempty"`
+	StartDate       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	UpdateDate      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=update_date,json=updateDate,proto3" json:"update_date,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TaskStatus) Reset() {
+	*x = TaskStatus{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatus) ProtoMessage() {}
+
+func (x *TaskStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatus.ProtoReflect.Descriptor instead.
+func (*TaskStatus) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TaskStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *TaskStatus) GetWorker() string {
+	if x != nil {
+		return x.Worker
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetLeaseExpireDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseExpireDate
+	}
+	return nil
+}
+
+func (x *TaskStatus) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *TaskStatus) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *TaskStatus) GetUpdateDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateDate
+	}
+	return nil
+}
+
+type Workflow struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId     string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	ProcessId      string                 `protobuf:"bytes,2,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	ProcessVersion int32                  `protobuf:"varint,3,opt,name=process_version,json=processVersion,proto3" json:"process_version,omitempty"`
+	Status         string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	BusinessKey    string                 `protobuf:"bytes,5,opt,name=business_key,json=businessKey,proto3" json:"business_key,omitempty"`
+	TaskStatus     map[string]*TaskStatus `protobuf:"bytes,6,rep,name=task_status,json=taskStatus,proto3" json:"task_status,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	PublishedStage map[string]bool        `protobuf:"bytes,7,rep,name=published_stage,json=publishedStage,proto3" json:"published_stage,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	CreateDate     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=create_date,json=createDate,proto3" json:"create_date,omitempty"`
+	UpdateDate     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=update_date,json=updateDate,proto3" json:"update_date,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Workflow) Reset() {
+	*x = Workflow{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Workflow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Workflow) ProtoMessage() {}
+
+func (x *Workflow) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Workflow.ProtoReflect.Descriptor instead.
+func (*Workflow) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Workflow) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *Workflow) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *Workflow) GetProcessVersion() int32 {
+	if x != nil {
+		return x.ProcessVersion
+	}
+	return 0
+}
+
+func (x *Workflow) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Workflow) GetBusinessKey() string {
+	if x != nil {
+		return x.BusinessKey
+	}
+	return ""
+}
+
+func (x *Workflow) GetTaskStatus() map[string]*TaskStatus {
+	if x != nil {
+		return x.TaskStatus
+	}
+	return nil
+}
+
+func (x *Workflow) GetPublishedStage() map[string]bool {
+	if x != nil {
+		return x.PublishedStage
+	}
+	return nil
+}
+
+func (x *Workflow) GetCreateDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateDate
+	}
+	return nil
+}
+
+func (x *Workflow) GetUpdateDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateDate
+	}
+	return nil
+}
+
+type Topic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProcessId     string                 `protobuf:"bytes,1,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	Task          string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topic) Reset() {
+	*x = Topic{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topic) ProtoMessage() {}
+
+func (x *Topic) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topic.ProtoReflect.Descriptor instead.
+func (*Topic) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Topic) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *Topic) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+type LockedJob struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	JobId           string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	ProcessId       string                 `protobuf:"bytes,2,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	TaskId          string                 `protobuf:"bytes,3,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	WorkflowId      string                 `protobuf:"bytes,4,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Attempts        int32                  `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LeaseExpireDate *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=lease_expire_date,json=leaseExpireDate,proto3" json:"lease_expire_date,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LockedJob) Reset() {
+	*x = LockedJob{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockedJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockedJob) ProtoMessage() {}
+
+func (x *LockedJob) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockedJob.ProtoReflect.Descriptor instead.
+func (*LockedJob) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LockedJob) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *LockedJob) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *LockedJob) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *LockedJob) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *LockedJob) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *LockedJob) GetLeaseExpireDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseExpireDate
+	}
+	return nil
+}
+
+type DeployProcessConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProcessConfig *ProcessConfig         `protobuf:"bytes,1,opt,name=process_config,json=processConfig,proto3" json:"process_config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeployProcessConfigRequest) Reset() {
+	*x = DeployProcessConfigRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeployProcessConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeployProcessConfigRequest) ProtoMessage() {}
+
+func (x *DeployProcessConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeployProcessConfigRequest.ProtoReflect.Descriptor instead.
+func (*DeployProcessConfigRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeployProcessConfigRequest) GetProcessConfig() *ProcessConfig {
+	if x != nil {
+		return x.ProcessConfig
+	}
+	return nil
+}
+
+type DeployProcessConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeployProcessConfigResponse) Reset() {
+	*x = DeployProcessConfigResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeployProcessConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeployProcessConfigResponse) ProtoMessage() {}
+
+func (x *DeployProcessConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeployProcessConfigResponse.ProtoReflect.Descriptor instead.
+func (*DeployProcessConfigResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{7}
+}
+
+type GetProcessConfigRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProcessId string                 `protobuf:"bytes,1,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	// version 0 is the latest version
+	Version       int32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProcessConfigRequest) Reset() {
+	*x = GetProcessConfigRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProcessConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProcessConfigRequest) ProtoMessage() {}
+
+func (x *GetProcessConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProcessConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetProcessConfigRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetProcessConfigRequest) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *GetProcessConfigRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type GetProcessConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProcessConfig *ProcessConfig         `protobuf:"bytes,1,opt,name=process_config,json=processConfig,proto3" json:"process_config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProcessConfigResponse) Reset() {
+	*x = GetProcessConfigResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProcessConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProcessConfigResponse) ProtoMessage() {}
+
+func (x *GetProcessConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProcessConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetProcessConfigResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetProcessConfigResponse) GetProcessConfig() *ProcessConfig {
+	if x != nil {
+		return x.ProcessConfig
+	}
+	return nil
+}
+
+type CreateWorkflowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProcessId     string                 `protobuf:"bytes,1,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	BusinessKey   string                 `protobuf:"bytes,2,opt,name=business_key,json=businessKey,proto3" json:"business_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWorkflowRequest) Reset() {
+	*x = CreateWorkflowRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWorkflowRequest) ProtoMessage() {}
+
+func (x *CreateWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*CreateWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateWorkflowRequest) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *CreateWorkflowRequest) GetBusinessKey() string {
+	if x != nil {
+		return x.BusinessKey
+	}
+	return ""
+}
+
+type CreateWorkflowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWorkflowResponse) Reset() {
+	*x = CreateWorkflowResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWorkflowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWorkflowResponse) ProtoMessage() {}
+
+func (x *CreateWorkflowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWorkflowResponse.ProtoReflect.Descriptor instead.
+func (*CreateWorkflowResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateWorkflowResponse) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+type GetWorkflowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWorkflowRequest) Reset() {
+	*x = GetWorkflowRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorkflowRequest) ProtoMessage() {}
+
+func (x *GetWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*GetWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetWorkflowRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+type GetWorkflowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Workflow      *Workflow              `protobuf:"bytes,1,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWorkflowResponse) Reset() {
+	*x = GetWorkflowResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorkflowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorkflowResponse) ProtoMessage() {}
+
+func (x *GetWorkflowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorkflowResponse.ProtoReflect.Descriptor instead.
+func (*GetWorkflowResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetWorkflowResponse) GetWorkflow() *Workflow {
+	if x != nil {
+		return x.Workflow
+	}
+	return nil
+}
+
+type SearchWorkflowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProcessId     string                 `protobuf:"bytes,1,opt,name=process_id,json=processId,proto3" json:"process_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Task          string                 `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	TaskStatus    string                 `protobuf:"bytes,4,opt,name=task_status,json=taskStatus,proto3" json:"task_status,omitempty"`
+	BusinessKey   string                 `protobuf:"bytes,5,opt,name=business_key,json=businessKey,proto3" json:"business_key,omitempty"`
+	CreatedFrom   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	SortBy        string                 `protobuf:"bytes,8,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder     string                 `protobuf:"bytes,9,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string                 `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchWorkflowsRequest) Reset() {
+	*x = SearchWorkflowsRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchWorkflowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchWorkflowsRequest) ProtoMessage() {}
+
+func (x *SearchWorkflowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchWorkflowsRequest.ProtoReflect.Descriptor instead.
+func (*SearchWorkflowsRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchWorkflowsRequest) GetProcessId() string {
+	if x != nil {
+		return x.ProcessId
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetTaskStatus() string {
+	if x != nil {
+		return x.TaskStatus
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetBusinessKey() string {
+	if x != nil {
+		return x.BusinessKey
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetCreatedFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return nil
+}
+
+func (x *SearchWorkflowsRequest) GetCreatedTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return nil
+}
+
+func (x *SearchWorkflowsRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+func (x *SearchWorkflowsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchWorkflowsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type SearchWorkflowsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Workflows []*Workflow            `protobuf:"bytes,1,rep,name=workflows,proto3" json:"workflows,omitempty"`
+	// next_cursor is empty on the last page
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchWorkflowsResponse) Reset() {
+	*x = SearchWorkflowsResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchWorkflowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchWorkflowsResponse) ProtoMessage() {}
+
+func (x *SearchWorkflowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchWorkflowsResponse.ProtoReflect.Descriptor instead.
+func (*SearchWorkflowsResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SearchWorkflowsResponse) GetWorkflows() []*Workflow {
+	if x != nil {
+		return x.Workflows
+	}
+	return nil
+}
+
+func (x *SearchWorkflowsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type CompleteTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Task          string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTaskRequest) Reset() {
+	*x = CompleteTaskRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTaskRequest) ProtoMessage() {}
+
+func (x *CompleteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTaskRequest.ProtoReflect.Descriptor instead.
+func (*CompleteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CompleteTaskRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *CompleteTaskRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+type CompleteTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTaskResponse) Reset() {
+	*x = CompleteTaskResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTaskResponse) ProtoMessage() {}
+
+func (x *CompleteTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTaskResponse.ProtoReflect.Descriptor instead.
+func (*CompleteTaskResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{17}
+}
+
+type FailTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Task          string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailTaskRequest) Reset() {
+	*x = FailTaskRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailTaskRequest) ProtoMessage() {}
+
+func (x *FailTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailTaskRequest.ProtoReflect.Descriptor instead.
+func (*FailTaskRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FailTaskRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *FailTaskRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+type FailTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailTaskResponse) Reset() {
+	*x = FailTaskResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailTaskResponse) ProtoMessage() {}
+
+func (x *FailTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailTaskResponse.ProtoReflect.Descriptor instead.
+func (*FailTaskResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{19}
+}
+
+type HeartbeatTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId    string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Task          string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatTaskRequest) Reset() {
+	*x = HeartbeatTaskRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatTaskRequest) ProtoMessage() {}
+
+func (x *HeartbeatTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatTaskRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatTaskRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *HeartbeatTaskRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *HeartbeatTaskRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+type HeartbeatTaskResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LeaseExpireDate *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=lease_expire_date,json=leaseExpireDate,proto3" json:"lease_expire_date,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HeartbeatTaskResponse) Reset() {
+	*x = HeartbeatTaskResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatTaskResponse) ProtoMessage() {}
+
+func (x *HeartbeatTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatTaskResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatTaskResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *HeartbeatTaskResponse) GetLeaseExpireDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseExpireDate
+	}
+	return nil
+}
+
+type CompleteJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteJobRequest) Reset() {
+	*x = CompleteJobRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteJobRequest) ProtoMessage() {}
+
+func (x *CompleteJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteJobRequest.ProtoReflect.Descriptor instead.
+func (*CompleteJobRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CompleteJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type CompleteJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteJobResponse) Reset() {
+	*x = CompleteJobResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteJobResponse) ProtoMessage() {}
+
+func (x *CompleteJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteJobResponse.ProtoReflect.Descriptor instead.
+func (*CompleteJobResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{23}
+}
+
+type FailJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailJobRequest) Reset() {
+	*x = FailJobRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailJobRequest) ProtoMessage() {}
+
+func (x *FailJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailJobRequest.ProtoReflect.Descriptor instead.
+func (*FailJobRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *FailJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type FailJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailJobResponse) Reset() {
+	*x = FailJobResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailJobResponse) ProtoMessage() {}
+
+func (x *FailJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailJobResponse.ProtoReflect.Descriptor instead.
+func (*FailJobResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{25}
+}
+
+type ExtendJobLockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// lock_duration_seconds 0 extends by the task lease
+	LockDurationSeconds int32 `protobuf:"varint,2,opt,name=lock_duration_seconds,json=lockDurationSeconds,proto3" json:"lock_duration_seconds,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ExtendJobLockRequest) Reset() {
+	*x = ExtendJobLockRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendJobLockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendJobLockRequest) ProtoMessage() {}
+
+func (x *ExtendJobLockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendJobLockRequest.ProtoReflect.Descriptor instead.
+func (*ExtendJobLockRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ExtendJobLockRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *ExtendJobLockRequest) GetLockDurationSeconds() int32 {
+	if x != nil {
+		return x.LockDurationSeconds
+	}
+	return 0
+}
+
+type ExtendJobLockResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LeaseExpireDate *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=lease_expire_date,json=leaseExpireDate,proto3" json:"lease_expire_date,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExtendJobLockResponse) Reset() {
+	*x = ExtendJobLockResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendJobLockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendJobLockResponse) ProtoMessage() {}
+
+func (x *ExtendJobLockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendJobLockResponse.ProtoReflect.Descriptor instead.
+func (*ExtendJobLockResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExtendJobLockResponse) GetLeaseExpireDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseExpireDate
+	}
+	return nil
+}
+
+type OpenJobStream struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	// max_jobs 0 takes one job at a time
+	MaxJobs int32 `protobuf:"varint,2,opt,name=max_jobs,json=maxJobs,proto3" json:"max_jobs,omitempty"`
+	// lock_duration_seconds 0 locks jobs for their task lease
+	LockDurationSeconds int32    `protobuf:"varint,3,opt,name=lock_duration_seconds,json=lockDurationSeconds,proto3" json:"lock_duration_seconds,omitempty"`
+	Topics              []*Topic `protobuf:"bytes,4,rep,name=topics,proto3" json:"topics,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *OpenJobStream) Reset() {
+	*x = OpenJobStream{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenJobStream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenJobStream) ProtoMessage() {}
+
+func (x *OpenJobStream) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenJobStream.ProtoReflect.Descriptor instead.
+func (*OpenJobStream) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *OpenJobStream) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *OpenJobStream) GetMaxJobs() int32 {
+	if x != nil {
+		return x.MaxJobs
+	}
+	return 0
+}
+
+func (x *OpenJobStream) GetLockDurationSeconds() int32 {
+	if x != nil {
+		return x.LockDurationSeconds
+	}
+	return 0
+}
+
+func (x *OpenJobStream) GetTopics() []*Topic {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+type Topics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topics        []*Topic               `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topics) Reset() {
+	*x = Topics{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topics) ProtoMessage() {}
+
+func (x *Topics) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topics.ProtoReflect.Descriptor instead.
+func (*Topics) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Topics) GetTopics() []*Topic {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+type StreamJobsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Types that are valid to be assigned to Request:
+	//
+	//	*StreamJobsRequest_Open
+	//	*StreamJobsRequest_Subscribe
+	//	*StreamJobsRequest_Unsubscribe
+	//	*StreamJobsRequest_Complete
+	//	*StreamJobsRequest_Fail
+	//	*StreamJobsRequest_ExtendLock
+	Request       isStreamJobsRequest_Request `protobuf_oneof:"request"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamJobsRequest) Reset() {
+	*x = StreamJobsRequest{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamJobsRequest) ProtoMessage() {}
+
+func (x *StreamJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamJobsRequest.ProtoReflect.Descriptor instead.
+func (*StreamJobsRequest) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *StreamJobsRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *StreamJobsRequest) GetRequest() isStreamJobsRequest_Request {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *StreamJobsRequest) GetOpen() *OpenJobStream {
+	if x != nil {
+		if x, ok := x.Request.(*StreamJobsRequest_Open); ok {
+			return x.Open
+		}
+	}
+	return nil
+}
+
+func (x *StreamJobsRequest) GetSubscribe() *Topics {
+	if x != nil {
+		if x, ok := x.Request.(*StreamJobsRequest_Subscribe); ok {
+			return x.Subscribe
+		}
+	}
+	return nil
+}
+
+func (x *StreamJobsRequest) GetUnsubscribe() *Topics {
+	if x != nil {
+		if x, ok := x.Request.(*StreamJobsRequest_Unsubscribe); ok {
+			return x.Unsubscribe
+		}
+	}
+	return nil
+}
+
+func (x *StreamJobsRequest) GetComplete() *CompleteJobRequest {
+	if x != nil {
+		if x, ok := x.Request.(*StreamJobsRequest_Complete); ok {
+			return x.Complete
+		}
+	}
+	return nil
+}
+
+func (x *StreamJobsRequest) GetFail() *FailJobRequest {
+	if x != nil {
+		if x, ok := x.Request.(*StreamJobsRequest_Fail); ok {
+			return x.Fail
+		}
+	}
+	return nil
+}
+
+func (x *StreamJobsRequest) GetExtendLock() *ExtendJobLockRequest {
+	if x != nil {
+		if x, ok := x.Request.(*StreamJobsRequest_ExtendLock); ok {
+			return x.ExtendLock
+		}
+	}
+	return nil
+}
+
+type isStreamJobsRequest_Request interface {
+	isStreamJobsRequest_Request()
+}
+
+type StreamJobsRequest_Open struct {
+	Open *OpenJobStream `protobuf:"bytes,2,opt,name=open,proto3,oneof"`
+}
+
+type StreamJobsRequest_Subscribe struct {
+	Subscribe *Topics `protobuf:"bytes,3,opt,name=subscribe,proto3,oneof"`
+}
+
+type StreamJobsRequest_Unsubscribe struct {
+	Unsubscribe *Topics `protobuf:"bytes,4,opt,name=unsubscribe,proto3,oneof"`
+}
+
+type StreamJobsRequest_Complete struct {
+	Complete *CompleteJobRequest `protobuf:"bytes,5,opt,name=complete,proto3,oneof"`
+}
+
+type StreamJobsRequest_Fail struct {
+	Fail *FailJobRequest `protobuf:"bytes,6,opt,name=fail,proto3,oneof"`
+}
+
+type StreamJobsRequest_ExtendLock struct {
+	ExtendLock *ExtendJobLockRequest `protobuf:"bytes,7,opt,name=extend_lock,json=extendLock,proto3,oneof"`
+}
+
+func (*StreamJobsRequest_Open) isStreamJobsRequest_Request() {}
+
+func (*StreamJobsRequest_Subscribe) isStreamJobsRequest_Request() {}
+
+func (*StreamJobsRequest_Unsubscribe) isStreamJobsRequest_Request() {}
+
+func (*StreamJobsRequest_Complete) isStreamJobsRequest_Request() {}
+
+func (*StreamJobsRequest_Fail) isStreamJobsRequest_Request() {}
+
+func (*StreamJobsRequest_ExtendLock) isStreamJobsRequest_Request() {}
+
+// StreamResult answers a StreamJobsRequest. code is a gRPC status code, OK
+// when the request succeeded.
+type StreamResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Code      int32                  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message   string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// topics the stream is subscribed to, set for open, subscribe and unsubscribe
+	Topics []*Topic `protobuf:"bytes,4,rep,name=topics,proto3" json:"topics,omitempty"`
+	// lease_expire_date is set for extend_lock
+	LeaseExpireDate *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=lease_expire_date,json=leaseExpireDate,proto3" json:"lease_expire_date,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StreamResult) Reset() {
+	*x = StreamResult{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamResult) ProtoMessage() {}
+
+func (x *StreamResult) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamResult.ProtoReflect.Descriptor instead.
+func (*StreamResult) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *StreamResult) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *StreamResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *StreamResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *StreamResult) GetTopics() []*Topic {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *StreamResult) GetLeaseExpireDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseExpireDate
+	}
+	return nil
+}
+
+type StreamJobsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Response:
+	//
+	//	*StreamJobsResponse_Job
+	//	*StreamJobsResponse_Result
+	Response      isStreamJobsResponse_Response `protobuf_oneof:"response"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamJobsResponse) Reset() {
+	*x = StreamJobsResponse{}
+	mi := &file_no_noodle_workflow_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamJobsResponse) ProtoMessage() {}
+
+func (x *StreamJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_no_noodle_workflow_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamJobsResponse.ProtoReflect.Descriptor instead.
+func (*StreamJobsResponse) Descriptor() ([]byte, []int) {
+	return file_no_noodle_workflow_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *StreamJobsResponse) GetResponse() isStreamJobsResponse_Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *StreamJobsResponse) GetJob() *LockedJob {
+	if x != nil {
+		if x, ok := x.Response.(*StreamJobsResponse_Job); ok {
+			return x.Job
+		}
+	}
+	return nil
+}
+
+func (x *StreamJobsResponse) GetResult() *StreamResult {
+	if x != nil {
+		if x, ok := x.Response.(*StreamJobsResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isStreamJobsResponse_Response interface {
+	isStreamJobsResponse_Response()
+}
+
+type StreamJobsResponse_Job struct {
+	Job *LockedJob `protobuf:"bytes,1,opt,name=job,proto3,oneof"`
+}
+
+type StreamJobsResponse_Result struct {
+	Result *StreamResult `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*StreamJobsResponse_Job) isStreamJobsResponse_Response() {}
+
+func (*StreamJobsResponse_Result) isStreamJobsResponse_Response() {}
+
+var File_no_noodle_workflow_proto protoreflect.FileDescriptor
+
+const file_no_noodle_workflow_proto_rawDesc = "" +
+	"\n" +
+	"\x18no_noodle_workflow.proto\x12\x15no_noodle_workflow.v1\x1a\x1fgoogle/protobuf/timestamp.proto\" \n" +
+	"\bTaskList\x12\x14\n" +
+	"\x05tasks\x18\x01 \x03(\tR\x05tasks\"\xdf\x05\n" +
+	"\rProcessConfig\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x01 \x01(\tR\tprocessId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\x12\\\n" +
+	"\x0emap_stage_task\x18\x04 \x03(\v26.no_noodle_workflow.v1.ProcessConfig.MapStageTaskEntryR\fmapStageTask\x12_\n" +
+	"\x0fmap_stage_ready\x18\x05 \x03(\v27.no_noodle_workflow.v1.ProcessConfig.MapStageReadyEntryR\rmapStageReady\x12r\n" +
+	"\x16map_task_lease_seconds\x18\x06 \x03(\v2=.no_noodle_workflow.v1.ProcessConfig.MapTaskLeaseSecondsEntryR\x13mapTaskLeaseSeconds\x12;\n" +
+	"\vcreate_date\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createDate\x1a`\n" +
+	"\x11MapStageTaskEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.no_noodle_workflow.v1.TaskListR\x05value:\x028\x01\x1aa\n" +
+	"\x12MapStageReadyEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.no_noodle_workflow.v1.TaskListR\x05value:\x028\x01\x1aF\n" +
+	"\x18MapTaskLeaseSecondsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xe6\x02\n" +
+	"\n" +
+	"TaskStatus\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1a\n" +
+	"\battempts\x18\x02 \x01(\x05R\battempts\x12\x16\n" +
+	"\x06worker\x18\x03 \x01(\tR\x06worker\x12\x15\n" +
+	"\x06job_id\x18\x04 \x01(\tR\x05jobId\x12F\n" +
+	"\x11lease_expire_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0fleaseExpireDate\x129\n" +
+	"\n" +
+	"start_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\x12;\n" +
+	"\vupdate_date\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateDate\"\xfd\x04\n" +
+	"\bWorkflow\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x02 \x01(\tR\tprocessId\x12'\n" +
+	"\x0fprocess_version\x18\x03 \x01(\x05R\x0eprocessVersion\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12!\n" +
+	"\fbusiness_key\x18\x05 \x01(\tR\vbusinessKey\x12P\n" +
+	"\vtask_status\x18\x06 \x03(\v2/.no_noodle_workflow.v1.Workflow.TaskStatusEntryR\n" +
+	"taskStatus\x12\\\n" +
+	"\x0fpublished_stage\x18\a \x03(\v23.no_noodle_workflow.v1.Workflow.PublishedStageEntryR\x0epublishedStage\x12;\n" +
+	"\vcreate_date\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createDate\x12;\n" +
+	"\vupdate_date\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateDate\x1a`\n" +
+	"\x0fTaskStatusEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x127\n" +
+	"\x05value\x18\x02 \x01(\v2!.no_noodle_workflow.v1.TaskStatusR\x05value:\x028\x01\x1aA\n" +
+	"\x13PublishedStageEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\":\n" +
+	"\x05Topic\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x01 \x01(\tR\tprocessId\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\"\xdf\x01\n" +
+	"\tLockedJob\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x02 \x01(\tR\tprocessId\x12\x17\n" +
+	"\atask_id\x18\x03 \x01(\tR\x06taskId\x12\x1f\n" +
+	"\vworkflow_id\x18\x04 \x01(\tR\n" +
+	"workflowId\x12\x1a\n" +
+	"\battempts\x18\x05 \x01(\x05R\battempts\x12F\n" +
+	"\x11lease_expire_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0fleaseExpireDate\"i\n" +
+	"\x1aDeployProcessConfigRequest\x12K\n" +
+	"\x0eprocess_config\x18\x01 \x01(\v2$.no_noodle_workflow.v1.ProcessConfigR\rprocessConfig\"\x1d\n" +
+	"\x1bDeployProcessConfigResponse\"R\n" +
+	"\x17GetProcessConfigRequest\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x01 \x01(\tR\tprocessId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"g\n" +
+	"\x18GetProcessConfigResponse\x12K\n" +
+	"\x0eprocess_config\x18\x01 \x01(\v2$.no_noodle_workflow.v1.ProcessConfigR\rprocessConfig\"Y\n" +
+	"\x15CreateWorkflowRequest\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x01 \x01(\tR\tprocessId\x12!\n" +
+	"\fbusiness_key\x18\x02 \x01(\tR\vbusinessKey\"9\n" +
+	"\x16CreateWorkflowResponse\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\"5\n" +
+	"\x12GetWorkflowRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\"R\n" +
+	"\x13GetWorkflowResponse\x12;\n" +
+	"\bworkflow\x18\x01 \x01(\v2\x1f.no_noodle_workflow.v1.WorkflowR\bworkflow\"\x87\x03\n" +
+	"\x16SearchWorkflowsRequest\x12\x1d\n" +
+	"\n" +
+	"process_id\x18\x01 \x01(\tR\tprocessId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04task\x18\x03 \x01(\tR\x04task\x12\x1f\n" +
+	"\vtask_status\x18\x04 \x01(\tR\n" +
+	"taskStatus\x12!\n" +
+	"\fbusiness_key\x18\x05 \x01(\tR\vbusinessKey\x12=\n" +
+	"\fcreated_from\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedFrom\x129\n" +
+	"\n" +
+	"created_to\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedTo\x12\x17\n" +
+	"\asort_by\x18\b \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\t \x01(\tR\tsortOrder\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\v \x01(\tR\x06cursor\"y\n" +
+	"\x17SearchWorkflowsResponse\x12=\n" +
+	"\tworkflows\x18\x01 \x03(\v2\x1f.no_noodle_workflow.v1.WorkflowR\tworkflows\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"J\n" +
+	"\x13CompleteTaskRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\"\x16\n" +
+	"\x14CompleteTaskResponse\"F\n" +
+	"\x0fFailTaskRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\"\x12\n" +
+	"\x10FailTaskResponse\"K\n" +
+	"\x14HeartbeatTaskRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\"_\n" +
+	"\x15HeartbeatTaskResponse\x12F\n" +
+	"\x11lease_expire_date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x0fleaseExpireDate\"+\n" +
+	"\x12CompleteJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x15\n" +
+	"\x13CompleteJobResponse\"'\n" +
+	"\x0eFailJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x11\n" +
+	"\x0fFailJobResponse\"a\n" +
+	"\x14ExtendJobLockRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x122\n" +
+	"\x15lock_duration_seconds\x18\x02 \x01(\x05R\x13lockDurationSeconds\"_\n" +
+	"\x15ExtendJobLockResponse\x12F\n" +
+	"\x11lease_expire_date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x0fleaseExpireDate\"\xb1\x01\n" +
+	"\rOpenJobStream\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x19\n" +
+	"\bmax_jobs\x18\x02 \x01(\x05R\amaxJobs\x122\n" +
+	"\x15lock_duration_seconds\x18\x03 \x01(\x05R\x13lockDurationSeconds\x124\n" +
+	"\x06topics\x18\x04 \x03(\v2\x1c.no_noodle_workflow.v1.TopicR\x06topics\">\n" +
+	"\x06Topics\x124\n" +
+	"\x06topics\x18\x01 \x03(\v2\x1c.no_noodle_workflow.v1.TopicR\x06topics\"\xd1\x03\n" +
+	"\x11StreamJobsRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12:\n" +
+	"\x04open\x18\x02 \x01(\v2$.no_noodle_workflow.v1.OpenJobStreamH\x00R\x04open\x12=\n" +
+	"\tsubscribe\x18\x03 \x01(\v2\x1d.no_noodle_workflow.v1.TopicsH\x00R\tsubscribe\x12A\n" +
+	"\vunsubscribe\x18\x04 \x01(\v2\x1d.no_noodle_workflow.v1.TopicsH\x00R\vunsubscribe\x12G\n" +
+	"\bcomplete\x18\x05 \x01(\v2).no_noodle_workflow.v1.CompleteJobRequestH\x00R\bcomplete\x12;\n" +
+	"\x04fail\x18\x06 \x01(\v2%.no_noodle_workflow.v1.FailJobRequestH\x00R\x04fail\x12N\n" +
+	"\vextend_lock\x18\a \x01(\v2+.no_noodle_workflow.v1.ExtendJobLockRequestH\x00R\n" +
+	"extendLockB\t\n" +
+	"\arequest\"\xd9\x01\n" +
+	"\fStreamResult\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x124\n" +
+	"\x06topics\x18\x04 \x03(\v2\x1c.no_noodle_workflow.v1.TopicR\x06topics\x12F\n" +
+	"\x11lease_expire_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0fleaseExpireDate\"\x95\x01\n" +
+	"\x12StreamJobsResponse\x124\n" +
+	"\x03job\x18\x01 \x01(\v2 .no_noodle_workflow.v1.LockedJobH\x00R\x03job\x12=\n" +
+	"\x06result\x18\x02 \x01(\v2#.no_noodle_workflow.v1.StreamResultH\x00R\x06resultB\n" +
+	"\n" +
+	"\bresponse2\x91\n" +
+	"\n" +
+	"\x10NoNoodleWorkflow\x12|\n" +
+	"\x13DeployProcessConfig\x121.no_noodle_workflow.v1.DeployProcessConfigRequest\x1a2.no_noodle_workflow.v1.DeployProcessConfigResponse\x12s\n" +
+	"\x10GetProcessConfig\x12..no_noodle_workflow.v1.GetProcessConfigRequest\x1a/.no_noodle_workflow.v1.GetProcessConfigResponse\x12m\n" +
+	"\x0eCreateWorkflow\x12,.no_noodle_workflow.v1.CreateWorkflowRequest\x1a-.no_noodle_workflow.v1.CreateWorkflowResponse\x12d\n" +
+	"\vGetWorkflow\x12).no_noodle_workflow.v1.GetWorkflowRequest\x1a*.no_noodle_workflow.v1.GetWorkflowResponse\x12p\n" +
+	"\x0fSearchWorkflows\x12-.no_noodle_workflow.v1.SearchWorkflowsRequest\x1a..no_noodle_workflow.v1.SearchWorkflowsResponse\x12g\n" +
+	"\fCompleteTask\x12*.no_noodle_workflow.v1.CompleteTaskRequest\x1a+.no_noodle_workflow.v1.CompleteTaskResponse\x12[\n" +
+	"\bFailTask\x12&.no_noodle_workflow.v1.FailTaskRequest\x1a'.no_noodle_workflow.v1.FailTaskResponse\x12j\n" +
+	"\rHeartbeatTask\x12+.no_noodle_workflow.v1.HeartbeatTaskRequest\x1a,.no_noodle_workflow.v1.HeartbeatTaskResponse\x12d\n" +
+	"\vCompleteJob\x12).no_noodle_workflow.v1.CompleteJobRequest\x1a*.no_noodle_workflow.v1.CompleteJobResponse\x12X\n" +
+	"\aFailJob\x12%.no_noodle_workflow.v1.FailJobRequest\x1a&.no_noodle_workflow.v1.FailJobResponse\x12j\n" +
+	"\rExtendJobLock\x12+.no_noodle_workflow.v1.ExtendJobLockRequest\x1a,.no_noodle_workflow.v1.ExtendJobLockResponse\x12e\n" +
+	"\n" +
+	"StreamJobs\x12(.no_noodle_workflow.v1.StreamJobsRequest\x1a).no_noodle_workflow.v1.StreamJobsResponse(\x010\x01Be\n" +
+	"*com.github.keeraponsom.nonoodleworkflow.v1P\x01Z5github.com/keerapon-som/no_noodle_workflow/nonoodlepbb\x06proto3"
+
+var (
+	file_no_noodle_workflow_proto_rawDescOnce sync.Once
+	file_no_noodle_workflow_proto_rawDescData []byte
+)
+
+func file_no_noodle_workflow_proto_rawDescGZIP() []byte {
+	file_no_noodle_workflow_proto_rawDescOnce.Do(func() {
+		file_no_noodle_workflow_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_no_noodle_workflow_proto_rawDesc), len(file_no_noodle_workflow_proto_rawDesc)))
+	})
+	return file_no_noodle_workflow_proto_rawDescData
+}
+
+var file_no_noodle_workflow_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_no_noodle_workflow_proto_goTypes = []any{
+	(*TaskList)(nil),                    // 0: no_noodle_workflow.v1.TaskList
+	(*ProcessConfig)(nil),               // 1: no_noodle_workflow.v1.ProcessConfig
+	(*TaskStatus)(nil),                  // 2: no_noodle_workflow.v1.TaskStatus
+	(*Workflow)(nil),                    // 3: no_noodle_workflow.v1.Workflow
+	(*Topic)(nil),                       // 4: no_noodle_workflow.v1.Topic
+	(*LockedJob)(nil),                   // 5: no_noodle_workflow.v1.LockedJob
+	(*DeployProcessConfigRequest)(nil),  // 6: no_noodle_workflow.v1.DeployProcessConfigRequest
+	(*DeployProcessConfigResponse)(nil), // 7: no_noodle_workflow.v1.DeployProcessConfigResponse
+	(*GetProcessConfigRequest)(nil),     // 8: no_noodle_workflow.v1.GetProcessConfigRequest
+	(*GetProcessConfigResponse)(nil),    // 9: no_noodle_workflow.v1.GetProcessConfigResponse
+	(*CreateWorkflowRequest)(nil),       // 10: no_noodle_workflow.v1.CreateWorkflowRequest
+	(*CreateWorkflowResponse)(nil),      // 11: no_noodle_workflow.v1.CreateWorkflowResponse
+	(*GetWorkflowRequest)(nil),          // 12: no_noodle_workflow.v1.GetWorkflowRequest
+	(*GetWorkflowResponse)(nil),         // 13: no_noodle_workflow.v1.GetWorkflowResponse
+	(*SearchWorkflowsRequest)(nil),      // 14: no_noodle_workflow.v1.SearchWorkflowsRequest
+	(*SearchWorkflowsResponse)(nil),     // 15: no_noodle_workflow.v1.SearchWorkflowsResponse
+	(*CompleteTaskRequest)(nil),         // 16: no_noodle_workflow.v1.CompleteTaskRequest
+	(*CompleteTaskResponse)(nil),        // 17: no_noodle_workflow.v1.CompleteTaskResponse
+	(*FailTaskRequest)(nil),             // 18: no_noodle_workflow.v1.FailTaskRequest
+	(*FailTaskResponse)(nil),            // 19: no_noodle_workflow.v1.FailTaskResponse
+	(*HeartbeatTaskRequest)(nil),        // 20: no_noodle_workflow.v1.HeartbeatTaskRequest
+	(*HeartbeatTaskResponse)(nil),       // 21: no_noodle_workflow.v1.HeartbeatTaskResponse
+	(*CompleteJobRequest)(nil),          // 22: no_noodle_workflow.v1.CompleteJobRequest
+	(*CompleteJobResponse)(nil),         // 23: no_noodle_workflow.v1.CompleteJobResponse
+	(*FailJobRequest)(nil),              // 24: no_noodle_workflow.v1.FailJobRequest
+	(*FailJobResponse)(nil),             // 25: no_noodle_workflow.v1.FailJobResponse
+	(*ExtendJobLockRequest)(nil),        // 26: no_noodle_workflow.v1.ExtendJobLockRequest
+	(*ExtendJobLockResponse)(nil),       // 27: no_noodle_workflow.v1.ExtendJobLockResponse
+	(*OpenJobStream)(nil),               // 28: no_noodle_workflow.v1.OpenJobStream
+	(*Topics)(nil),                      // 29: no_noodle_workflow.v1.Topics
+	(*StreamJobsRequest)(nil),           // 30: no_noodle_workflow.v1.StreamJobsRequest
+	(*StreamResult)(nil),                // 31: no_noodle_workflow.v1.StreamResult
+	(*StreamJobsResponse)(nil),          // 32: no_noodle_workflow.v1.StreamJobsResponse
+	nil,                                 // 33: no_noodle_workflow.v1.ProcessConfig.MapStageTaskEntry
+	nil,                                 // 34: no_noodle_workflow.v1.ProcessConfig.MapStageReadyEntry
+	nil,                                 // 35: no_noodle_workflow.v1.ProcessConfig.MapTaskLeaseSecondsEntry
+	nil,                                 // 36: no_noodle_workflow.v1.Workflow.TaskStatusEntry
+	nil,                                 // 37: no_noodle_workflow.v1.Workflow.PublishedStageEntry
+	(*timestamppb.Timestamp)(nil),       // 38: google.protobuf.Timestamp
+}
+var file_no_noodle_workflow_proto_depIdxs = []int32{
+	33, // 0: no_noodle_workflow.v1.ProcessConfig.map_stage_task:type_name -> no_noodle_workflow.v1.ProcessConfig.MapStageTaskEntry
+	34, // 1: no_noodle_workflow.v1.ProcessConfig.map_stage_ready:type_name -> no_noodle_workflow.v1.ProcessConfig.MapStageReadyEntry
+	35, // 2: no_noodle_workflow.v1.ProcessConfig.map_task_lease_seconds:type_name -> no_noodle_workflow.v1.ProcessConfig.MapTaskLeaseSecondsEntry
+	38, // 3: no_noodle_workflow.v1.ProcessConfig.create_date:type_name -> google.protobuf.Timestamp
+	38, // 4: no_noodle_workflow.v1.TaskStatus.lease_expire_date:type_name -> google.protobuf.Timestamp
+	38, // 5: no_noodle_workflow.v1.TaskStatus.start_date:type_name -> google.protobuf.Timestamp
+	38, // 6: no_noodle_workflow.v1.TaskStatus.end_date:type_name -> google.protobuf.Timestamp
+	38, // 7: no_noodle_workflow.v1.TaskStatus.update_date:type_name -> google.protobuf.Timestamp
+	36, // 8: no_noodle_workflow.v1.Workflow.task_status:type_name -> no_noodle_workflow.v1.Workflow.TaskStatusEntry
+	37, // 9: no_noodle_workflow.v1.Workflow.published_stage:type_name -> no_noodle_workflow.v1.Workflow.PublishedStageEntry
+	38, // 10: no_noodle_workflow.v1.Workflow.create_date:type_name -> google.protobuf.Timestamp
+	38, // 11: no_noodle_workflow.v1.Workflow.update_date:type_name -> google.protobuf.Timestamp
+	38, // 12: no_noodle_workflow.v1.LockedJob.lease_expire_date:type_name -> google.protobuf.Timestamp
+	1,  // 13: no_noodle_workflow.v1.DeployProcessConfigRequest.process_config:type_name -> no_noodle_workflow.v1.ProcessConfig
+	1,  // 14: no_noodle_workflow.v1.GetProcessConfigResponse.process_config:type_name -> no_noodle_workflow.v1.ProcessConfig
+	3,  // 15: no_noodle_workflow.v1.GetWorkflowResponse.workflow:type_name -> no_noodle_workflow.v1.Workflow
+	38, // 16: no_noodle_workflow.v1.SearchWorkflowsRequest.created_from:type_name -> google.protobuf.Timestamp
+	38, // 17: no_noodle_workflow.v1.SearchWorkflowsRequest.created_to:type_name -> google.protobuf.Timestamp
+	3,  // 18: no_noodle_workflow.v1.SearchWorkflowsResponse.workflows:type_name -> no_noodle_workflow.v1.Workflow
+	38, // 19: no_noodle_workflow.v1.HeartbeatTaskResponse.lease_expire_date:type_name -> google.protobuf.Timestamp
+	38, // 20: no_noodle_workflow.v1.ExtendJobLockResponse.lease_expire_date:type_name -> google.protobuf.Timestamp
+	4,  // 21: no_noodle_workflow.v1.OpenJobStream.topics:type_name -> no_noodle_workflow.v1.Topic
+	4,  // 22: no_noodle_workflow.v1.Topics.topics:type_name -> no_noodle_workflow.v1.Topic
+	28, // 23: no_noodle_workflow.v1.StreamJobsRequest.open:type_name -> no_noodle_workflow.v1.OpenJobStream
+	29, // 24: no_noodle_workflow.v1.StreamJobsRequest.subscribe:type_name -> no_noodle_workflow.v1.Topics
+	29, // 25: no_noodle_workflow.v1.StreamJobsRequest.unsubscribe:type_name -> no_noodle_workflow.v1.Topics
+	22, // 26: no_noodle_workflow.v1.StreamJobsRequest.complete:type_name -> no_noodle_workflow.v1.CompleteJobRequest
+	24, // 27: no_noodle_workflow.v1.StreamJobsRequest.fail:type_name -> no_noodle_workflow.v1.FailJobRequest
+	26, // 28: no_noodle_workflow.v1.StreamJobsRequest.extend_lock:type_name -> no_noodle_workflow.v1.ExtendJobLockRequest
+	4,  // 29: no_noodle_workflow.v1.StreamResult.topics:type_name -> no_noodle_workflow.v1.Topic
+	38, // 30: no_noodle_workflow.v1.StreamResult.lease_expire_date:type_name -> google.protobuf.Timestamp
+	5,  // 31: no_noodle_workflow.v1.StreamJobsResponse.job:type_name -> no_noodle_workflow.v1.LockedJob
+	31, // 32: no_noodle_workflow.v1.StreamJobsResponse.result:type_name -> no_noodle_workflow.v1.StreamResult
+	0,  // 33: no_noodle_workflow.v1.ProcessConfig.MapStageTaskEntry.value:type_name -> no_noodle_workflow.v1.TaskList
+	0,  // 34: no_noodle_workflow.v1.ProcessConfig.MapStageReadyEntry.value:type_name -> no_noodle_workflow.v1.TaskList
+	2,  // 35: no_noodle_workflow.v1.Workflow.TaskStatusEntry.value:type_name -> no_noodle_workflow.v1.TaskStatus
+	6,  // 36: no_noodle_workflow.v1.NoNoodleWorkflow.DeployProcessConfig:input_type -> no_noodle_workflow.v1.DeployProcessConfigRequest
+	8,  // 37: no_noodle_workflow.v1.NoNoodleWorkflow.GetProcessConfig:input_type -> no_noodle_workflow.v1.GetProcessConfigRequest
+	10, // 38: no_noodle_workflow.v1.NoNoodleWorkflow.CreateWorkflow:input_type -> no_noodle_workflow.v1.CreateWorkflowRequest
+	12, // 39: no_noodle_workflow.v1.NoNoodleWorkflow.GetWorkflow:input_type -> no_noodle_workflow.v1.GetWorkflowRequest
+	14, // 40: no_noodle_workflow.v1.NoNoodleWorkflow.SearchWorkflows:input_type -> no_noodle_workflow.v1.SearchWorkflowsRequest
+	16, // 41: no_noodle_workflow.v1.NoNoodleWorkflow.CompleteTask:input_type -> no_noodle_workflow.v1.CompleteTaskRequest
+	18, // 42: no_noodle_workflow.v1.NoNoodleWorkflow.FailTask:input_type -> no_noodle_workflow.v1.FailTaskRequest
+	20, // 43: no_noodle_workflow.v1.NoNoodleWorkflow.HeartbeatTask:input_type -> no_noodle_workflow.v1.HeartbeatTaskRequest
+	22, // 44: no_noodle_workflow.v1.NoNoodleWorkflow.CompleteJob:input_type -> no_noodle_workflow.v1.CompleteJobRequest
+	24, // 45: no_noodle_workflow.v1.NoNoodleWorkflow.FailJob:input_type -> no_noodle_workflow.v1.FailJobRequest
+	26, // 46: no_noodle_workflow.v1.NoNoodleWorkflow.ExtendJobLock:input_type -> no_noodle_workflow.v1.ExtendJobLockRequest
+	30, // 47: no_noodle_workflow.v1.NoNoodleWorkflow.StreamJobs:input_type -> no_noodle_workflow.v1.StreamJobsRequest
+	7,  // 48: no_noodle_workflow.v1.NoNoodleWorkflow.DeployProcessConfig:output_type -> no_noodle_workflow.v1.DeployProcessConfigResponse
+	9,  // 49: no_noodle_workflow.v1.NoNoodleWorkflow.GetProcessConfig:output_type -> no_noodle_workflow.v1.GetProcessConfigResponse
+	11, // 50: no_noodle_workflow.v1.NoNoodleWorkflow.CreateWorkflow:output_type -> no_noodle_workflow.v1.CreateWorkflowResponse
+	13, // 51: no_noodle_workflow.v1.NoNoodleWorkflow.GetWorkflow:output_type -> no_noodle_workflow.v1.GetWorkflowResponse
+	15, // 52: no_noodle_workflow.v1.NoNoodleWorkflow.SearchWorkflows:output_type -> no_noodle_workflow.v1.SearchWorkflowsResponse
+	17, // 53: no_noodle_workflow.v1.NoNoodleWorkflow.CompleteTask:output_type -> no_noodle_workflow.v1.CompleteTaskResponse
+	19, // 54: no_noodle_workflow.v1.NoNoodleWorkflow.FailTask:output_type -> no_noodle_workflow.v1.FailTaskResponse
+	21, // 55: no_noodle_workflow.v1.NoNoodleWorkflow.HeartbeatTask:output_type -> no_noodle_workflow.v1.HeartbeatTaskResponse
+	23, // 56: no_noodle_workflow.v1.NoNoodleWorkflow.CompleteJob:output_type -> no_noodle_workflow.v1.CompleteJobResponse
+	25, // 57: no_noodle_workflow.v1.NoNoodleWorkflow.FailJob:output_type -> no_noodle_workflow.v1.FailJobResponse
+	27, // 58: no_noodle_workflow.v1.NoNoodleWorkflow.ExtendJobLock:output_type -> no_noodle_workflow.v1.ExtendJobLockResponse
+	32, // 59: no_noodle_workflow.v1.NoNoodleWorkflow.StreamJobs:output_type -> no_noodle_workflow.v1.StreamJobsResponse
+	48, // [48:60] is the sub-list for method output_type
+	36, // [36:48] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
+}
+
+func init() { file_no_noodle_workflow_proto_init() }
+func file_no_noodle_workflow_proto_init() {
+	if File_no_noodle_workflow_proto != nil {
+		return
+	}
+	file_no_noodle_workflow_proto_msgTypes[30].OneofWrappers = []any{
+		(*StreamJobsRequest_Open)(nil),
+		(*StreamJobsRequest_Subscribe)(nil),
+		(*StreamJobsRequest_Unsubscribe)(nil),
+		(*StreamJobsRequest_Complete)(nil),
+		(*StreamJobsRequest_Fail)(nil),
+		(*StreamJobsRequest_ExtendLock)(nil),
+	}
+	file_no_noodle_workflow_proto_msgTypes[32].OneofWrappers = []any{
+		(*StreamJobsResponse_Job)(nil),
+		(*StreamJobsResponse_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_no_noodle_workflow_proto_rawDesc), len(file_no_noodle_workflow_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   38,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_no_noodle_workflow_proto_goTypes,
+		DependencyIndexes: file_no_noodle_workflow_proto_depIdxs,
+		MessageInfos:      file_no_noodle_workflow_proto_msgTypes,
+	}.Build()
+	File_no_noodle_workflow_proto = out.File
+	file_no_noodle_workflow_proto_goTypes = nil
+	file_no_noodle_workflow_proto_depIdxs = nil
+}
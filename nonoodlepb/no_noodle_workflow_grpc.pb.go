@@ -0,0 +1,564 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: no_noodle_workflow.proto
+
+package nonoodlepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NoNoodleWorkflow_DeployProcessConfig_FullMethodName = "/no_noodle_workflow.v1.NoNoodleWorkflow/DeployProcessConfig"
+	NoNoodleWorkflow_GetProcessConfig_FullMethodName    = "/no_noodle_workflow.v1.NoNoodleWorkflow/GetProcessConfig"
+	NoNoodleWorkflow_CreateWorkflow_FullMethodName      = "/no_noodle_workflow.v1.NoNoodleWorkflow/CreateWorkflow"
+	NoNoodleWorkflow_GetWorkflow_FullMethodName         = "/no_noodle_workflow.v1.NoNoodleWorkflow/GetWorkflow"
+	NoNoodleWorkflow_SearchWorkflows_FullMethodName     = "/no_noodle_workflow.v1.NoNoodleWorkflow/SearchWorkflows"
+	NoNoodleWorkflow_CompleteTask_FullMethodName        = "/no_noodle_workflow.v1.NoNoodleWorkflow/CompleteTask"
+	NoNoodleWorkflow_FailTask_FullMethodName            = "/no_noodle_workflow.v1.NoNoodleWorkflow/FailTask"
+	NoNoodleWorkflow_HeartbeatTask_FullMethodName       = "/no_noodle_workflow.v1.NoNoodleWorkflow/HeartbeatTask"
+	NoNoodleWorkflow_CompleteJob_FullMethodName         = "/no_noodle_workflow.v1.NoNoodleWorkflow/CompleteJob"
+	NoNoodleWorkflow_FailJob_FullMethodName             = "/no_noodle_workflow.v1.NoNoodleWorkflow/FailJob"
+	NoNoodleWorkflow_ExtendJobLock_FullMethodName       = "/no_noodle_workflow.v1.NoNoodleWorkflow/ExtendJobLock"
+	NoNoodleWorkflow_StreamJobs_FullMethodName          = "/no_noodle_workflow.v1.NoNoodleWorkflow/StreamJobs"
+)
+
+// NoNoodleWorkflowClient is the client API for NoNoodleWorkflow service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NoNoodleWorkflow is the gRPC counterpart of the core HTTP API. Errors are
+// reported with the usual status codes: NOT_FOUND for unknown workflows,
+// processes, tasks and jobs, INVALID_ARGUMENT for bad requests and
+// FAILED_PRECONDITION when a task or job is no longer active.
+type NoNoodleWorkflowClient interface {
+	DeployProcessConfig(ctx context.Context, in *DeployProcessConfigRequest, opts ...grpc.CallOption) (*DeployProcessConfigResponse, error)
+	GetProcessConfig(ctx context.Context, in *GetProcessConfigRequest, opts ...grpc.CallOption) (*GetProcessConfigResponse, error)
+	CreateWorkflow(ctx context.Context, in *CreateWorkflowRequest, opts ...grpc.CallOption) (*CreateWorkflowResponse, error)
+	GetWorkflow(ctx context.Context, in *GetWorkflowRequest, opts ...grpc.CallOption) (*GetWorkflowResponse, error)
+	SearchWorkflows(ctx context.Context, in *SearchWorkflowsRequest, opts ...grpc.CallOption) (*SearchWorkflowsResponse, error)
+	// CompleteTask, FailTask and HeartbeatTask act on the task a pushed job
+	// belongs to.
+	CompleteTask(ctx context.Context, in *CompleteTaskRequest, opts ...grpc.CallOption) (*CompleteTaskResponse, error)
+	FailTask(ctx context.Context, in *FailTaskRequest, opts ...grpc.CallOption) (*FailTaskResponse, error)
+	HeartbeatTask(ctx context.Context, in *HeartbeatTaskRequest, opts ...grpc.CallOption) (*HeartbeatTaskResponse, error)
+	// CompleteJob, FailJob and ExtendJobLock act on a job delivered by
+	// StreamJobs or fetched over HTTP.
+	CompleteJob(ctx context.Context, in *CompleteJobRequest, opts ...grpc.CallOption) (*CompleteJobResponse, error)
+	FailJob(ctx context.Context, in *FailJobRequest, opts ...grpc.CallOption) (*FailJobResponse, error)
+	ExtendJobLock(ctx context.Context, in *ExtendJobLockRequest, opts ...grpc.CallOption) (*ExtendJobLockResponse, error)
+	// StreamJobs delivers jobs over one long lived stream. The first request
+	// must be open, every request is answered with a result carrying its
+	// request_id. At most max_jobs jobs are locked to the worker at a time, a
+	// job frees its slot once it is completed or failed on the stream or its
+	// lock runs out. Jobs still locked when the stream ends are delivered again
+	// right away.
+	StreamJobs(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamJobsRequest, StreamJobsResponse], error)
+}
+
+type noNoodleWorkflowClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNoNoodleWorkflowClient(cc grpc.ClientConnInterface) NoNoodleWorkflowClient {
+	return &noNoodleWorkflowClient{cc}
+}
+
+func (c *noNoodleWorkflowClient) DeployProcessConfig(ctx context.Context, in *DeployProcessConfigRequest, opts ...grpc.CallOption) (*DeployProcessConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeployProcessConfigResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_DeployProcessConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) GetProcessConfig(ctx context.Context, in *GetProcessConfigRequest, opts ...grpc.CallOption) (*GetProcessConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProcessConfigResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_GetProcessConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) CreateWorkflow(ctx context.Context, in *CreateWorkflowRequest, opts ...grpc.CallOption) (*CreateWorkflowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateWorkflowResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_CreateWorkflow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) GetWorkflow(ctx context.Context, in *GetWorkflowRequest, opts ...grpc.CallOption) (*GetWorkflowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWorkflowResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_GetWorkflow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) SearchWorkflows(ctx context.Context, in *SearchWorkflowsRequest, opts ...grpc.CallOption) (*SearchWorkflowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchWorkflowsResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_SearchWorkflows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) CompleteTask(ctx context.Context, in *CompleteTaskRequest, opts ...grpc.CallOption) (*CompleteTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteTaskResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_CompleteTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) FailTask(ctx context.Context, in *FailTaskRequest, opts ...grpc.CallOption) (*FailTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FailTaskResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_FailTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) HeartbeatTask(ctx context.Context, in *HeartbeatTaskRequest, opts ...grpc.CallOption) (*HeartbeatTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatTaskResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_HeartbeatTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) CompleteJob(ctx context.Context, in *CompleteJobRequest, opts ...grpc.CallOption) (*CompleteJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteJobResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_CompleteJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) FailJob(ctx context.Context, in *FailJobRequest, opts ...grpc.CallOption) (*FailJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FailJobResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_FailJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) ExtendJobLock(ctx context.Context, in *ExtendJobLockRequest, opts ...grpc.CallOption) (*ExtendJobLockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtendJobLockResponse)
+	err := c.cc.Invoke(ctx, NoNoodleWorkflow_ExtendJobLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noNoodleWorkflowClient) StreamJobs(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamJobsRequest, StreamJobsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoNoodleWorkflow_ServiceDesc.Streams[0], NoNoodleWorkflow_StreamJobs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamJobsRequest, StreamJobsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoNoodleWorkflow_StreamJobsClient = grpc.BidiStreamingClient[StreamJobsRequest, StreamJobsResponse]
+
+// NoNoodleWorkflowServer is the server API for NoNoodleWorkflow service.
+// All implementations must embed UnimplementedNoNoodleWorkflowServer
+// for forward compatibility.
+//
+// NoNoodleWorkflow is the gRPC counterpart of the core HTTP API. Errors are
+// reported with the usual status codes: NOT_FOUND for unknown workflows,
+// processes, tasks and jobs, INVALID_ARGUMENT for bad requests and
+// FAILED_PRECONDITION when a task or job is no longer active.
+type NoNoodleWorkflowServer interface {
+	DeployProcessConfig(context.Context, *DeployProcessConfigRequest) (*DeployProcessConfigResponse, error)
+	GetProcessConfig(context.Context, *GetProcessConfigRequest) (*GetProcessConfigResponse, error)
+	CreateWorkflow(context.Context, *CreateWorkflowRequest) (*CreateWorkflowResponse, error)
+	GetWorkflow(context.Context, *GetWorkflowRequest) (*GetWorkflowResponse, error)
+	SearchWorkflows(context.Context, *SearchWorkflowsRequest) (*SearchWorkflowsResponse, error)
+	// CompleteTask, FailTask and HeartbeatTask act on the task a pushed job
+	// belongs to.
+	CompleteTask(context.Context, *CompleteTaskRequest) (*CompleteTaskResponse, error)
+	FailTask(context.Context, *FailTaskRequest) (*FailTaskResponse, error)
+	HeartbeatTask(context.Context, *HeartbeatTaskRequest) (*HeartbeatTaskResponse, error)
+	// CompleteJob, FailJob and ExtendJobLock act on a job delivered by
+	// StreamJobs or fetched over HTTP.
+	CompleteJob(context.Context, *CompleteJobRequest) (*CompleteJobResponse, error)
+	FailJob(context.Context, *FailJobRequest) (*FailJobResponse, error)
+	ExtendJobLock(context.Context, *ExtendJobLockRequest) (*ExtendJobLockResponse, error)
+	// StreamJobs delivers jobs over one long lived stream. The first request
+	// must be open, every request is answered with a result carrying its
+	// request_id. At most max_jobs jobs are locked to the worker at a time, a
+	// job frees its slot once it is completed or failed on the stream or its
+	// lock runs out. Jobs still locked when the stream ends are delivered again
+	// right away.
+	StreamJobs(grpc.BidiStreamingServer[StreamJobsRequest, StreamJobsResponse]) error
+	mustEmbedUnimplementedNoNoodleWorkflowServer()
+}
+
+// UnimplementedNoNoodleWorkflowServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNoNoodleWorkflowServer struct{}
+
+func (UnimplementedNoNoodleWorkflowServer) DeployProcessConfig(context.Context, *DeployProcessConfigRequest) (*DeployProcessConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeployProcessConfig not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) GetProcessConfig(context.Context, *GetProcessConfigRequest) (*GetProcessConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProcessConfig not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) CreateWorkflow(context.Context, *CreateWorkflowRequest) (*CreateWorkflowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWorkflow not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) GetWorkflow(context.Context, *GetWorkflowRequest) (*GetWorkflowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkflow not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) SearchWorkflows(context.Context, *SearchWorkflowsRequest) (*SearchWorkflowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchWorkflows not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) CompleteTask(context.Context, *CompleteTaskRequest) (*CompleteTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteTask not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) FailTask(context.Context, *FailTaskRequest) (*FailTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FailTask not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) HeartbeatTask(context.Context, *HeartbeatTaskRequest) (*HeartbeatTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HeartbeatTask not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) CompleteJob(context.Context, *CompleteJobRequest) (*CompleteJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteJob not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) FailJob(context.Context, *FailJobRequest) (*FailJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FailJob not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) ExtendJobLock(context.Context, *ExtendJobLockRequest) (*ExtendJobLockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendJobLock not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) StreamJobs(grpc.BidiStreamingServer[StreamJobsRequest, StreamJobsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamJobs not implemented")
+}
+func (UnimplementedNoNoodleWorkflowServer) mustEmbedUnimplementedNoNoodleWorkflowServer() {}
+func (UnimplementedNoNoodleWorkflowServer) testEmbeddedByValue()                          {}
+
+// UnsafeNoNoodleWorkflowServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NoNoodleWorkflowServer will
+// result in compilation errors.
+type UnsafeNoNoodleWorkflowServer interface {
+	mustEmbedUnimplementedNoNoodleWorkflowServer()
+}
+
+func RegisterNoNoodleWorkflowServer(s grpc.ServiceRegistrar, srv NoNoodleWorkflowServer) {
+	// If the following call pancis, it indicates UnimplementedNoNoodleWorkflowServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NoNoodleWorkflow_ServiceDesc, srv)
+}
+
+func _NoNoodleWorkflow_DeployProcessConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeployProcessConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).DeployProcessConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_DeployProcessConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).DeployProcessConfig(ctx, req.(*DeployProcessConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_GetProcessConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProcessConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).GetProcessConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_GetProcessConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).GetProcessConfig(ctx, req.(*GetProcessConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_CreateWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).CreateWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_CreateWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).CreateWorkflow(ctx, req.(*CreateWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_GetWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).GetWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_GetWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).GetWorkflow(ctx, req.(*GetWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_SearchWorkflows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchWorkflowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).SearchWorkflows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_SearchWorkflows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).SearchWorkflows(ctx, req.(*SearchWorkflowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_CompleteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).CompleteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_CompleteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).CompleteTask(ctx, req.(*CompleteTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_FailTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FailTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).FailTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_FailTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).FailTask(ctx, req.(*FailTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_HeartbeatTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).HeartbeatTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_HeartbeatTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).HeartbeatTask(ctx, req.(*HeartbeatTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_CompleteJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).CompleteJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_CompleteJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).CompleteJob(ctx, req.(*CompleteJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_FailJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FailJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).FailJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_FailJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).FailJob(ctx, req.(*FailJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_ExtendJobLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendJobLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoNoodleWorkflowServer).ExtendJobLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoNoodleWorkflow_ExtendJobLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoNoodleWorkflowServer).ExtendJobLock(ctx, req.(*ExtendJobLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoNoodleWorkflow_StreamJobs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NoNoodleWorkflowServer).StreamJobs(&grpc.GenericServerStream[StreamJobsRequest, StreamJobsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoNoodleWorkflow_StreamJobsServer = grpc.BidiStreamingServer[StreamJobsRequest, StreamJobsResponse]
+
+// NoNoodleWorkflow_ServiceDesc is the grpc.ServiceDesc for NoNoodleWorkflow service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NoNoodleWorkflow_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "no_noodle_workflow.v1.NoNoodleWorkflow",
+	HandlerType: (*NoNoodleWorkflowServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DeployProcessConfig",
+			Handler:    _NoNoodleWorkflow_DeployProcessConfig_Handler,
+		},
+		{
+			MethodName: "GetProcessConfig",
+			Handler:    _NoNoodleWorkflow_GetProcessConfig_Handler,
+		},
+		{
+			MethodName: "CreateWorkflow",
+			Handler:    _NoNoodleWorkflow_CreateWorkflow_Handler,
+		},
+		{
+			MethodName: "GetWorkflow",
+			Handler:    _NoNoodleWorkflow_GetWorkflow_Handler,
+		},
+		{
+			MethodName: "SearchWorkflows",
+			Handler:    _NoNoodleWorkflow_SearchWorkflows_Handler,
+		},
+		{
+			MethodName: "CompleteTask",
+			Handler:    _NoNoodleWorkflow_CompleteTask_Handler,
+		},
+		{
+			MethodName: "FailTask",
+			Handler:    _NoNoodleWorkflow_FailTask_Handler,
+		},
+		{
+			MethodName: "HeartbeatTask",
+			Handler:    _NoNoodleWorkflow_HeartbeatTask_Handler,
+		},
+		{
+			MethodName: "CompleteJob",
+			Handler:    _NoNoodleWorkflow_CompleteJob_Handler,
+		},
+		{
+			MethodName: "FailJob",
+			Handler:    _NoNoodleWorkflow_FailJob_Handler,
+		},
+		{
+			MethodName: "ExtendJobLock",
+			Handler:    _NoNoodleWorkflow_ExtendJobLock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamJobs",
+			Handler:       _NoNoodleWorkflow_StreamJobs_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "no_noodle_workflow.proto",
+}